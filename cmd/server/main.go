@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -18,6 +19,8 @@ import (
 	"learn/internal/config"
 	"learn/internal/repository"
 	"learn/internal/service"
+	"learn/internal/storage"
+	"learn/pkg/secretbox"
 )
 
 // @title Backend Misc API
@@ -49,6 +52,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Agents don't use the database: they get their checks from the server.
+	if cfg.RunMode == config.RunModeAgent {
+		runAgent(logger, cfg)
+		return
+	}
+
 	db, err := config.OpenDB(cfg.DBPath)
 	if err != nil {
 		logger.Error("failed to connect to database", "error", err)
@@ -61,72 +70,193 @@ func main() {
 		os.Exit(1)
 	}
 
+	secrets, err := secretbox.New(cfg.EncryptionKey)
+	if err != nil {
+		logger.Error("failed to initialise encryption", "error", err)
+		os.Exit(1)
+	}
+
 	userRepo := repository.NewSQLiteUserRepository(db)
-	monitorRepo := repository.NewSQLiteMonitorRepository(db)
+	monitorRepo := repository.NewSQLiteMonitorRepository(db, secrets)
 	snippetRepo := repository.NewSQLiteSnippetRepository(db)
+	postRepo := repository.NewSQLitePostRepository(db)
+	exportRepo := repository.NewSQLiteExportRepository(db)
+	auditRepo := repository.NewSQLiteAuditRepository(db)
+	organizationRepo := repository.NewSQLiteOrganizationRepository(db)
+	incidentRepo := repository.NewSQLiteIncidentRepository(db)
+	notificationRepo := repository.NewSQLiteNotificationRepository(db, secrets)
+	onCallRepo := repository.NewSQLiteOnCallRepository(db)
+	maintenanceRepo := repository.NewSQLiteMaintenanceRepository(db)
+	statusPageRepo := repository.NewSQLiteStatusPageRepository(db)
+	rollupRepo := repository.NewSQLiteRollupRepository(db)
+	leaseRepo := repository.NewSQLiteLeaseRepository(db)
+	probeRepo := repository.NewSQLiteProbeRepository(db)
+	monitorEventRepo := repository.NewSQLiteMonitorEventRepository(db)
+
+	blobStore, err := storage.NewLocalBlobStore(cfg.UploadDir)
+	if err != nil {
+		logger.Error("failed to open upload directory", "error", err)
+		os.Exit(1)
+	}
 
-	authService := service.NewAuthService(userRepo, cfg.JWTSecret, cfg.JWTExpiry)
-	userService := service.NewUserService(userRepo)
-	monitorService := service.NewMonitorService(monitorRepo)
-	snippetService := service.NewSnippetService(snippetRepo)
+	var mailer service.Mailer = service.NewLogMailer()
+	if cfg.SMTPHost != "" {
+		mailer = service.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+	}
+
+	leases := service.NewLeases(leaseRepo, cfg.WorkerID)
+	auditService := service.NewAuditService(auditRepo)
+	authService := service.NewAuthService(userRepo, auditService, cfg.JWTSecret, cfg.JWTExpiry)
+	userService := service.NewUserService(userRepo, auditService)
+	monitorSchedule := service.NewMonitorSchedule()
+	monitorEvents := service.NewMonitorEvents(monitorEventRepo)
+	egressPolicy := service.NewEgressPolicy(cfg.EgressAllowlist)
+	monitorService := service.NewMonitorService(monitorRepo, incidentRepo, organizationRepo, maintenanceRepo, rollupRepo, probeRepo, egressPolicy, monitorSchedule, monitorEvents, auditService)
+	incidentService := service.NewIncidentService(incidentRepo, monitorRepo, onCallRepo, notificationRepo, monitorEvents, auditService)
+	notificationService := service.NewNotificationService(notificationRepo, monitorService, mailer, leases, auditService, egressPolicy, cfg.NotificationRetryDelay)
+	maintenanceService := service.NewMaintenanceService(maintenanceRepo, monitorService, auditService)
+	statusPageService := service.NewStatusPageService(statusPageRepo, monitorService, incidentRepo, maintenanceService, auditService)
+	onCallService := service.NewOnCallService(onCallRepo, incidentRepo, userRepo, monitorService, notificationService, maintenanceService, leases, auditService)
+	checkRecorder := service.NewCheckRecorder(monitorRepo, incidentService, notificationService, maintenanceService, monitorEvents)
+	monitorCheckService := service.NewMonitorCheckService(monitorRepo, monitorService, checkRecorder)
+	probeService := service.NewProbeService(probeRepo, monitorRepo, checkRecorder, maintenanceService, auditService)
+	snippetService := service.NewSnippetService(snippetRepo, auditService)
 	postService := service.NewPostService()
+	organizationService := service.NewOrganizationService(organizationRepo, mailer, auditService, cfg.InviteTTL, cfg.PublicURL)
+	avatarService := service.NewAvatarService(blobStore, userRepo, auditService)
+	heartbeatService := service.NewHeartbeatService(monitorRepo, checkRecorder)
+	exportService := service.NewExportService(exportRepo, userRepo, monitorRepo, postRepo, snippetRepo, cfg.ExportLinkTTL)
+	if _, err := exportService.FailStale(context.Background()); err != nil {
+		logger.Error("failed to expire stale exports", "error", err)
+	}
 
-	monitorWorker := service.NewMonitorWorker(monitorRepo, snippetService)
-	monitorWorker.Start()
+	// Any number of processes may share the database: checks and background
+	// jobs are divided between them through leases, so "api" processes can be
+	// scaled separately from "worker" ones.
+	runsWorkers := cfg.RunMode != config.RunModeAPI
+	servesHTTP := cfg.RunMode != config.RunModeWorker
+
+	monitorWorker := service.NewMonitorWorker(monitorRepo, monitorSchedule, leases, checkRecorder, maintenanceService, snippetService, exportService, egressPolicy)
+	logCompactor := service.NewLogCompactor(monitorRepo, rollupRepo, leases, cfg.CompactionInterval, cfg.LogRetention, cfg.HourlyRollupRetention)
+	if runsWorkers {
+		logger.Info("worker started", "worker_id", cfg.WorkerID)
+		monitorWorker.Start()
+		notificationService.Start()
+		onCallService.Start()
+		logCompactor.Start()
+	}
 
 	authHandler := handlers.NewAuthHandler(authService)
 	userHandler := handlers.NewUserHandler(userService)
-	monitorHandler := handlers.NewMonitorHandler(monitorService)
+	monitorHandler := handlers.NewMonitorHandler(monitorService, monitorCheckService)
 	snippetHandler := handlers.NewSnippetHandler(snippetService)
 	postHandler := handlers.NewPostHandler(postService)
 	miscHandler := handlers.NewMiscHandler()
+	exportHandler := handlers.NewExportHandler(exportService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	avatarHandler := handlers.NewAvatarHandler(avatarService, cfg.AvatarMaxBytes)
+	organizationHandler := handlers.NewOrganizationHandler(organizationService)
+	heartbeatHandler := handlers.NewHeartbeatHandler(heartbeatService)
+	incidentHandler := handlers.NewIncidentHandler(incidentService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	onCallHandler := handlers.NewOnCallHandler(onCallService)
+	maintenanceHandler := handlers.NewMaintenanceHandler(maintenanceService)
+	statusPageHandler := handlers.NewStatusPageHandler(statusPageService)
+	probeHandler := handlers.NewProbeHandler(probeService)
 
 	mux := http.NewServeMux()
 	routes.RegisterSwaggerRoutes(mux)
 	routes.RegisterMiscRoutes(mux, miscHandler)
-	routes.RegisterAuthRoutes(mux, authHandler)
 
 	authMiddleware := middleware.Auth(userRepo, cfg.JWTSecret)
+	optionalAuthMiddleware := middleware.OptionalAuth(userRepo, cfg.JWTSecret)
+	adminMiddleware := middleware.Admin(cfg.AdminUserIDs)
+	routes.RegisterAuthRoutes(mux, authHandler, authMiddleware)
 	routes.RegisterUserRoutes(mux, userHandler, authMiddleware)
 	routes.RegisterPostRoutes(mux, postHandler, authMiddleware)
 	routes.RegisterMonitorRoutes(mux, monitorHandler, authMiddleware)
-	routes.RegisterSnippetRoutes(mux, snippetHandler)
+	routes.RegisterSnippetRoutes(mux, snippetHandler, optionalAuthMiddleware)
+	routes.RegisterExportRoutes(mux, exportHandler, authMiddleware)
+	routes.RegisterAuditRoutes(mux, auditHandler, authMiddleware, adminMiddleware)
+	routes.RegisterAvatarRoutes(mux, avatarHandler, authMiddleware)
+	routes.RegisterOrganizationRoutes(mux, organizationHandler, authMiddleware)
+	routes.RegisterHeartbeatRoutes(mux, heartbeatHandler)
+	routes.RegisterIncidentRoutes(mux, incidentHandler, authMiddleware)
+	routes.RegisterNotificationRoutes(mux, notificationHandler, authMiddleware)
+	routes.RegisterOnCallRoutes(mux, onCallHandler, authMiddleware)
+	routes.RegisterMaintenanceRoutes(mux, maintenanceHandler, authMiddleware)
+	routes.RegisterStatusPageRoutes(mux, statusPageHandler, authMiddleware)
+	routes.RegisterProbeRoutes(mux, probeHandler, authMiddleware, adminMiddleware)
 
 	handler := middleware.Chain(mux,
 		middleware.Recovery(logger),
 		middleware.SecurityHeaders(),
 		middleware.CORS(cfg.AllowedOrigins),
-		middleware.Timeout(cfg.RequestTimeout),
+		middleware.Timeout(cfg.RequestTimeout, "/monitors/events"),
+		middleware.ClientInfo(),
 		middleware.Logging(logger),
 	)
 
+	// Cancelled on shutdown so open event streams end instead of holding it up.
+	baseCtx, cancelBase := context.WithCancel(context.Background())
 	server := &http.Server{
 		Addr:              ":" + cfg.Port,
 		Handler:           handler,
+		BaseContext:       func(net.Listener) context.Context { return baseCtx },
 		ReadHeaderTimeout: 5 * time.Second,
 		ReadTimeout:       10 * time.Second,
 		WriteTimeout:      10 * time.Second,
 		IdleTimeout:       60 * time.Second,
 	}
+	server.RegisterOnShutdown(cancelBase)
 
-	go func() {
-		logger.Info("server started", "port", cfg.Port)
-		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			logger.Error("server stopped unexpectedly", "error", err)
+	if servesHTTP {
+		if err := monitorEvents.Start(); err != nil {
+			logger.Error("failed to start monitor events", "error", err)
 			os.Exit(1)
 		}
-	}()
+		go func() {
+			logger.Info("server started", "port", cfg.Port)
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("server stopped unexpectedly", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
 
 	logger.Info("shutting down")
-	monitorWorker.Stop()
+	if runsWorkers {
+		monitorWorker.Stop()
+		notificationService.Stop()
+		onCallService.Stop()
+		logCompactor.Stop()
+		leases.Release()
+	}
+	exportService.Stop()
 
+	if !servesHTTP {
+		return
+	}
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		logger.Error("shutdown error", "error", err)
 	}
+	monitorEvents.Stop()
+}
+
+func runAgent(logger *slog.Logger, cfg config.Config) {
+	agent := service.NewProbeAgent(cfg.ProbeServerURL, cfg.ProbeToken, service.NewEgressPolicy(cfg.EgressAllowlist))
+	agent.Start()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	<-sigChan
+
+	logger.Info("shutting down")
+	agent.Stop()
 }
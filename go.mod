@@ -10,6 +10,7 @@ require (
 	github.com/swaggo/http-swagger v1.3.4
 	github.com/swaggo/swag v1.16.6
 	golang.org/x/crypto v0.48.0
+	golang.org/x/image v0.36.0
 	modernc.org/sqlite v1.45.0
 )
 
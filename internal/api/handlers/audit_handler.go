@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"learn/internal/api/middleware"
+	"learn/internal/api/response"
+	"learn/internal/models"
+	"learn/internal/service"
+)
+
+type AuditHandler struct {
+	audit *service.AuditService
+}
+
+func NewAuditHandler(audit *service.AuditService) *AuditHandler {
+	return &AuditHandler{audit: audit}
+}
+
+// GetProfileAudit godoc
+// @Summary List the current user's audit events
+// @Tags audit
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Start of range (RFC3339)"
+// @Param to query string false "End of range (RFC3339)"
+// @Param action query string false "Filter by action"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} types.AuditLogListResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /profile/audit [get]
+func (h *AuditHandler) GetProfileAudit(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	filter, message := parseAuditFilter(r)
+	if message != "" {
+		response.WriteError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	entries, err := h.audit.ListForUser(r.Context(), user.ID, filter)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	if entries == nil {
+		entries = []models.AuditLog{}
+	}
+
+	response.WriteSuccess(w, http.StatusOK, entries, "Audit events retrieved successfully")
+}
+
+// ListAudit godoc
+// @Summary List audit events for all users
+// @Tags audit
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Start of range (RFC3339)"
+// @Param to query string false "End of range (RFC3339)"
+// @Param actor_id query string false "Filter by actor"
+// @Param action query string false "Filter by action"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} types.AuditLogListResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /admin/audit [get]
+func (h *AuditHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	filter, message := parseAuditFilter(r)
+	if message != "" {
+		response.WriteError(w, http.StatusBadRequest, message)
+		return
+	}
+	filter.ActorID = strings.TrimSpace(r.URL.Query().Get("actor_id"))
+
+	entries, err := h.audit.List(r.Context(), filter)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	if entries == nil {
+		entries = []models.AuditLog{}
+	}
+
+	response.WriteSuccess(w, http.StatusOK, entries, "Audit events retrieved successfully")
+}
+
+func parseAuditFilter(r *http.Request) (models.AuditFilter, string) {
+	query := r.URL.Query()
+	filter := models.AuditFilter{
+		Action: strings.TrimSpace(query.Get("action")),
+	}
+
+	from, ok := parseTimeParam(query.Get("from"))
+	if !ok {
+		return models.AuditFilter{}, "from must be an RFC3339 timestamp"
+	}
+	filter.From = from
+
+	to, ok := parseTimeParam(query.Get("to"))
+	if !ok {
+		return models.AuditFilter{}, "to must be an RFC3339 timestamp"
+	}
+	filter.To = to
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return models.AuditFilter{}, "from must be before to"
+	}
+
+	limit, offset, message := parsePagination(r, 50, 200)
+	if message != "" {
+		return models.AuditFilter{}, message
+	}
+	filter.Limit = limit
+	filter.Offset = offset
+
+	return filter, ""
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 
+	"learn/internal/api/middleware"
 	"learn/internal/api/response"
 	"learn/internal/api/validator"
 	"learn/internal/repository"
@@ -97,3 +98,45 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		User:  user.Response(),
 	}, "Login successful")
 }
+
+// ChangePassword godoc
+// @Summary Change the current user's password
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body types.ChangePasswordRequest true "Change password request"
+// @Success 200 {object} types.EmptyResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /profile/password [post]
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req types.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	if err := h.auth.ChangePassword(r.Context(), user, req.CurrentPassword, req.NewPassword); err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			response.WriteError(w, http.StatusUnauthorized, "Current password is incorrect")
+			return
+		}
+		response.WriteError(w, http.StatusInternalServerError, "Failed to change password")
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, nil, "Password changed successfully")
+}
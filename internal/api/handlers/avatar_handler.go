@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"learn/internal/api/middleware"
+	"learn/internal/api/response"
+	"learn/internal/service"
+)
+
+type AvatarHandler struct {
+	avatars  *service.AvatarService
+	maxBytes int64
+}
+
+func NewAvatarHandler(avatars *service.AvatarService, maxBytes int64) *AvatarHandler {
+	return &AvatarHandler{avatars: avatars, maxBytes: maxBytes}
+}
+
+// UploadAvatar godoc
+// @Summary Upload a profile avatar
+// @Description Accepts PNG, JPEG, GIF or WebP either as the raw request body or as the "avatar" field of a multipart form. The image is cropped to a square and re-encoded as 64px and 256px PNGs.
+// @Tags users
+// @Security BearerAuth
+// @Accept image/png,image/jpeg,image/gif,image/webp,multipart/form-data
+// @Produce json
+// @Param avatar formData file false "Avatar image"
+// @Success 200 {object} types.UserResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 413 {object} types.ErrorResponseEnvelope
+// @Failure 415 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /profile/avatar [put]
+func (h *AvatarHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	data, err := h.readUpload(w, r)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.WriteError(w, http.StatusRequestEntityTooLarge, "Avatar must be at most "+strconv.FormatInt(h.maxBytes, 10)+" bytes")
+			return
+		}
+		response.WriteError(w, http.StatusBadRequest, "Invalid avatar upload")
+		return
+	}
+	if len(data) == 0 {
+		response.WriteError(w, http.StatusBadRequest, "Avatar image is required")
+		return
+	}
+
+	updated, err := h.avatars.Upload(r.Context(), user, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAvatarUnsupportedType):
+			response.WriteError(w, http.StatusUnsupportedMediaType, "Avatar must be a PNG, JPEG, GIF or WebP image")
+		case errors.Is(err, service.ErrAvatarInvalidImage):
+			response.WriteError(w, http.StatusBadRequest, "Avatar image could not be decoded")
+		case errors.Is(err, service.ErrAvatarTooLarge):
+			response.WriteError(w, http.StatusBadRequest, "Avatar image dimensions are too large")
+		default:
+			response.WriteError(w, http.StatusInternalServerError, "Failed to store avatar")
+		}
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, updated.Response(), "Avatar updated successfully")
+}
+
+// GetAvatar godoc
+// @Summary Get an avatar image
+// @Tags users
+// @Produce image/png
+// @Param id path string true "Avatar ID"
+// @Param size query int false "Size in pixels (64 or 256, default 256)"
+// @Success 200 {file} file
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /avatars/{id} [get]
+func (h *AvatarHandler) GetAvatar(w http.ResponseWriter, r *http.Request) {
+	size := service.DefaultAvatarSize
+	if raw := strings.TrimSpace(r.URL.Query().Get("size")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || !slices.Contains(service.AvatarSizes, parsed) {
+			response.WriteError(w, http.StatusBadRequest, "Invalid avatar size")
+			return
+		}
+		size = parsed
+	}
+
+	data, err := h.avatars.Get(r.Context(), r.PathValue("id"), size)
+	if err != nil {
+		if errors.Is(err, service.ErrAvatarNotFound) {
+			response.WriteError(w, http.StatusNotFound, "Avatar not found")
+			return
+		}
+		response.WriteError(w, http.StatusInternalServerError, "Failed to load avatar")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+func (h *AvatarHandler) readUpload(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	body := http.MaxBytesReader(w, r.Body, h.maxBytes+4096)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return readLimited(body, h.maxBytes)
+	}
+
+	r.Body = body
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "avatar" {
+			defer part.Close()
+			return readLimited(part, h.maxBytes)
+		}
+		_ = part.Close()
+	}
+}
+
+func readLimited(reader io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, &http.MaxBytesError{Limit: limit}
+	}
+	return data, nil
+}
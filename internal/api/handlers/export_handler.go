@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"learn/internal/api/middleware"
+	"learn/internal/api/response"
+	"learn/internal/models"
+	"learn/internal/service"
+	"learn/internal/types"
+)
+
+type ExportHandler struct {
+	exports *service.ExportService
+}
+
+func NewExportHandler(exports *service.ExportService) *ExportHandler {
+	return &ExportHandler{exports: exports}
+}
+
+// RequestExport godoc
+// @Summary Request a personal data export
+// @Description Assembles a ZIP archive of the profile, monitors with check history, posts and snippets in the background.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 202 {object} types.ExportResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /profile/export [post]
+func (h *ExportHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	export, err := h.exports.Request(r.Context(), user.ID)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Failed to request export")
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusAccepted, exportResponse(export), "Export requested successfully")
+}
+
+// GetExport godoc
+// @Summary Get personal data export status
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Export ID"
+// @Success 200 {object} types.ExportResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /profile/export/{id} [get]
+func (h *ExportHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		response.WriteError(w, http.StatusBadRequest, "Invalid export ID")
+		return
+	}
+
+	export, err := h.exports.Get(r.Context(), user.ID, id)
+	if err != nil {
+		if errors.Is(err, service.ErrExportNotFound) {
+			response.WriteError(w, http.StatusNotFound, "Export not found")
+			return
+		}
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, exportResponse(export), "Export retrieved successfully")
+}
+
+// DownloadExport godoc
+// @Summary Download a personal data export
+// @Description The link works once and expires after the configured time.
+// @Tags users
+// @Produce application/zip
+// @Param token path string true "Download token"
+// @Success 200 {file} file
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /exports/{token} [get]
+func (h *ExportHandler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(r.PathValue("token"))
+	if token == "" {
+		response.WriteError(w, http.StatusNotFound, "Export not found or link has expired")
+		return
+	}
+
+	archive, err := h.exports.Download(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, service.ErrExportNotFound) {
+			response.WriteError(w, http.StatusNotFound, "Export not found or link has expired")
+			return
+		}
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="data-export.zip"`)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(archive)
+}
+
+func exportResponse(export models.DataExport) types.ExportResponse {
+	result := types.ExportResponse{DataExport: export}
+	if export.Status == models.ExportStatusReady && export.Token != "" {
+		result.DownloadURL = "/exports/" + export.Token
+	}
+	return result
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"learn/internal/api/response"
+	"learn/internal/models"
+	"learn/internal/service"
+)
+
+type HeartbeatHandler struct {
+	heartbeats *service.HeartbeatService
+}
+
+func NewHeartbeatHandler(heartbeats *service.HeartbeatService) *HeartbeatHandler {
+	return &HeartbeatHandler{heartbeats: heartbeats}
+}
+
+// Ping godoc
+// @Summary Report a successful heartbeat
+// @Description The request body is stored as job output (truncated to 10 KB). A non-zero exit_code marks the run as failed.
+// @Tags heartbeats
+// @Accept plain
+// @Produce json
+// @Param token path string true "Ping token"
+// @Param exit_code query int false "Job exit code"
+// @Success 200 {object} types.EmptyResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /ping/{token} [post]
+func (h *HeartbeatHandler) Ping(w http.ResponseWriter, r *http.Request) {
+	h.handlePing(w, r, models.PingEventSuccess)
+}
+
+// PingStart godoc
+// @Summary Report that a job has started
+// @Description The next ping's log entry records how long the job ran.
+// @Tags heartbeats
+// @Produce json
+// @Param token path string true "Ping token"
+// @Success 200 {object} types.EmptyResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /ping/{token}/start [post]
+func (h *HeartbeatHandler) PingStart(w http.ResponseWriter, r *http.Request) {
+	h.handlePing(w, r, models.PingEventStart)
+}
+
+// PingFail godoc
+// @Summary Report a failed job run
+// @Description Counts as a failed check towards the monitor's failure_threshold. The request body is stored as job output.
+// @Tags heartbeats
+// @Accept plain
+// @Produce json
+// @Param token path string true "Ping token"
+// @Param exit_code query int false "Job exit code"
+// @Success 200 {object} types.EmptyResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /ping/{token}/fail [post]
+func (h *HeartbeatHandler) PingFail(w http.ResponseWriter, r *http.Request) {
+	h.handlePing(w, r, models.PingEventFail)
+}
+
+func (h *HeartbeatHandler) handlePing(w http.ResponseWriter, r *http.Request, event string) {
+	token := strings.TrimSpace(r.PathValue("token"))
+
+	var exitCode *int
+	if raw := r.URL.Query().Get("exit_code"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			response.WriteError(w, http.StatusBadRequest, "exit_code must be an integer")
+			return
+		}
+		exitCode = &parsed
+	}
+
+	output, err := io.ReadAll(io.LimitReader(r.Body, service.MaxPingOutputBytes+1))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+
+	if err := h.heartbeats.Ping(r.Context(), token, event, exitCode, string(output)); err != nil {
+		switch {
+		case errors.Is(err, service.ErrHeartbeatNotFound):
+			response.WriteError(w, http.StatusNotFound, "Heartbeat monitor not found")
+		case errors.Is(err, service.ErrHeartbeatPaused):
+			// The job can't tell the monitor was paused, so it isn't failed.
+			response.WriteSuccess(w, http.StatusOK, nil, "Monitor is paused; ping ignored")
+		default:
+			response.WriteError(w, http.StatusInternalServerError, "Database error")
+		}
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, nil, "Ping received")
+}
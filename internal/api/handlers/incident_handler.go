@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"learn/internal/api/middleware"
+	"learn/internal/api/response"
+	"learn/internal/api/validator"
+	"learn/internal/models"
+	"learn/internal/service"
+	"learn/internal/types"
+)
+
+type IncidentHandler struct {
+	incidents *service.IncidentService
+}
+
+func NewIncidentHandler(incidents *service.IncidentService) *IncidentHandler {
+	return &IncidentHandler{incidents: incidents}
+}
+
+// ListIncidents godoc
+// @Summary List incidents across my monitors
+// @Tags incidents
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "open or resolved"
+// @Success 200 {object} types.IncidentListResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /incidents [get]
+func (h *IncidentHandler) ListIncidents(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" && status != models.IncidentStatusOpen && status != models.IncidentStatusResolved {
+		response.WriteError(w, http.StatusBadRequest, "status must be open or resolved")
+		return
+	}
+
+	incidents, err := h.incidents.List(r.Context(), user.ID, status)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	if incidents == nil {
+		incidents = []models.Incident{}
+	}
+
+	response.WriteSuccess(w, http.StatusOK, incidents, "Incidents retrieved successfully")
+}
+
+// ListMonitorIncidents godoc
+// @Summary List a monitor's incidents
+// @Tags incidents
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Monitor ID"
+// @Success 200 {object} types.IncidentListResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /monitors/{id}/incidents [get]
+func (h *IncidentHandler) ListMonitorIncidents(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	incidents, err := h.incidents.ListByMonitor(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.WriteError(w, http.StatusNotFound, "Monitor not found")
+			return
+		}
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	if incidents == nil {
+		incidents = []models.Incident{}
+	}
+
+	response.WriteSuccess(w, http.StatusOK, incidents, "Incidents retrieved successfully")
+}
+
+// ListIncidentDeliveries godoc
+// @Summary List an incident's escalation alerts
+// @Tags incidents
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Incident ID"
+// @Success 200 {object} types.NotificationDeliveryListResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /incidents/{id}/deliveries [get]
+func (h *IncidentHandler) ListIncidentDeliveries(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	deliveries, err := h.incidents.ListDeliveries(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrIncidentNotFound) {
+			response.WriteError(w, http.StatusNotFound, "Incident not found")
+			return
+		}
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	if deliveries == nil {
+		deliveries = []models.NotificationDelivery{}
+	}
+
+	response.WriteSuccess(w, http.StatusOK, deliveries, "Deliveries retrieved successfully")
+}
+
+// AcknowledgeIncident godoc
+// @Summary Acknowledge an incident
+// @Description Marks the incident as acknowledged by the caller, which stops its escalation. Notes, when given, replace the incident's notes.
+// @Tags incidents
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Incident ID"
+// @Param request body types.IncidentAckRequest false "Notes"
+// @Success 200 {object} types.IncidentResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /incidents/{id}/ack [post]
+func (h *IncidentHandler) AcknowledgeIncident(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req types.IncidentAckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	incident, err := h.incidents.Acknowledge(r.Context(), user.ID, r.PathValue("id"), req.Notes)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrIncidentNotFound):
+			response.WriteError(w, http.StatusNotFound, "Incident not found")
+		case errors.Is(err, service.ErrMonitorForbidden):
+			response.WriteError(w, http.StatusForbidden, "Your organization role cannot acknowledge incidents")
+		default:
+			response.WriteError(w, http.StatusInternalServerError, "Database error")
+		}
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, incident, "Incident acknowledged")
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"learn/internal/api/middleware"
+	"learn/internal/api/response"
+	"learn/internal/api/validator"
+	"learn/internal/models"
+	"learn/internal/service"
+	"learn/internal/types"
+)
+
+type MaintenanceHandler struct {
+	maintenance *service.MaintenanceService
+}
+
+func NewMaintenanceHandler(maintenance *service.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{maintenance: maintenance}
+}
+
+// CreateWindow godoc
+// @Summary Schedule a maintenance window
+// @Description While the window is active its monitors raise no alerts or incidents and the time is left out of uptime. In pause mode checks are skipped; in record mode they run and are logged with the maintenance status. recurrence is an optional cron expression or RRULE (FREQ=DAILY|WEEKLY|MONTHLY with INTERVAL, BYDAY, COUNT, UNTIL) evaluated in timezone; each occurrence lasts ends_at - starts_at.
+// @Tags maintenance
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body types.MaintenanceWindowCreateRequest true "Create window"
+// @Success 201 {object} types.MaintenanceWindowResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /maintenance-windows [post]
+func (h *MaintenanceHandler) CreateWindow(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req types.MaintenanceWindowCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	window, err := h.maintenance.Create(r.Context(), user.ID, models.MaintenanceWindow{
+		Name:           req.Name,
+		OrganizationID: req.OrganizationID,
+		StartsAt:       req.StartsAt,
+		EndsAt:         req.EndsAt,
+		Recurrence:     req.Recurrence,
+		Timezone:       req.Timezone,
+		Mode:           req.Mode,
+		MonitorIDs:     req.MonitorIDs,
+	})
+	if err != nil {
+		writeMaintenanceError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusCreated, maintenanceResponse(window), "Maintenance window created successfully")
+}
+
+// GetWindows godoc
+// @Summary List maintenance windows
+// @Tags maintenance
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} types.MaintenanceWindowListResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /maintenance-windows [get]
+func (h *MaintenanceHandler) GetWindows(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	windows, err := h.maintenance.List(r.Context(), user.ID)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	result := make([]types.MaintenanceWindowResponse, 0, len(windows))
+	for _, window := range windows {
+		result = append(result, maintenanceResponse(window))
+	}
+
+	response.WriteSuccess(w, http.StatusOK, result, "Maintenance windows retrieved successfully")
+}
+
+// GetWindow godoc
+// @Summary Get a maintenance window
+// @Tags maintenance
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Window ID"
+// @Success 200 {object} types.MaintenanceWindowResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /maintenance-windows/{id} [get]
+func (h *MaintenanceHandler) GetWindow(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	window, err := h.maintenance.Get(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		writeMaintenanceError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, maintenanceResponse(window), "Maintenance window retrieved successfully")
+}
+
+// DeleteWindow godoc
+// @Summary Delete a maintenance window
+// @Description Ends the window immediately if it is active.
+// @Tags maintenance
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Window ID"
+// @Success 200 {object} types.EmptyResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /maintenance-windows/{id} [delete]
+func (h *MaintenanceHandler) DeleteWindow(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	if err := h.maintenance.Delete(r.Context(), user.ID, r.PathValue("id")); err != nil {
+		writeMaintenanceError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, nil, "Maintenance window deleted successfully")
+}
+
+func maintenanceResponse(window models.MaintenanceWindow) types.MaintenanceWindowResponse {
+	now := time.Now()
+	_, _, active := window.OccurrenceAt(now)
+	return types.MaintenanceWindowResponse{
+		MaintenanceWindow: window,
+		Active:            active,
+		NextStartsAt:      window.NextOccurrence(now),
+	}
+}
+
+func writeMaintenanceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrMaintenanceNotFound):
+		response.WriteError(w, http.StatusNotFound, "Maintenance window not found")
+	case errors.Is(err, service.ErrOrganizationNotFound):
+		response.WriteError(w, http.StatusNotFound, "Organization not found")
+	case errors.Is(err, sql.ErrNoRows):
+		response.WriteError(w, http.StatusNotFound, "Monitor not found")
+	case errors.Is(err, service.ErrInvalidMaintenanceWindow), errors.Is(err, service.ErrMaintenanceMonitorMismatch):
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrMonitorForbidden):
+		response.WriteError(w, http.StatusForbidden, "Your organization role cannot change these monitors")
+	default:
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+	}
+}
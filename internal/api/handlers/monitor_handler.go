@@ -3,8 +3,13 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"learn/internal/api/middleware"
 	"learn/internal/api/response"
@@ -14,12 +19,18 @@ import (
 	"learn/internal/types"
 )
 
+const (
+	eventKeepAliveInterval = 15 * time.Second
+	eventWriteTimeout      = 10 * time.Second
+)
+
 type MonitorHandler struct {
 	monitors *service.MonitorService
+	checks   *service.MonitorCheckService
 }
 
-func NewMonitorHandler(monitors *service.MonitorService) *MonitorHandler {
-	return &MonitorHandler{monitors: monitors}
+func NewMonitorHandler(monitors *service.MonitorService, checks *service.MonitorCheckService) *MonitorHandler {
+	return &MonitorHandler{monitors: monitors, checks: checks}
 }
 
 // CreateMonitor godoc
@@ -32,6 +43,8 @@ func NewMonitorHandler(monitors *service.MonitorService) *MonitorHandler {
 // @Success 201 {object} types.MonitorResponseEnvelope
 // @Failure 400 {object} types.ErrorResponseEnvelope
 // @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
 // @Failure 500 {object} types.ErrorResponseEnvelope
 // @Router /monitors [post]
 func (h *MonitorHandler) CreateMonitor(w http.ResponseWriter, r *http.Request) {
@@ -52,9 +65,48 @@ func (h *MonitorHandler) CreateMonitor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	monitor, err := h.monitors.Create(r.Context(), user.ID, req.Name, req.URL, req.IntervalSeconds)
+	graceSeconds := models.DefaultHeartbeatGraceSeconds
+	if req.GraceSeconds != nil {
+		graceSeconds = *req.GraceSeconds
+	}
+
+	monitor, err := h.monitors.Create(r.Context(), user.ID, models.Monitor{
+		OrganizationID:    req.OrganizationID,
+		Name:              req.Name,
+		Type:              req.Type,
+		URL:               req.URL,
+		IntervalSeconds:   req.IntervalSeconds,
+		Method:            req.Method,
+		Headers:           monitorHeaders(req.Headers),
+		Body:              req.Body,
+		AcceptedStatuses:  req.AcceptedStatuses,
+		FollowRedirects:   req.FollowRedirects == nil || *req.FollowRedirects,
+		Assertions:        monitorAssertions(req.Assertions),
+		MaxResponseBytes:  req.MaxResponseBytes,
+		DNS:               dnsCheckConfig(req.DNS),
+		TLS:               tlsCheckConfig(req.TLS),
+		GraceSeconds:      graceSeconds,
+		Retries:           req.Retries,
+		RetryDelayMs:      req.RetryDelayMs,
+		FailureThreshold:  req.FailureThreshold,
+		RecoveryThreshold: req.RecoveryThreshold,
+		SLATarget:         req.SLATarget,
+		Locations:         req.Locations,
+		Quorum:            req.Quorum,
+	})
 	if err != nil {
-		response.WriteError(w, http.StatusInternalServerError, "Failed to create monitor")
+		switch {
+		case errors.Is(err, service.ErrMonitorSecretMissing):
+			response.WriteError(w, http.StatusBadRequest, "Secret headers need a value")
+		case errors.Is(err, service.ErrInvalidMonitorAssertion), errors.Is(err, service.ErrInvalidMonitorTarget), errors.Is(err, service.ErrInvalidMonitorLocations):
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrOrganizationNotFound):
+			response.WriteError(w, http.StatusNotFound, "Organization not found")
+		case errors.Is(err, service.ErrMonitorForbidden):
+			response.WriteError(w, http.StatusForbidden, "Your organization role cannot create monitors")
+		default:
+			response.WriteError(w, http.StatusInternalServerError, "Failed to create monitor")
+		}
 		return
 	}
 
@@ -128,6 +180,235 @@ func (h *MonitorHandler) GetMonitor(w http.ResponseWriter, r *http.Request) {
 	response.WriteSuccess(w, http.StatusOK, result, "Monitor retrieved successfully")
 }
 
+// GetMonitorUptime godoc
+// @Summary Get time-weighted uptime
+// @Description Availability is the share of monitored time the monitor was not in a confirmed down state. Maintenance and the time before the first check are left out. Without from the last 24h, 7d and 30d are reported; with from (and optionally to, default now) the custom range is, up to 366 days. Each range is compared with the monitor's sla_target, or target when given, along with the error budget that target allows.
+// @Tags monitors
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Monitor ID"
+// @Param from query string false "Range start (RFC3339)"
+// @Param to query string false "Range end (RFC3339)"
+// @Param target query number false "SLA target percentage, e.g. 99.95"
+// @Success 200 {object} types.MonitorUptimeResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /monitors/{id}/uptime [get]
+func (h *MonitorHandler) GetMonitorUptime(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	query := r.URL.Query()
+	from, ok := parseTimeParam(query.Get("from"))
+	if !ok {
+		response.WriteError(w, http.StatusBadRequest, "from must be an RFC3339 timestamp")
+		return
+	}
+	to, ok := parseTimeParam(query.Get("to"))
+	if !ok {
+		response.WriteError(w, http.StatusBadRequest, "to must be an RFC3339 timestamp")
+		return
+	}
+	if from == nil && to != nil {
+		response.WriteError(w, http.StatusBadRequest, "to requires from")
+		return
+	}
+
+	var target float64
+	if raw := strings.TrimSpace(query.Get("target")); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed <= 0 || parsed >= 100 {
+			response.WriteError(w, http.StatusBadRequest, "target must be a percentage between 0 and 100")
+			return
+		}
+		target = parsed
+	}
+
+	var rangeFrom, rangeTo time.Time
+	if from != nil {
+		rangeFrom = *from
+	}
+	if to != nil {
+		rangeTo = *to
+	}
+
+	id := r.PathValue("id")
+	reports, err := h.monitors.Uptime(r.Context(), user.ID, id, rangeFrom, rangeTo, target)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.WriteError(w, http.StatusNotFound, "Monitor not found")
+		case errors.Is(err, service.ErrInvalidUptimeRange):
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			response.WriteError(w, http.StatusInternalServerError, "Database error")
+		}
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, types.MonitorUptimeResponse{MonitorID: id, Ranges: reports}, "Uptime retrieved successfully")
+}
+
+// GetMonitorHistory godoc
+// @Summary Get aggregated check history
+// @Description Buckets of checks per hour or UTC day with the number of checks, successful checks, uptime and min/avg/max/p95 response time of the successful ones. Old raw logs are compacted into hourly and daily rollups; buckets come from the rollups or the raw logs, whichever covers them. Without granularity, ranges up to 7 days are hourly and longer ones daily. Hourly history may span 31 days and daily history 366. from defaults to 24 hours before to, which defaults to now. Buckets without checks are omitted.
+// @Tags monitors
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Monitor ID"
+// @Param from query string false "Range start (RFC3339)"
+// @Param to query string false "Range end (RFC3339)"
+// @Param granularity query string false "hour or day"
+// @Success 200 {object} types.MonitorHistoryResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /monitors/{id}/history [get]
+func (h *MonitorHandler) GetMonitorHistory(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	query := r.URL.Query()
+	from, ok := parseTimeParam(query.Get("from"))
+	if !ok {
+		response.WriteError(w, http.StatusBadRequest, "from must be an RFC3339 timestamp")
+		return
+	}
+	to, ok := parseTimeParam(query.Get("to"))
+	if !ok {
+		response.WriteError(w, http.StatusBadRequest, "to must be an RFC3339 timestamp")
+		return
+	}
+	granularity := query.Get("granularity")
+	if granularity != "" && granularity != models.RollupHour && granularity != models.RollupDay {
+		response.WriteError(w, http.StatusBadRequest, "granularity must be hour or day")
+		return
+	}
+
+	rangeTo := time.Now().UTC()
+	if to != nil {
+		rangeTo = to.UTC()
+	}
+	rangeFrom := rangeTo.Add(-24 * time.Hour)
+	if from != nil {
+		rangeFrom = from.UTC()
+	}
+
+	id := r.PathValue("id")
+	buckets, granularity, err := h.monitors.History(r.Context(), user.ID, id, rangeFrom, rangeTo, granularity)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.WriteError(w, http.StatusNotFound, "Monitor not found")
+		case errors.Is(err, service.ErrInvalidUptimeRange):
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			response.WriteError(w, http.StatusInternalServerError, "Database error")
+		}
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, types.MonitorHistoryResponse{
+		MonitorID:   id,
+		Granularity: granularity,
+		From:        rangeFrom,
+		To:          rangeTo,
+		Buckets:     buckets,
+	}, "History retrieved successfully")
+}
+
+// UpdateMonitor godoc
+// @Summary Update a monitor
+// @Description Only the fields present in the body are changed; check history is kept.
+// @Description A secret header sent without a value keeps its stored value.
+// @Tags monitors
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Monitor ID"
+// @Param request body types.MonitorUpdateRequest true "Fields to change"
+// @Success 200 {object} types.MonitorResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /monitors/{id} [patch]
+func (h *MonitorHandler) UpdateMonitor(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		response.WriteError(w, http.StatusBadRequest, "Invalid monitor ID")
+		return
+	}
+
+	var req types.MonitorUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	monitor, err := h.monitors.Update(r.Context(), user.ID, id, models.MonitorUpdate{
+		Name:              req.Name,
+		Type:              req.Type,
+		URL:               req.URL,
+		IntervalSeconds:   req.IntervalSeconds,
+		Method:            req.Method,
+		Headers:           monitorHeaders(req.Headers),
+		Body:              req.Body,
+		AcceptedStatuses:  req.AcceptedStatuses,
+		FollowRedirects:   req.FollowRedirects,
+		Assertions:        monitorAssertions(req.Assertions),
+		MaxResponseBytes:  req.MaxResponseBytes,
+		DNS:               dnsCheckConfig(req.DNS),
+		TLS:               tlsCheckConfig(req.TLS),
+		GraceSeconds:      req.GraceSeconds,
+		Retries:           req.Retries,
+		RetryDelayMs:      req.RetryDelayMs,
+		FailureThreshold:  req.FailureThreshold,
+		RecoveryThreshold: req.RecoveryThreshold,
+		SLATarget:         req.SLATarget,
+		Locations:         req.Locations,
+		Quorum:            req.Quorum,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMonitorSecretMissing):
+			response.WriteError(w, http.StatusBadRequest, "Secret headers need a value unless one is already stored under that name")
+		case errors.Is(err, service.ErrInvalidMonitorAssertion), errors.Is(err, service.ErrInvalidMonitorTarget), errors.Is(err, service.ErrInvalidMonitorLocations):
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			response.WriteError(w, http.StatusNotFound, "Monitor not found")
+		case errors.Is(err, service.ErrMonitorForbidden):
+			response.WriteError(w, http.StatusForbidden, "Your organization role cannot modify this monitor")
+		default:
+			response.WriteError(w, http.StatusInternalServerError, "Failed to update monitor")
+		}
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, monitor, "Monitor updated successfully")
+}
+
 // DeleteMonitor godoc
 // @Summary Delete a monitor
 // @Tags monitors
@@ -136,6 +417,7 @@ func (h *MonitorHandler) GetMonitor(w http.ResponseWriter, r *http.Request) {
 // @Param id path string true "Monitor ID"
 // @Success 200 {object} types.EmptyResponseEnvelope
 // @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
 // @Failure 404 {object} types.ErrorResponseEnvelope
 // @Failure 500 {object} types.ErrorResponseEnvelope
 // @Router /monitors/{id} [delete]
@@ -153,6 +435,10 @@ func (h *MonitorHandler) DeleteMonitor(w http.ResponseWriter, r *http.Request) {
 	}
 
 	deleted, err := h.monitors.Delete(r.Context(), user.ID, id)
+	if errors.Is(err, service.ErrMonitorForbidden) {
+		response.WriteError(w, http.StatusForbidden, "Your organization role cannot modify this monitor")
+		return
+	}
 	if err != nil {
 		response.WriteError(w, http.StatusInternalServerError, "Database error")
 		return
@@ -173,6 +459,7 @@ func (h *MonitorHandler) DeleteMonitor(w http.ResponseWriter, r *http.Request) {
 // @Param id path string true "Monitor ID"
 // @Success 200 {object} types.EmptyResponseEnvelope
 // @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
 // @Failure 404 {object} types.ErrorResponseEnvelope
 // @Failure 500 {object} types.ErrorResponseEnvelope
 // @Router /monitors/{id}/toggle [patch]
@@ -190,6 +477,10 @@ func (h *MonitorHandler) ToggleMonitor(w http.ResponseWriter, r *http.Request) {
 	}
 
 	updated, err := h.monitors.Toggle(r.Context(), user.ID, id)
+	if errors.Is(err, service.ErrMonitorForbidden) {
+		response.WriteError(w, http.StatusForbidden, "Your organization role cannot modify this monitor")
+		return
+	}
 	if err != nil {
 		response.WriteError(w, http.StatusInternalServerError, "Database error")
 		return
@@ -202,6 +493,107 @@ func (h *MonitorHandler) ToggleMonitor(w http.ResponseWriter, r *http.Request) {
 	response.WriteSuccess(w, http.StatusOK, nil, "Monitor toggled successfully")
 }
 
+// CheckMonitor godoc
+// @Summary Check a monitor now
+// @Description Runs the monitor's check once from this server, without retries, and records it as a manual check. The result counts towards the monitor's state like a scheduled check. Heartbeat and paused monitors can't be checked on demand.
+// @Tags monitors
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Monitor ID"
+// @Success 200 {object} types.MonitorCheckResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 409 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /monitors/{id}/check [post]
+func (h *MonitorHandler) CheckMonitor(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		response.WriteError(w, http.StatusBadRequest, "Invalid monitor ID")
+		return
+	}
+
+	result, state, err := h.checks.Check(r.Context(), user.ID, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			response.WriteError(w, http.StatusNotFound, "Monitor not found")
+		case errors.Is(err, service.ErrMonitorForbidden):
+			response.WriteError(w, http.StatusForbidden, "Your organization role cannot check this monitor")
+		case errors.Is(err, service.ErrMonitorNotCheckable):
+			response.WriteError(w, http.StatusBadRequest, "Heartbeat monitors are checked by their pings")
+		case errors.Is(err, service.ErrMonitorPaused):
+			response.WriteError(w, http.StatusConflict, "Monitor is paused; resume it to check it")
+		default:
+			response.WriteError(w, http.StatusInternalServerError, "Failed to check monitor")
+		}
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, types.MonitorCheckResponse{Result: result, State: state}, "Monitor checked successfully")
+}
+
+// TestMonitor godoc
+// @Summary Test a monitor configuration
+// @Description Runs a single check for settings that haven't been saved, using the same defaults and validation as creating a monitor. Nothing is stored. The check's own outcome, up or down, is in the result.
+// @Tags monitors
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body types.MonitorTestRequest true "Check settings"
+// @Success 200 {object} types.MonitorTestResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /monitors/test [post]
+func (h *MonitorHandler) TestMonitor(w http.ResponseWriter, r *http.Request) {
+	var req types.MonitorTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	result, err := h.checks.Test(r.Context(), models.Monitor{
+		Type:             req.Type,
+		URL:              req.URL,
+		Method:           req.Method,
+		Headers:          monitorHeaders(req.Headers),
+		Body:             req.Body,
+		AcceptedStatuses: req.AcceptedStatuses,
+		FollowRedirects:  req.FollowRedirects == nil || *req.FollowRedirects,
+		Assertions:       monitorAssertions(req.Assertions),
+		MaxResponseBytes: req.MaxResponseBytes,
+		DNS:              dnsCheckConfig(req.DNS),
+		TLS:              tlsCheckConfig(req.TLS),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMonitorSecretMissing):
+			response.WriteError(w, http.StatusBadRequest, "Secret headers need a value")
+		case errors.Is(err, service.ErrInvalidMonitorAssertion), errors.Is(err, service.ErrInvalidMonitorTarget):
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			response.WriteError(w, http.StatusInternalServerError, "Failed to test monitor")
+		}
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, result, "Monitor tested successfully")
+}
+
 // GetDashboard godoc
 // @Summary Get monitor dashboard
 // @Tags monitors
@@ -218,20 +610,163 @@ func (h *MonitorHandler) GetDashboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stats, logs, err := h.monitors.Dashboard(r.Context(), user.ID)
+	stats, incidents, logs, err := h.monitors.Dashboard(r.Context(), user.ID)
 	if err != nil {
 		response.WriteError(w, http.StatusInternalServerError, "Database error")
 		return
 	}
+	if incidents == nil {
+		incidents = []models.Incident{}
+	}
 	if logs == nil {
 		logs = []models.RecentMonitorLog{}
 	}
 
 	response.WriteSuccess(w, http.StatusOK, types.MonitorDashboardResponse{
-		TotalMonitors:  stats.Total,
-		ActiveMonitors: stats.Active,
-		UpMonitors:     stats.Up,
-		DownMonitors:   stats.Down,
-		RecentLogs:     logs,
+		TotalMonitors:    stats.Total,
+		ActiveMonitors:   stats.Active,
+		UpMonitors:       stats.Up,
+		DownMonitors:     stats.Down,
+		DegradedMonitors: stats.Degraded,
+		OpenIncidents:    incidents,
+		RecentLogs:       logs,
 	}, "Dashboard retrieved successfully")
 }
+
+// StreamMonitorEvents godoc
+// @Summary Stream live monitor updates
+// @Description Server-Sent Events for the user's monitors: check for every check result, state when a monitor's status changes and incident when an incident opens, resolves or is acknowledged. Reconnect with Last-Event-ID to replay what was missed; a reset event means some events are no longer available and the client should reload its data.
+// @Tags monitors
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /monitors/events [get]
+func (h *MonitorHandler) StreamMonitorEvents(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	rawLastEventID := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	var lastEventID uint64
+	if rawLastEventID != "" {
+		parsed, err := strconv.ParseUint(rawLastEventID, 10, 64)
+		if err != nil {
+			response.WriteError(w, http.StatusBadRequest, "Last-Event-ID must be an event ID")
+			return
+		}
+		lastEventID = parsed
+	}
+
+	sub, replay, complete, err := h.monitors.Subscribe(r.Context(), user.ID, lastEventID, rawLastEventID != "")
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	defer sub.Close()
+
+	// The stream outlives the server's read and write timeouts, so it lifts
+	// them and gives each write its own deadline instead.
+	controller := http.NewResponseController(w)
+	if err := controller.SetReadDeadline(time.Time{}); err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+	send := func(frame string) bool {
+		if err := controller.SetWriteDeadline(time.Now().Add(eventWriteTimeout)); err != nil {
+			return false
+		}
+		if _, err := io.WriteString(w, frame); err != nil {
+			return false
+		}
+		return controller.Flush() == nil
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if !send("retry: 3000\n\n") {
+		return
+	}
+
+	if !complete {
+		replay = nil
+		if !send(fmt.Sprintf("id: %d\nevent: reset\ndata: {}\n\n", sub.Since())) {
+			return
+		}
+	}
+	for _, event := range replay {
+		if !send(eventFrame(event)) {
+			return
+		}
+	}
+
+	keepAlive := time.NewTicker(eventKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			// A closed channel means this client fell behind; it reconnects
+			// and catches up from Last-Event-ID.
+			if !ok || !send(eventFrame(event)) {
+				return
+			}
+		case <-keepAlive.C:
+			if !send(": keep-alive\n\n") {
+				return
+			}
+		}
+	}
+}
+
+func eventFrame(event models.MonitorEvent) string {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		data = []byte("{}")
+	}
+	return fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
+
+func monitorHeaders(headers []types.MonitorHeaderRequest) []models.MonitorHeader {
+	if headers == nil {
+		return nil
+	}
+	out := make([]models.MonitorHeader, 0, len(headers))
+	for _, header := range headers {
+		out = append(out, models.MonitorHeader{Name: header.Name, Value: header.Value, Secret: header.Secret})
+	}
+	return out
+}
+
+func monitorAssertions(assertions []types.MonitorAssertionRequest) []models.MonitorAssertion {
+	if assertions == nil {
+		return nil
+	}
+	out := make([]models.MonitorAssertion, 0, len(assertions))
+	for _, assertion := range assertions {
+		out = append(out, models.MonitorAssertion{Type: assertion.Type, Target: assertion.Target, Value: assertion.Value})
+	}
+	return out
+}
+
+func dnsCheckConfig(req *types.MonitorDNSRequest) *models.DNSCheckConfig {
+	if req == nil {
+		return nil
+	}
+	return &models.DNSCheckConfig{RecordType: req.RecordType, Expected: req.Expected, Resolver: req.Resolver}
+}
+
+func tlsCheckConfig(req *types.MonitorTLSRequest) *models.TLSCheckConfig {
+	if req == nil {
+		return nil
+	}
+	return &models.TLSCheckConfig{ExpiryThresholdDays: req.ExpiryThresholdDays}
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"learn/internal/api/middleware"
+	"learn/internal/api/response"
+	"learn/internal/api/validator"
+	"learn/internal/models"
+	"learn/internal/service"
+	"learn/internal/types"
+)
+
+type NotificationHandler struct {
+	notifications *service.NotificationService
+}
+
+func NewNotificationHandler(notifications *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notifications: notifications}
+}
+
+// CreateChannel godoc
+// @Summary Create a notification channel
+// @Description Webhook, Slack and Discord channels post to url; email channels send to email. Webhook bodies are signed with secret when one is set.
+// @Tags notifications
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body types.NotificationChannelCreateRequest true "Create channel"
+// @Success 201 {object} types.NotificationChannelResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /notifications/channels [post]
+func (h *NotificationHandler) CreateChannel(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req types.NotificationChannelCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	channel, err := h.notifications.CreateChannel(r.Context(), user.ID, models.NotificationChannel{
+		Name:     req.Name,
+		Type:     req.Type,
+		URL:      req.URL,
+		Email:    req.Email,
+		Secret:   req.Secret,
+		Template: req.Template,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidNotificationTemplate), errors.Is(err, service.ErrInvalidNotificationURL),
+			errors.Is(err, service.ErrEgressBlocked):
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			response.WriteError(w, http.StatusInternalServerError, "Failed to create channel")
+		}
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusCreated, channel, "Channel created successfully")
+}
+
+// GetChannels godoc
+// @Summary List my notification channels
+// @Tags notifications
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} types.NotificationChannelListResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /notifications/channels [get]
+func (h *NotificationHandler) GetChannels(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	channels, err := h.notifications.ListChannels(r.Context(), user.ID)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	if channels == nil {
+		channels = []models.NotificationChannel{}
+	}
+
+	response.WriteSuccess(w, http.StatusOK, channels, "Channels retrieved successfully")
+}
+
+// DeleteChannel godoc
+// @Summary Delete a notification channel
+// @Description Unlinks the channel from every monitor and abandons its pending deliveries.
+// @Tags notifications
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Channel ID"
+// @Success 200 {object} types.EmptyResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /notifications/channels/{id} [delete]
+func (h *NotificationHandler) DeleteChannel(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	deleted, err := h.notifications.DeleteChannel(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	if !deleted {
+		response.WriteError(w, http.StatusNotFound, "Channel not found")
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, nil, "Channel deleted successfully")
+}
+
+// TestChannel godoc
+// @Summary Send a test notification
+// @Description Queues a sample alert for the channel; its outcome appears in the delivery log.
+// @Tags notifications
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Channel ID"
+// @Success 202 {object} types.NotificationDeliveryResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /notifications/channels/{id}/test [post]
+func (h *NotificationHandler) TestChannel(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	delivery, err := h.notifications.TestChannel(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusAccepted, delivery, "Test notification queued")
+}
+
+// GetDeliveries godoc
+// @Summary List a channel's delivery log
+// @Tags notifications
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Channel ID"
+// @Success 200 {object} types.NotificationDeliveryListResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /notifications/channels/{id}/deliveries [get]
+func (h *NotificationHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	deliveries, err := h.notifications.ListDeliveries(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []models.NotificationDelivery{}
+	}
+
+	response.WriteSuccess(w, http.StatusOK, deliveries, "Deliveries retrieved successfully")
+}
+
+// GetMonitorChannels godoc
+// @Summary List the channels alerted for a monitor
+// @Tags notifications
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Monitor ID"
+// @Success 200 {object} types.NotificationChannelListResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /monitors/{id}/channels [get]
+func (h *NotificationHandler) GetMonitorChannels(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	channels, err := h.notifications.ListMonitorChannels(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, monitorChannelList(channels, user.ID), "Channels retrieved successfully")
+}
+
+// SetMonitorChannels godoc
+// @Summary Choose the channels alerted for a monitor
+// @Description Replaces the monitor's channels. New channels must be your own; channels linked by other organization members may be kept.
+// @Tags notifications
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Monitor ID"
+// @Param request body types.MonitorChannelsRequest true "Channel IDs"
+// @Success 200 {object} types.NotificationChannelListResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /monitors/{id}/channels [put]
+func (h *NotificationHandler) SetMonitorChannels(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req types.MonitorChannelsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	channels, err := h.notifications.SetMonitorChannels(r.Context(), user.ID, r.PathValue("id"), req.ChannelIDs)
+	if err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, monitorChannelList(channels, user.ID), "Channels updated successfully")
+}
+
+// monitorChannelList hides the targets of channels that belong to other
+// members of the monitor's organization.
+func monitorChannelList(channels []models.NotificationChannel, userID string) []models.NotificationChannel {
+	out := make([]models.NotificationChannel, 0, len(channels))
+	for _, channel := range channels {
+		if channel.UserID != userID {
+			channel.URL, channel.Email, channel.Template = "", "", ""
+		}
+		out = append(out, channel)
+	}
+	return out
+}
+
+func writeNotificationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotificationChannelNotFound):
+		response.WriteError(w, http.StatusNotFound, "Channel not found")
+	case errors.Is(err, sql.ErrNoRows):
+		response.WriteError(w, http.StatusNotFound, "Monitor not found")
+	case errors.Is(err, service.ErrMonitorForbidden):
+		response.WriteError(w, http.StatusForbidden, "Your organization role cannot change this monitor")
+	default:
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+	}
+}
@@ -0,0 +1,441 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"learn/internal/api/middleware"
+	"learn/internal/api/response"
+	"learn/internal/api/validator"
+	"learn/internal/models"
+	"learn/internal/service"
+	"learn/internal/types"
+)
+
+type OnCallHandler struct {
+	oncall *service.OnCallService
+}
+
+func NewOnCallHandler(oncall *service.OnCallService) *OnCallHandler {
+	return &OnCallHandler{oncall: oncall}
+}
+
+// CreateSchedule godoc
+// @Summary Create an on-call schedule
+// @Description Participants take turns being on call for rotation_days each (default 7), starting from rotation_start (default now). Participants must belong to the organization, or be the caller for personal schedules.
+// @Tags oncall
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body types.OnCallScheduleCreateRequest true "Create schedule"
+// @Success 201 {object} types.OnCallScheduleResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /oncall/schedules [post]
+func (h *OnCallHandler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req types.OnCallScheduleCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	schedule, err := h.oncall.CreateSchedule(r.Context(), user.ID, models.OnCallSchedule{
+		Name:           req.Name,
+		OrganizationID: req.OrganizationID,
+		RotationStart:  req.RotationStart,
+		RotationDays:   req.RotationDays,
+		Participants:   req.Participants,
+	})
+	if err != nil {
+		writeOnCallError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusCreated, scheduleResponse(schedule), "Schedule created successfully")
+}
+
+// GetSchedules godoc
+// @Summary List on-call schedules
+// @Description Lists my personal schedules and those of my organizations, with who is on call right now.
+// @Tags oncall
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} types.OnCallScheduleListResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /oncall/schedules [get]
+func (h *OnCallHandler) GetSchedules(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	schedules, err := h.oncall.ListSchedules(r.Context(), user.ID)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	result := make([]types.OnCallScheduleResponse, 0, len(schedules))
+	for _, schedule := range schedules {
+		result = append(result, scheduleResponse(schedule))
+	}
+
+	response.WriteSuccess(w, http.StatusOK, result, "Schedules retrieved successfully")
+}
+
+// GetSchedule godoc
+// @Summary Get an on-call schedule
+// @Tags oncall
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} types.OnCallScheduleResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /oncall/schedules/{id} [get]
+func (h *OnCallHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	schedule, err := h.oncall.GetSchedule(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		writeOnCallError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, scheduleResponse(schedule), "Schedule retrieved successfully")
+}
+
+// DeleteSchedule godoc
+// @Summary Delete an on-call schedule
+// @Description Escalation steps that target the schedule are skipped from then on.
+// @Tags oncall
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} types.EmptyResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /oncall/schedules/{id} [delete]
+func (h *OnCallHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	if err := h.oncall.DeleteSchedule(r.Context(), user.ID, r.PathValue("id")); err != nil {
+		writeOnCallError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, nil, "Schedule deleted successfully")
+}
+
+// CreateOverride godoc
+// @Summary Override who is on call
+// @Description Puts user_id on call between starts_at and ends_at regardless of the rotation. The most recently created override wins when several overlap.
+// @Tags oncall
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Param request body types.OnCallOverrideCreateRequest true "Create override"
+// @Success 201 {object} types.OnCallScheduleResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /oncall/schedules/{id}/overrides [post]
+func (h *OnCallHandler) CreateOverride(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req types.OnCallOverrideCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	schedule, err := h.oncall.AddOverride(r.Context(), user.ID, r.PathValue("id"), models.OnCallOverride{
+		UserID:   req.UserID,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+	})
+	if err != nil {
+		writeOnCallError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusCreated, scheduleResponse(schedule), "Override created successfully")
+}
+
+// DeleteOverride godoc
+// @Summary Delete an on-call override
+// @Tags oncall
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Param overrideID path string true "Override ID"
+// @Success 200 {object} types.EmptyResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /oncall/schedules/{id}/overrides/{overrideID} [delete]
+func (h *OnCallHandler) DeleteOverride(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	if err := h.oncall.DeleteOverride(r.Context(), user.ID, r.PathValue("id"), r.PathValue("overrideID")); err != nil {
+		writeOnCallError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, nil, "Override deleted successfully")
+}
+
+// CreatePolicy godoc
+// @Summary Create an escalation policy
+// @Description Steps run in order while an incident stays unacknowledged; each fires delay_minutes after the previous one (the first after the incident opens). Targets are on-call schedules, users or my notification channels.
+// @Tags oncall
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body types.EscalationPolicyCreateRequest true "Create policy"
+// @Success 201 {object} types.EscalationPolicyResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /escalation-policies [post]
+func (h *OnCallHandler) CreatePolicy(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req types.EscalationPolicyCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	steps := make([]models.EscalationStep, 0, len(req.Steps))
+	for _, step := range req.Steps {
+		targets := make([]models.EscalationTarget, 0, len(step.Targets))
+		for _, target := range step.Targets {
+			targets = append(targets, models.EscalationTarget{Type: target.Type, ID: target.ID})
+		}
+		steps = append(steps, models.EscalationStep{DelayMinutes: step.DelayMinutes, Targets: targets})
+	}
+
+	policy, err := h.oncall.CreatePolicy(r.Context(), user.ID, models.EscalationPolicy{
+		Name:           req.Name,
+		OrganizationID: req.OrganizationID,
+		Steps:          steps,
+	})
+	if err != nil {
+		writeOnCallError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusCreated, policy, "Escalation policy created successfully")
+}
+
+// GetPolicies godoc
+// @Summary List escalation policies
+// @Tags oncall
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} types.EscalationPolicyListResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /escalation-policies [get]
+func (h *OnCallHandler) GetPolicies(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	policies, err := h.oncall.ListPolicies(r.Context(), user.ID)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	if policies == nil {
+		policies = []models.EscalationPolicy{}
+	}
+
+	response.WriteSuccess(w, http.StatusOK, policies, "Escalation policies retrieved successfully")
+}
+
+// GetPolicy godoc
+// @Summary Get an escalation policy
+// @Tags oncall
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Policy ID"
+// @Success 200 {object} types.EscalationPolicyResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /escalation-policies/{id} [get]
+func (h *OnCallHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	policy, err := h.oncall.GetPolicy(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		writeOnCallError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, policy, "Escalation policy retrieved successfully")
+}
+
+// DeletePolicy godoc
+// @Summary Delete an escalation policy
+// @Description Detaches the policy from its monitors and stops escalating their open incidents.
+// @Tags oncall
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Policy ID"
+// @Success 200 {object} types.EmptyResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /escalation-policies/{id} [delete]
+func (h *OnCallHandler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	if err := h.oncall.DeletePolicy(r.Context(), user.ID, r.PathValue("id")); err != nil {
+		writeOnCallError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, nil, "Escalation policy deleted successfully")
+}
+
+// SetMonitorPolicy godoc
+// @Summary Set a monitor's escalation policy
+// @Description Incidents opened from now on escalate through the policy. An empty policy_id detaches it. The policy must belong to the same organization as the monitor.
+// @Tags oncall
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Monitor ID"
+// @Param request body types.MonitorEscalationPolicyRequest true "Policy"
+// @Success 200 {object} types.MonitorResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /monitors/{id}/escalation-policy [put]
+func (h *OnCallHandler) SetMonitorPolicy(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req types.MonitorEscalationPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	monitor, err := h.oncall.SetMonitorPolicy(r.Context(), user.ID, r.PathValue("id"), req.PolicyID)
+	if err != nil {
+		writeOnCallError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, monitor, "Escalation policy updated successfully")
+}
+
+func scheduleResponse(schedule models.OnCallSchedule) types.OnCallScheduleResponse {
+	return types.OnCallScheduleResponse{OnCallSchedule: schedule, OnCallUserID: schedule.OnCallAt(time.Now())}
+}
+
+func writeOnCallError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrScheduleNotFound):
+		response.WriteError(w, http.StatusNotFound, "Schedule not found")
+	case errors.Is(err, service.ErrOverrideNotFound):
+		response.WriteError(w, http.StatusNotFound, "Override not found")
+	case errors.Is(err, service.ErrEscalationPolicyNotFound):
+		response.WriteError(w, http.StatusNotFound, "Escalation policy not found")
+	case errors.Is(err, service.ErrOrganizationNotFound):
+		response.WriteError(w, http.StatusNotFound, "Organization not found")
+	case errors.Is(err, sql.ErrNoRows):
+		response.WriteError(w, http.StatusNotFound, "Monitor not found")
+	case errors.Is(err, service.ErrOnCallUserNotAllowed), errors.Is(err, service.ErrInvalidEscalationTarget):
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrEscalationPolicyForbidden):
+		response.WriteError(w, http.StatusForbidden, "Your organization role cannot change on-call settings")
+	case errors.Is(err, service.ErrMonitorForbidden):
+		response.WriteError(w, http.StatusForbidden, "Your organization role cannot change this monitor")
+	default:
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+	}
+}
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"learn/internal/api/middleware"
+	"learn/internal/api/response"
+	"learn/internal/api/validator"
+	"learn/internal/models"
+	"learn/internal/service"
+	"learn/internal/types"
+)
+
+type OrganizationHandler struct {
+	organizations *service.OrganizationService
+}
+
+func NewOrganizationHandler(organizations *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{organizations: organizations}
+}
+
+// CreateOrganization godoc
+// @Summary Create an organization
+// @Tags organizations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body types.OrganizationCreateRequest true "Create organization"
+// @Success 201 {object} types.OrganizationResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /organizations [post]
+func (h *OrganizationHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req types.OrganizationCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	organization, err := h.organizations.Create(r.Context(), user.ID, req.Name)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Failed to create organization")
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusCreated, organization, "Organization created successfully")
+}
+
+// GetOrganizations godoc
+// @Summary List my organizations
+// @Tags organizations
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} types.OrganizationListResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /organizations [get]
+func (h *OrganizationHandler) GetOrganizations(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	organizations, err := h.organizations.List(r.Context(), user.ID)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	if organizations == nil {
+		organizations = []models.Organization{}
+	}
+
+	response.WriteSuccess(w, http.StatusOK, organizations, "Organizations retrieved successfully")
+}
+
+// GetOrganization godoc
+// @Summary Get organization with members
+// @Tags organizations
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} types.OrganizationDetailResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /organizations/{id} [get]
+func (h *OrganizationHandler) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	organization, members, err := h.organizations.Get(r.Context(), user.ID, strings.TrimSpace(r.PathValue("id")))
+	if err != nil {
+		writeOrganizationError(w, err)
+		return
+	}
+	if members == nil {
+		members = []models.OrganizationMember{}
+	}
+
+	response.WriteSuccess(w, http.StatusOK, types.OrganizationDetailResponse{
+		Organization: organization,
+		Members:      members,
+	}, "Organization retrieved successfully")
+}
+
+// UpdateMember godoc
+// @Summary Change a member's role
+// @Tags organizations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param userID path string true "Member user ID"
+// @Param request body types.OrganizationMemberUpdateRequest true "New role"
+// @Success 200 {object} types.EmptyResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 409 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /organizations/{id}/members/{userID} [patch]
+func (h *OrganizationHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req types.OrganizationMemberUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	err := h.organizations.UpdateMemberRole(r.Context(), user.ID, r.PathValue("id"), r.PathValue("userID"), req.Role)
+	if err != nil {
+		writeOrganizationError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, nil, "Member updated successfully")
+}
+
+// RemoveMember godoc
+// @Summary Remove a member or leave an organization
+// @Tags organizations
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param userID path string true "Member user ID"
+// @Success 200 {object} types.EmptyResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 409 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /organizations/{id}/members/{userID} [delete]
+func (h *OrganizationHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	if err := h.organizations.RemoveMember(r.Context(), user.ID, r.PathValue("id"), r.PathValue("userID")); err != nil {
+		writeOrganizationError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, nil, "Member removed successfully")
+}
+
+// CreateInvite godoc
+// @Summary Invite someone to an organization by email
+// @Tags organizations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body types.OrganizationInviteRequest true "Invite"
+// @Success 201 {object} types.OrganizationInviteResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /organizations/{id}/invites [post]
+func (h *OrganizationHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req types.OrganizationInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	invite, token, err := h.organizations.Invite(r.Context(), user, r.PathValue("id"), req.Email, req.Role)
+	if err != nil {
+		writeOrganizationError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusCreated, types.OrganizationInviteResponse{
+		OrganizationInvite: invite,
+		Token:              token,
+	}, "Invite sent successfully")
+}
+
+// GetInvites godoc
+// @Summary List pending invites
+// @Tags organizations
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} types.OrganizationInviteListResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /organizations/{id}/invites [get]
+func (h *OrganizationHandler) GetInvites(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	invites, err := h.organizations.ListInvites(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		writeOrganizationError(w, err)
+		return
+	}
+	if invites == nil {
+		invites = []models.OrganizationInvite{}
+	}
+
+	response.WriteSuccess(w, http.StatusOK, invites, "Invites retrieved successfully")
+}
+
+// RevokeInvite godoc
+// @Summary Revoke a pending invite
+// @Tags organizations
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param inviteID path string true "Invite ID"
+// @Success 200 {object} types.EmptyResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /organizations/{id}/invites/{inviteID} [delete]
+func (h *OrganizationHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	if err := h.organizations.RevokeInvite(r.Context(), user.ID, r.PathValue("id"), r.PathValue("inviteID")); err != nil {
+		writeOrganizationError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, nil, "Invite revoked successfully")
+}
+
+// AcceptInvite godoc
+// @Summary Accept an organization invite
+// @Description The signed-in user's email must match the invited address.
+// @Tags organizations
+// @Security BearerAuth
+// @Produce json
+// @Param token path string true "Invite token"
+// @Success 200 {object} types.OrganizationResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 409 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /invites/{token}/accept [post]
+func (h *OrganizationHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	organization, err := h.organizations.AcceptInvite(r.Context(), user, r.PathValue("token"))
+	if err != nil {
+		writeOrganizationError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, organization, "Invite accepted successfully")
+}
+
+func writeOrganizationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrOrganizationNotFound):
+		response.WriteError(w, http.StatusNotFound, "Organization not found")
+	case errors.Is(err, service.ErrMemberNotFound):
+		response.WriteError(w, http.StatusNotFound, "Member not found")
+	case errors.Is(err, service.ErrInviteNotFound):
+		response.WriteError(w, http.StatusNotFound, "Invite not found or has expired")
+	case errors.Is(err, service.ErrOrganizationForbidden):
+		response.WriteError(w, http.StatusForbidden, "Your organization role does not allow this")
+	case errors.Is(err, service.ErrInviteEmailMismatch):
+		response.WriteError(w, http.StatusForbidden, "This invite was sent to a different email address")
+	case errors.Is(err, service.ErrLastOwner):
+		response.WriteError(w, http.StatusConflict, "Organization must keep at least one owner")
+	case errors.Is(err, service.ErrAlreadyMember):
+		response.WriteError(w, http.StatusConflict, "You are already a member of this organization")
+	default:
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+	}
+}
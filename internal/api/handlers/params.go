@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func parseTimeParam(raw string) (*time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, true
+	}
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, false
+	}
+	return &parsed, true
+}
+
+func parsePagination(r *http.Request, defaultLimit, maxLimit int) (int, int, string) {
+	query := r.URL.Query()
+
+	limit := defaultLimit
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxLimit {
+			return 0, 0, "limit must be between 1 and " + strconv.Itoa(maxLimit)
+		}
+		limit = parsed
+	}
+
+	offset := 0
+	if raw := strings.TrimSpace(query.Get("offset")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			return 0, 0, "offset must be a non-negative integer"
+		}
+		offset = parsed
+	}
+
+	return limit, offset, ""
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"learn/internal/api/middleware"
+	"learn/internal/api/response"
+	"learn/internal/api/validator"
+	"learn/internal/models"
+	"learn/internal/repository"
+	"learn/internal/service"
+	"learn/internal/types"
+)
+
+const maxProbeResultsBytes = 4 << 20
+
+type ProbeHandler struct {
+	probes *service.ProbeService
+}
+
+func NewProbeHandler(probes *service.ProbeService) *ProbeHandler {
+	return &ProbeHandler{probes: probes}
+}
+
+// CreateProbe godoc
+// @Summary Register a probe
+// @Description The token in the response is only shown once. Run the probe with RUN_MODE=agent, PROBE_SERVER_URL and PROBE_TOKEN set.
+// @Tags probes
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body types.ProbeCreateRequest true "Probe"
+// @Success 201 {object} types.ProbeCreateResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 409 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /admin/probes [post]
+func (h *ProbeHandler) CreateProbe(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req types.ProbeCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	probe, token, err := h.probes.Create(r.Context(), user.ID, req.Location)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrProbeLocationReserved):
+			response.WriteError(w, http.StatusBadRequest, "The local location is reserved for checks run by the server")
+		case errors.Is(err, repository.ErrProbeExists):
+			response.WriteError(w, http.StatusConflict, "A probe is already registered for this location")
+		default:
+			response.WriteError(w, http.StatusInternalServerError, "Failed to create probe")
+		}
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusCreated, types.ProbeCreateResponse{
+		Probe: probe,
+		Token: token,
+	}, "Probe created successfully")
+}
+
+// ListProbes godoc
+// @Summary List probes
+// @Tags probes
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} types.ProbeListResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /admin/probes [get]
+func (h *ProbeHandler) ListProbes(w http.ResponseWriter, r *http.Request) {
+	probes, err := h.probes.List(r.Context())
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	if probes == nil {
+		probes = []models.Probe{}
+	}
+
+	response.WriteSuccess(w, http.StatusOK, probes, "Probes retrieved successfully")
+}
+
+// DeleteProbe godoc
+// @Summary Delete a probe
+// @Description The probe's token stops working. Monitors still listing its location treat it as not reporting.
+// @Tags probes
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Probe ID"
+// @Success 200 {object} types.EmptyResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /admin/probes/{id} [delete]
+func (h *ProbeHandler) DeleteProbe(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	if err := h.probes.Delete(r.Context(), user.ID, r.PathValue("id")); err != nil {
+		if errors.Is(err, service.ErrProbeNotFound) {
+			response.WriteError(w, http.StatusNotFound, "Probe not found")
+			return
+		}
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, nil, "Probe deleted successfully")
+}
+
+// GetLocations godoc
+// @Summary List the locations monitors can be checked from
+// @Tags probes
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} types.LocationListResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /locations [get]
+func (h *ProbeHandler) GetLocations(w http.ResponseWriter, r *http.Request) {
+	locations, err := h.probes.Locations(r.Context())
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, locations, "Locations retrieved successfully")
+}
+
+// GetProbeChecks godoc
+// @Summary List the checks assigned to the calling probe
+// @Description Authenticated with the probe's token. Secret header values are included.
+// @Tags probes
+// @Produce json
+// @Param Authorization header string true "Bearer probe token"
+// @Success 200 {object} types.ProbeCheckListResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /probes/checks [get]
+func (h *ProbeHandler) GetProbeChecks(w http.ResponseWriter, r *http.Request) {
+	probe, ok := h.authenticateProbe(w, r)
+	if !ok {
+		return
+	}
+
+	checks, err := h.probes.Assignments(r.Context(), probe)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, checks, "Checks retrieved successfully")
+}
+
+// ReportProbeResults godoc
+// @Summary Report check results from the calling probe
+// @Description Authenticated with the probe's token. Results for monitors not assigned to the probe are ignored.
+// @Tags probes
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer probe token"
+// @Param request body types.ProbeResultsRequest true "Results"
+// @Success 200 {object} types.ProbeResultsResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /probes/results [post]
+func (h *ProbeHandler) ReportProbeResults(w http.ResponseWriter, r *http.Request) {
+	probe, ok := h.authenticateProbe(w, r)
+	if !ok {
+		return
+	}
+
+	var req types.ProbeResultsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxProbeResultsBytes)).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	recorded, err := h.probes.Report(r.Context(), probe, req.Results)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, types.ProbeResultsResponse{Recorded: recorded}, "Results recorded successfully")
+}
+
+func (h *ProbeHandler) authenticateProbe(w http.ResponseWriter, r *http.Request) (models.Probe, bool) {
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(scheme, "bearer") {
+		response.WriteError(w, http.StatusUnauthorized, "Invalid authorization header format. Use: Bearer <token>")
+		return models.Probe{}, false
+	}
+
+	probe, err := h.probes.Authenticate(r.Context(), strings.TrimSpace(token))
+	if err != nil {
+		if errors.Is(err, service.ErrProbeUnauthorized) {
+			response.WriteError(w, http.StatusUnauthorized, "Invalid probe token")
+			return models.Probe{}, false
+		}
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return models.Probe{}, false
+	}
+	return probe, true
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 
+	"learn/internal/api/middleware"
 	"learn/internal/api/response"
 	"learn/internal/api/validator"
 	"learn/internal/service"
@@ -21,6 +22,7 @@ func NewSnippetHandler(snippets *service.SnippetService) *SnippetHandler {
 
 // CreateSnippet godoc
 // @Summary Create a snippet
+// @Description Snippets created with a bearer token are attributed to that user.
 // @Tags snippets
 // @Accept json
 // @Produce json
@@ -41,7 +43,12 @@ func (h *SnippetHandler) CreateSnippet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	snippet, err := h.snippets.Create(r.Context(), req.Content, req.Password, req.BurnAfterRead, req.ExpiresInHours)
+	var userID string
+	if user, ok := middleware.GetUserFromContext(r); ok {
+		userID = user.ID
+	}
+
+	snippet, err := h.snippets.Create(r.Context(), userID, req.Content, req.Password, req.BurnAfterRead, req.ExpiresInHours)
 	if err != nil {
 		response.WriteError(w, http.StatusInternalServerError, "Failed to create snippet")
 		return
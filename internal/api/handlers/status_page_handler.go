@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"learn/internal/api/middleware"
+	"learn/internal/api/response"
+	"learn/internal/api/validator"
+	"learn/internal/models"
+	"learn/internal/repository"
+	"learn/internal/service"
+	"learn/internal/types"
+)
+
+//go:embed templates/status_page.html
+var statusPageHTML string
+
+var statusPageTemplate = template.Must(template.New("status_page").Funcs(template.FuncMap{
+	"css": func(value string) template.CSS { return template.CSS(value) },
+	"statusLabel": func(status string) string {
+		switch status {
+		case models.StatusPageOperational:
+			return "All systems operational"
+		case models.StatusPageDegraded:
+			return "Degraded performance"
+		case models.StatusPagePartialOutage:
+			return "Partial outage"
+		case models.StatusPageMajorOutage:
+			return "Major outage"
+		default:
+			return "Scheduled maintenance"
+		}
+	},
+	"uptimeClass": func(uptime *float64) string {
+		switch {
+		case uptime == nil:
+			return ""
+		case *uptime >= 99.9:
+			return "good"
+		case *uptime >= 95:
+			return "fair"
+		default:
+			return "poor"
+		}
+	},
+	"formatUptime": func(uptime *float64) string {
+		if uptime == nil {
+			return "No data"
+		}
+		return fmt.Sprintf("%.2f%%", *uptime)
+	},
+}).Parse(statusPageHTML))
+
+type StatusPageHandler struct {
+	pages *service.StatusPageService
+}
+
+func NewStatusPageHandler(pages *service.StatusPageService) *StatusPageHandler {
+	return &StatusPageHandler{pages: pages}
+}
+
+// CreateStatusPage godoc
+// @Summary Create a status page
+// @Description Publishes the selected monitors at /status/{slug}. Visitors see monitor names, states and uptime but never URLs. custom_css is added to the page's stylesheet and may not contain "<".
+// @Tags status-pages
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body types.StatusPageRequest true "Create status page"
+// @Success 201 {object} types.StatusPageResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 409 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /status-pages [post]
+func (h *StatusPageHandler) CreateStatusPage(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	page, ok := decodeStatusPageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.pages.Create(r.Context(), user.ID, page)
+	if err != nil {
+		writeStatusPageError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusCreated, created, "Status page created successfully")
+}
+
+// GetStatusPages godoc
+// @Summary List status pages
+// @Tags status-pages
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} types.StatusPageListResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /status-pages [get]
+func (h *StatusPageHandler) GetStatusPages(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	pages, err := h.pages.List(r.Context(), user.ID)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	if pages == nil {
+		pages = []models.StatusPage{}
+	}
+
+	response.WriteSuccess(w, http.StatusOK, pages, "Status pages retrieved successfully")
+}
+
+// GetStatusPage godoc
+// @Summary Get a status page's configuration
+// @Tags status-pages
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Status page ID"
+// @Success 200 {object} types.StatusPageResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /status-pages/{id} [get]
+func (h *StatusPageHandler) GetStatusPage(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	page, err := h.pages.Get(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		writeStatusPageError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, page, "Status page retrieved successfully")
+}
+
+// UpdateStatusPage godoc
+// @Summary Replace a status page's configuration
+// @Tags status-pages
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Status page ID"
+// @Param request body types.StatusPageRequest true "Status page"
+// @Success 200 {object} types.StatusPageResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 409 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /status-pages/{id} [put]
+func (h *StatusPageHandler) UpdateStatusPage(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	page, ok := decodeStatusPageRequest(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.pages.Update(r.Context(), user.ID, r.PathValue("id"), page)
+	if err != nil {
+		writeStatusPageError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, updated, "Status page updated successfully")
+}
+
+// DeleteStatusPage godoc
+// @Summary Delete a status page
+// @Tags status-pages
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Status page ID"
+// @Success 200 {object} types.EmptyResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 403 {object} types.ErrorResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /status-pages/{id} [delete]
+func (h *StatusPageHandler) DeleteStatusPage(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	if err := h.pages.Delete(r.Context(), user.ID, r.PathValue("id")); err != nil {
+		writeStatusPageError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, nil, "Status page deleted successfully")
+}
+
+// GetPublicStatusPage godoc
+// @Summary View a public status page
+// @Description No authentication required. Renders HTML by default; send Accept: application/json or ?format=json for the same data as JSON. Each monitor has 90 daily uptime bars (UTC days, oldest first, null when there were no checks) and active incidents are listed.
+// @Tags status-pages
+// @Produce html
+// @Produce json
+// @Param slug path string true "Status page slug"
+// @Param format query string false "json to get JSON"
+// @Success 200 {object} types.PublicStatusPageResponseEnvelope
+// @Failure 404 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /status/{slug} [get]
+func (h *StatusPageHandler) GetPublicStatusPage(w http.ResponseWriter, r *http.Request) {
+	wantsJSON := r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json")
+
+	page, err := h.pages.Public(r.Context(), r.PathValue("slug"))
+	if err != nil {
+		if !wantsJSON && errors.Is(err, service.ErrStatusPageNotFound) {
+			http.Error(w, "Status page not found", http.StatusNotFound)
+			return
+		}
+		writeStatusPageError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=30")
+	if wantsJSON {
+		response.WriteSuccess(w, http.StatusOK, page, "Status page retrieved successfully")
+		return
+	}
+
+	var body bytes.Buffer
+	if err := statusPageTemplate.Execute(&body, page); err != nil {
+		http.Error(w, "Could not render status page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = body.WriteTo(w)
+}
+
+func decodeStatusPageRequest(w http.ResponseWriter, r *http.Request) (models.StatusPage, bool) {
+	var req types.StatusPageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return models.StatusPage{}, false
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return models.StatusPage{}, false
+	}
+
+	sections := make([]models.StatusPageSection, 0, len(req.Sections))
+	for _, section := range req.Sections {
+		monitorIDs := section.MonitorIDs
+		if monitorIDs == nil {
+			monitorIDs = []string{}
+		}
+		sections = append(sections, models.StatusPageSection{Name: section.Name, MonitorIDs: monitorIDs})
+	}
+
+	return models.StatusPage{
+		OrganizationID: req.OrganizationID,
+		Slug:           req.Slug,
+		Title:          req.Title,
+		Description:    req.Description,
+		CustomCSS:      req.CustomCSS,
+		Sections:       sections,
+	}, true
+}
+
+func writeStatusPageError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrStatusPageNotFound):
+		response.WriteError(w, http.StatusNotFound, "Status page not found")
+	case errors.Is(err, service.ErrOrganizationNotFound):
+		response.WriteError(w, http.StatusNotFound, "Organization not found")
+	case errors.Is(err, sql.ErrNoRows):
+		response.WriteError(w, http.StatusNotFound, "Monitor not found")
+	case errors.Is(err, repository.ErrStatusPageSlugTaken):
+		response.WriteError(w, http.StatusConflict, "Slug is already taken")
+	case errors.Is(err, service.ErrInvalidStatusPage), errors.Is(err, service.ErrStatusPageMonitorMismatch):
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrMonitorForbidden):
+		response.WriteError(w, http.StatusForbidden, "Your organization role cannot publish these monitors")
+	default:
+		response.WriteError(w, http.StatusInternalServerError, "Database error")
+	}
+}
@@ -1,14 +1,17 @@
 package handlers
 
 import (
-	"database/sql"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
 	"learn/internal/api/middleware"
 	"learn/internal/api/response"
+	"learn/internal/api/validator"
 	"learn/internal/models"
 	"learn/internal/service"
+	"learn/internal/types"
 )
 
 type UserHandler struct {
@@ -20,33 +23,50 @@ func NewUserHandler(users *service.UserService) *UserHandler {
 }
 
 // GetUsers godoc
-// @Summary List all users
+// @Summary List public user profiles
 // @Tags users
 // @Produce json
+// @Param q query string false "Search by username or display name"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param offset query int false "Offset"
 // @Success 200 {object} types.UsersResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
 // @Failure 500 {object} types.ErrorResponseEnvelope
 // @Router /users [get]
 func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := h.users.List(r.Context())
+	limit, offset, message := parsePagination(r, 20, 100)
+	if message != "" {
+		response.WriteError(w, http.StatusBadRequest, message)
+		return
+	}
+
+	users, total, err := h.users.List(r.Context(), models.UserFilter{
+		Search: strings.TrimSpace(r.URL.Query().Get("q")),
+		Limit:  limit,
+		Offset: offset,
+	})
 	if err != nil {
 		response.WriteError(w, http.StatusInternalServerError, "Database error")
 		return
 	}
-
-	result := make([]models.UserResponse, 0, len(users))
-	for _, user := range users {
-		result = append(result, user.Response())
+	if users == nil {
+		users = []models.PublicProfile{}
 	}
 
-	response.WriteSuccess(w, http.StatusOK, result, "Users retrieved successfully")
+	response.WriteSuccess(w, http.StatusOK, types.UserListResponse{
+		Users:  users,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, "Users retrieved successfully")
 }
 
 // GetUser godoc
-// @Summary Get user by ID
+// @Summary Get public user profile by ID
 // @Tags users
 // @Produce json
 // @Param id path string true "User ID"
-// @Success 200 {object} types.UserResponseEnvelope
+// @Success 200 {object} types.PublicProfileResponseEnvelope
 // @Failure 400 {object} types.ErrorResponseEnvelope
 // @Failure 404 {object} types.ErrorResponseEnvelope
 // @Failure 500 {object} types.ErrorResponseEnvelope
@@ -58,9 +78,9 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.users.GetByID(r.Context(), id)
+	profile, err := h.users.GetPublic(r.Context(), id)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, service.ErrUserNotFound) {
 			response.WriteError(w, http.StatusNotFound, "User not found")
 			return
 		}
@@ -68,7 +88,7 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response.WriteSuccess(w, http.StatusOK, user.Response(), "User retrieved successfully")
+	response.WriteSuccess(w, http.StatusOK, profile, "User retrieved successfully")
 }
 
 // GetProfile godoc
@@ -89,3 +109,42 @@ func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 
 	response.WriteSuccess(w, http.StatusOK, user.Response(), "Profile retrieved successfully")
 }
+
+// UpdateProfile godoc
+// @Summary Update current user's profile
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body types.ProfileUpdateRequest true "Profile fields to change"
+// @Success 200 {object} types.UserResponseEnvelope
+// @Failure 400 {object} types.ErrorResponseEnvelope
+// @Failure 401 {object} types.ErrorResponseEnvelope
+// @Failure 500 {object} types.ErrorResponseEnvelope
+// @Router /profile [patch]
+func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	user, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req types.ProfileUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validator.Validate(req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, validator.FormatErrorsString(err))
+		return
+	}
+
+	updated, err := h.users.UpdateProfile(r.Context(), user, req.DisplayName, req.Bio)
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "Failed to update profile")
+		return
+	}
+
+	response.WriteSuccess(w, http.StatusOK, updated.Response(), "Profile updated successfully")
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"learn/internal/api/response"
+)
+
+// Admin lets through the users whose IDs are listed. Emails aren't verified,
+// so they can't be used to grant access.
+func Admin(adminUserIDs []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := GetUserFromContext(r)
+			if !ok {
+				response.WriteError(w, http.StatusUnauthorized, "User not found in context")
+				return
+			}
+
+			if !slices.Contains(adminUserIDs, user.ID) {
+				response.WriteError(w, http.StatusForbidden, "Admin access required")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
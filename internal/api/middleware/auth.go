@@ -27,26 +27,30 @@ func Auth(users repository.UserRepository, jwtSecret string) func(http.Handler)
 				return
 			}
 
-			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-				response.WriteError(w, http.StatusUnauthorized, "Invalid authorization header format. Use: Bearer <token>")
+			user, status, message := authenticate(r, users, jwtSecret, authHeader)
+			if status != 0 {
+				response.WriteError(w, status, message)
 				return
 			}
 
-			tokenString := parts[1]
-			claims, err := jwt.ValidateToken(jwtSecret, tokenString)
-			if err != nil {
-				response.WriteError(w, http.StatusUnauthorized, "Invalid or expired token")
-				return
-			}
+			ctx := context.WithValue(r.Context(), UserKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
-			user, err := users.GetByID(r.Context(), claims.UserID)
-			if err == sql.ErrNoRows {
-				response.WriteError(w, http.StatusUnauthorized, "User not found")
+func OptionalAuth(users repository.UserRepository, jwtSecret string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				next.ServeHTTP(w, r)
 				return
 			}
-			if err != nil {
-				response.WriteError(w, http.StatusInternalServerError, "Database error")
+
+			user, status, message := authenticate(r, users, jwtSecret, authHeader)
+			if status != 0 {
+				response.WriteError(w, status, message)
 				return
 			}
 
@@ -56,6 +60,29 @@ func Auth(users repository.UserRepository, jwtSecret string) func(http.Handler)
 	}
 }
 
+func authenticate(r *http.Request, users repository.UserRepository, jwtSecret, authHeader string) (models.User, int, string) {
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return models.User{}, http.StatusUnauthorized, "Invalid authorization header format. Use: Bearer <token>"
+	}
+
+	tokenString := parts[1]
+	claims, err := jwt.ValidateToken(jwtSecret, tokenString)
+	if err != nil {
+		return models.User{}, http.StatusUnauthorized, "Invalid or expired token"
+	}
+
+	user, err := users.GetByID(r.Context(), claims.UserID)
+	if err == sql.ErrNoRows {
+		return models.User{}, http.StatusUnauthorized, "User not found"
+	}
+	if err != nil {
+		return models.User{}, http.StatusInternalServerError, "Database error"
+	}
+
+	return user, 0, ""
+}
+
 func GetUserFromContext(r *http.Request) (models.User, bool) {
 	user, ok := r.Context().Value(UserKey).(models.User)
 	return user, ok
@@ -17,7 +17,7 @@ func CORS(allowedOrigins []string) func(http.Handler) http.Handler {
 					w.Header().Set("Access-Control-Allow-Origin", "*")
 				}
 				w.Header().Set("Vary", "Origin")
-				w.Header().Set("Access-Control-Allow-Methods", "GET,POST,OPTIONS,PATCH,DELETE")
+				w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,OPTIONS,PATCH,DELETE")
 				w.Header().Set("Access-Control-Allow-Headers", "Authorization,Content-Type")
 			}
 
@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 
@@ -39,10 +40,12 @@ func (tw *timeoutWriter) Write(p []byte) (int, error) {
 	return tw.ResponseWriter.Write(p)
 }
 
-func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+// Timeout cuts requests off after timeout, except those to the streaming
+// paths, which stay open for as long as the client does.
+func Timeout(timeout time.Duration, streamingPaths ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if timeout <= 0 {
+			if timeout <= 0 || slices.Contains(streamingPaths, r.URL.Path) {
 				next.ServeHTTP(w, r)
 				return
 			}
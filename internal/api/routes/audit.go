@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"net/http"
+
+	"learn/internal/api/handlers"
+)
+
+func RegisterAuditRoutes(mux *http.ServeMux, handler *handlers.AuditHandler, auth, admin func(http.Handler) http.Handler) {
+	mux.Handle("GET /profile/audit", auth(http.HandlerFunc(handler.GetProfileAudit)))
+	mux.Handle("GET /admin/audit", auth(admin(http.HandlerFunc(handler.ListAudit))))
+}
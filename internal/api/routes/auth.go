@@ -6,7 +6,8 @@ import (
 	"learn/internal/api/handlers"
 )
 
-func RegisterAuthRoutes(mux *http.ServeMux, handler *handlers.AuthHandler) {
+func RegisterAuthRoutes(mux *http.ServeMux, handler *handlers.AuthHandler, auth func(http.Handler) http.Handler) {
 	mux.HandleFunc("POST /auth/signup", handler.Signup)
 	mux.HandleFunc("POST /auth/login", handler.Login)
+	mux.Handle("POST /profile/password", auth(http.HandlerFunc(handler.ChangePassword)))
 }
@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"net/http"
+
+	"learn/internal/api/handlers"
+)
+
+func RegisterAvatarRoutes(mux *http.ServeMux, handler *handlers.AvatarHandler, auth func(http.Handler) http.Handler) {
+	mux.Handle("PUT /profile/avatar", auth(http.HandlerFunc(handler.UploadAvatar)))
+	mux.HandleFunc("GET /avatars/{id}", handler.GetAvatar)
+}
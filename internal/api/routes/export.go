@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"net/http"
+
+	"learn/internal/api/handlers"
+)
+
+func RegisterExportRoutes(mux *http.ServeMux, handler *handlers.ExportHandler, auth func(http.Handler) http.Handler) {
+	mux.Handle("POST /profile/export", auth(http.HandlerFunc(handler.RequestExport)))
+	mux.Handle("GET /profile/export/{id}", auth(http.HandlerFunc(handler.GetExport)))
+	mux.HandleFunc("GET /exports/{token}", handler.DownloadExport)
+}
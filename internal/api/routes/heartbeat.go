@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"net/http"
+
+	"learn/internal/api/handlers"
+)
+
+func RegisterHeartbeatRoutes(mux *http.ServeMux, handler *handlers.HeartbeatHandler) {
+	mux.HandleFunc("POST /ping/{token}", handler.Ping)
+	mux.HandleFunc("POST /ping/{token}/start", handler.PingStart)
+	mux.HandleFunc("POST /ping/{token}/fail", handler.PingFail)
+}
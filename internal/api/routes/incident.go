@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"net/http"
+
+	"learn/internal/api/handlers"
+)
+
+func RegisterIncidentRoutes(mux *http.ServeMux, handler *handlers.IncidentHandler, auth func(http.Handler) http.Handler) {
+	mux.Handle("GET /incidents", auth(http.HandlerFunc(handler.ListIncidents)))
+	mux.Handle("POST /incidents/{id}/ack", auth(http.HandlerFunc(handler.AcknowledgeIncident)))
+	mux.Handle("GET /incidents/{id}/deliveries", auth(http.HandlerFunc(handler.ListIncidentDeliveries)))
+	mux.Handle("GET /monitors/{id}/incidents", auth(http.HandlerFunc(handler.ListMonitorIncidents)))
+}
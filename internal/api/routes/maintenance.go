@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"net/http"
+
+	"learn/internal/api/handlers"
+)
+
+func RegisterMaintenanceRoutes(mux *http.ServeMux, handler *handlers.MaintenanceHandler, auth func(http.Handler) http.Handler) {
+	mux.Handle("GET /maintenance-windows", auth(http.HandlerFunc(handler.GetWindows)))
+	mux.Handle("POST /maintenance-windows", auth(http.HandlerFunc(handler.CreateWindow)))
+	mux.Handle("GET /maintenance-windows/{id}", auth(http.HandlerFunc(handler.GetWindow)))
+	mux.Handle("DELETE /maintenance-windows/{id}", auth(http.HandlerFunc(handler.DeleteWindow)))
+}
@@ -9,8 +9,14 @@ import (
 func RegisterMonitorRoutes(mux *http.ServeMux, handler *handlers.MonitorHandler, auth func(http.Handler) http.Handler) {
 	mux.Handle("GET /monitors", auth(http.HandlerFunc(handler.GetMonitors)))
 	mux.Handle("POST /monitors", auth(http.HandlerFunc(handler.CreateMonitor)))
+	mux.Handle("POST /monitors/test", auth(http.HandlerFunc(handler.TestMonitor)))
+	mux.Handle("GET /monitors/events", auth(http.HandlerFunc(handler.StreamMonitorEvents)))
 	mux.Handle("GET /monitors/{id}", auth(http.HandlerFunc(handler.GetMonitor)))
+	mux.Handle("GET /monitors/{id}/history", auth(http.HandlerFunc(handler.GetMonitorHistory)))
+	mux.Handle("GET /monitors/{id}/uptime", auth(http.HandlerFunc(handler.GetMonitorUptime)))
+	mux.Handle("PATCH /monitors/{id}", auth(http.HandlerFunc(handler.UpdateMonitor)))
 	mux.Handle("DELETE /monitors/{id}", auth(http.HandlerFunc(handler.DeleteMonitor)))
 	mux.Handle("PATCH /monitors/{id}/toggle", auth(http.HandlerFunc(handler.ToggleMonitor)))
+	mux.Handle("POST /monitors/{id}/check", auth(http.HandlerFunc(handler.CheckMonitor)))
 	mux.Handle("GET /dashboard", auth(http.HandlerFunc(handler.GetDashboard)))
 }
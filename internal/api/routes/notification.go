@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"net/http"
+
+	"learn/internal/api/handlers"
+)
+
+func RegisterNotificationRoutes(mux *http.ServeMux, handler *handlers.NotificationHandler, auth func(http.Handler) http.Handler) {
+	mux.Handle("GET /notifications/channels", auth(http.HandlerFunc(handler.GetChannels)))
+	mux.Handle("POST /notifications/channels", auth(http.HandlerFunc(handler.CreateChannel)))
+	mux.Handle("DELETE /notifications/channels/{id}", auth(http.HandlerFunc(handler.DeleteChannel)))
+	mux.Handle("POST /notifications/channels/{id}/test", auth(http.HandlerFunc(handler.TestChannel)))
+	mux.Handle("GET /notifications/channels/{id}/deliveries", auth(http.HandlerFunc(handler.GetDeliveries)))
+	mux.Handle("GET /monitors/{id}/channels", auth(http.HandlerFunc(handler.GetMonitorChannels)))
+	mux.Handle("PUT /monitors/{id}/channels", auth(http.HandlerFunc(handler.SetMonitorChannels)))
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"net/http"
+
+	"learn/internal/api/handlers"
+)
+
+func RegisterOnCallRoutes(mux *http.ServeMux, handler *handlers.OnCallHandler, auth func(http.Handler) http.Handler) {
+	mux.Handle("GET /oncall/schedules", auth(http.HandlerFunc(handler.GetSchedules)))
+	mux.Handle("POST /oncall/schedules", auth(http.HandlerFunc(handler.CreateSchedule)))
+	mux.Handle("GET /oncall/schedules/{id}", auth(http.HandlerFunc(handler.GetSchedule)))
+	mux.Handle("DELETE /oncall/schedules/{id}", auth(http.HandlerFunc(handler.DeleteSchedule)))
+	mux.Handle("POST /oncall/schedules/{id}/overrides", auth(http.HandlerFunc(handler.CreateOverride)))
+	mux.Handle("DELETE /oncall/schedules/{id}/overrides/{overrideID}", auth(http.HandlerFunc(handler.DeleteOverride)))
+	mux.Handle("GET /escalation-policies", auth(http.HandlerFunc(handler.GetPolicies)))
+	mux.Handle("POST /escalation-policies", auth(http.HandlerFunc(handler.CreatePolicy)))
+	mux.Handle("GET /escalation-policies/{id}", auth(http.HandlerFunc(handler.GetPolicy)))
+	mux.Handle("DELETE /escalation-policies/{id}", auth(http.HandlerFunc(handler.DeletePolicy)))
+	mux.Handle("PUT /monitors/{id}/escalation-policy", auth(http.HandlerFunc(handler.SetMonitorPolicy)))
+}
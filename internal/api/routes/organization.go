@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"net/http"
+
+	"learn/internal/api/handlers"
+)
+
+func RegisterOrganizationRoutes(mux *http.ServeMux, handler *handlers.OrganizationHandler, auth func(http.Handler) http.Handler) {
+	mux.Handle("GET /organizations", auth(http.HandlerFunc(handler.GetOrganizations)))
+	mux.Handle("POST /organizations", auth(http.HandlerFunc(handler.CreateOrganization)))
+	mux.Handle("GET /organizations/{id}", auth(http.HandlerFunc(handler.GetOrganization)))
+	mux.Handle("PATCH /organizations/{id}/members/{userID}", auth(http.HandlerFunc(handler.UpdateMember)))
+	mux.Handle("DELETE /organizations/{id}/members/{userID}", auth(http.HandlerFunc(handler.RemoveMember)))
+	mux.Handle("GET /organizations/{id}/invites", auth(http.HandlerFunc(handler.GetInvites)))
+	mux.Handle("POST /organizations/{id}/invites", auth(http.HandlerFunc(handler.CreateInvite)))
+	mux.Handle("DELETE /organizations/{id}/invites/{inviteID}", auth(http.HandlerFunc(handler.RevokeInvite)))
+	mux.Handle("POST /invites/{token}/accept", auth(http.HandlerFunc(handler.AcceptInvite)))
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"net/http"
+
+	"learn/internal/api/handlers"
+)
+
+func RegisterProbeRoutes(mux *http.ServeMux, handler *handlers.ProbeHandler, auth, admin func(http.Handler) http.Handler) {
+	mux.Handle("POST /admin/probes", auth(admin(http.HandlerFunc(handler.CreateProbe))))
+	mux.Handle("GET /admin/probes", auth(admin(http.HandlerFunc(handler.ListProbes))))
+	mux.Handle("DELETE /admin/probes/{id}", auth(admin(http.HandlerFunc(handler.DeleteProbe))))
+	mux.Handle("GET /locations", auth(http.HandlerFunc(handler.GetLocations)))
+	mux.HandleFunc("GET /probes/checks", handler.GetProbeChecks)
+	mux.HandleFunc("POST /probes/results", handler.ReportProbeResults)
+}
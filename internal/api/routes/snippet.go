@@ -6,8 +6,8 @@ import (
 	"learn/internal/api/handlers"
 )
 
-func RegisterSnippetRoutes(mux *http.ServeMux, handler *handlers.SnippetHandler) {
-	mux.HandleFunc("POST /snippets", handler.CreateSnippet)
+func RegisterSnippetRoutes(mux *http.ServeMux, handler *handlers.SnippetHandler, optionalAuth func(http.Handler) http.Handler) {
+	mux.Handle("POST /snippets", optionalAuth(http.HandlerFunc(handler.CreateSnippet)))
 	mux.HandleFunc("GET /s/{hash}", handler.GetSnippet)
 	mux.HandleFunc("POST /s/{hash}", handler.GetSnippet)
 }
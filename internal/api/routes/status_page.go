@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"net/http"
+
+	"learn/internal/api/handlers"
+)
+
+func RegisterStatusPageRoutes(mux *http.ServeMux, handler *handlers.StatusPageHandler, auth func(http.Handler) http.Handler) {
+	mux.Handle("GET /status-pages", auth(http.HandlerFunc(handler.GetStatusPages)))
+	mux.Handle("POST /status-pages", auth(http.HandlerFunc(handler.CreateStatusPage)))
+	mux.Handle("GET /status-pages/{id}", auth(http.HandlerFunc(handler.GetStatusPage)))
+	mux.Handle("PUT /status-pages/{id}", auth(http.HandlerFunc(handler.UpdateStatusPage)))
+	mux.Handle("DELETE /status-pages/{id}", auth(http.HandlerFunc(handler.DeleteStatusPage)))
+	mux.HandleFunc("GET /status/{slug}", handler.GetPublicStatusPage)
+}
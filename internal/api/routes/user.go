@@ -10,4 +10,5 @@ func RegisterUserRoutes(mux *http.ServeMux, handler *handlers.UserHandler, auth
 	mux.HandleFunc("GET /users", handler.GetUsers)
 	mux.HandleFunc("GET /users/{id}", handler.GetUser)
 	mux.Handle("GET /profile", auth(http.HandlerFunc(handler.GetProfile)))
+	mux.Handle("PATCH /profile", auth(http.HandlerFunc(handler.UpdateProfile)))
 }
@@ -5,12 +5,23 @@ import (
 	"strings"
 
 	"github.com/go-playground/validator/v10"
+	"learn/internal/models"
 )
 
 var validate *validator.Validate
 
 func init() {
 	validate = validator.New()
+	validate.RegisterValidation("httpheader", func(fl validator.FieldLevel) bool {
+		return isHeaderName(fl.Field().String())
+	})
+	validate.RegisterValidation("statusranges", func(fl validator.FieldLevel) bool {
+		_, err := models.ParseStatusRanges(fl.Field().String())
+		return err == nil
+	})
+	validate.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+		return isSlug(fl.Field().String())
+	})
 }
 
 func Validate(s any) error {
@@ -38,7 +49,7 @@ func formatError(e validator.FieldError) string {
 	field := strings.ToLower(e.Field())
 
 	switch e.Tag() {
-	case "required":
+	case "required", "required_unless", "required_if":
 		return fmt.Sprintf("%s is required", field)
 	case "email":
 		return fmt.Sprintf("%s must be a valid email address", field)
@@ -50,7 +61,45 @@ func formatError(e validator.FieldError) string {
 		return fmt.Sprintf("%s must contain only letters and numbers", field)
 	case "url":
 		return fmt.Sprintf("%s must be a valid URL", field)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, e.Param())
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, e.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, e.Param())
+	case "httpheader":
+		return fmt.Sprintf("%s must be a valid HTTP header name", field)
+	case "statusranges":
+		return fmt.Sprintf("%s must be a list of status codes or ranges such as 200-299,301", field)
+	case "slug":
+		return fmt.Sprintf("%s must contain only lowercase letters, numbers and single hyphens", field)
 	default:
 		return fmt.Sprintf("%s is invalid", field)
 	}
 }
+
+func isHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if c > 0x7e || !(c == '!' || c == '#' || c == '$' || c == '%' || c == '&' || c == '\'' || c == '*' ||
+			c == '+' || c == '-' || c == '.' || c == '^' || c == '_' || c == '`' || c == '|' || c == '~' ||
+			('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')) {
+			return false
+		}
+	}
+	return true
+}
+
+func isSlug(slug string) bool {
+	if slug == "" || slug[0] == '-' || slug[len(slug)-1] == '-' || strings.Contains(slug, "--") {
+		return false
+	}
+	for _, c := range slug {
+		if !(c == '-' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z')) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,21 +1,54 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"log"
+	"net/netip"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
+const (
+	RunModeAll    = "all"
+	RunModeAPI    = "api"
+	RunModeWorker = "worker"
+	RunModeAgent  = "agent"
+)
+
 type Config struct {
-	Port           string
-	DBPath         string
-	JWTSecret      string
-	JWTExpiry      time.Duration
-	RequestTimeout time.Duration
-	AllowedOrigins []string
+	RunMode                string
+	WorkerID               string
+	ProbeServerURL         string
+	ProbeToken             string
+	Port                   string
+	DBPath                 string
+	JWTSecret              string
+	EncryptionKey          string
+	JWTExpiry              time.Duration
+	RequestTimeout         time.Duration
+	AllowedOrigins         []string
+	ExportLinkTTL          time.Duration
+	AdminUserIDs           []string
+	UploadDir              string
+	AvatarMaxBytes         int64
+	InviteTTL              time.Duration
+	PublicURL              string
+	SMTPHost               string
+	SMTPPort               string
+	SMTPUsername           string
+	SMTPPassword           string
+	SMTPFrom               string
+	NotificationRetryDelay time.Duration
+	CompactionInterval     time.Duration
+	LogRetention           time.Duration
+	HourlyRollupRetention  time.Duration
+	EgressAllowlist        []netip.Prefix
 }
 
 func Load() (Config, error) {
@@ -29,13 +62,63 @@ func Load() (Config, error) {
 		parsedExpiry = 24 * time.Hour
 	}
 
+	jwtSecret := getEnv("JWT_SECRET", "your-secret-key-change-in-production")
+
+	runMode := getEnv("RUN_MODE", RunModeAll)
+	if runMode != RunModeAll && runMode != RunModeAPI && runMode != RunModeWorker && runMode != RunModeAgent {
+		return Config{}, fmt.Errorf("RUN_MODE must be %s, %s, %s or %s, got %q", RunModeAll, RunModeAPI, RunModeWorker, RunModeAgent, runMode)
+	}
+
+	// Secrets sealed with one key can't be read with another, so the key must
+	// not change along with JWT_SECRET.
+	encryptionKey := getEnv("ENCRYPTION_KEY", "")
+	if runMode != RunModeAgent && encryptionKey == "" {
+		return Config{}, fmt.Errorf("ENCRYPTION_KEY is required; set it to the current JWT_SECRET to keep reading secrets saved by earlier versions")
+	}
+
+	if getEnv("ADMIN_EMAILS", "") != "" {
+		return Config{}, fmt.Errorf("ADMIN_EMAILS is no longer supported; list the IDs of admin users in ADMIN_USER_IDS")
+	}
+
+	probeServerURL := getEnv("PROBE_SERVER_URL", "")
+	probeToken := getEnv("PROBE_TOKEN", "")
+	if runMode == RunModeAgent && (probeServerURL == "" || probeToken == "") {
+		return Config{}, fmt.Errorf("RUN_MODE=%s needs PROBE_SERVER_URL and PROBE_TOKEN", RunModeAgent)
+	}
+
+	egressAllowlist, err := parsePrefixes(getEnv("EGRESS_ALLOWLIST", ""))
+	if err != nil {
+		return Config{}, fmt.Errorf("EGRESS_ALLOWLIST: %w", err)
+	}
+
 	return Config{
-		Port:           getEnv("PORT", "8000"),
-		DBPath:         getEnv("DB_PATH", "./app.db"),
-		JWTSecret:      getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
-		JWTExpiry:      parsedExpiry,
-		RequestTimeout: getDuration("REQUEST_TIMEOUT", 10*time.Second),
-		AllowedOrigins: parseCSV(getEnv("ALLOWED_ORIGINS", "*")),
+		RunMode:                runMode,
+		WorkerID:               getEnv("WORKER_ID", defaultWorkerID()),
+		ProbeServerURL:         probeServerURL,
+		ProbeToken:             probeToken,
+		Port:                   getEnv("PORT", "8000"),
+		DBPath:                 getEnv("DB_PATH", "./app.db"),
+		JWTSecret:              jwtSecret,
+		EncryptionKey:          encryptionKey,
+		JWTExpiry:              parsedExpiry,
+		RequestTimeout:         getDuration("REQUEST_TIMEOUT", 10*time.Second),
+		AllowedOrigins:         parseCSV(getEnv("ALLOWED_ORIGINS", "*")),
+		ExportLinkTTL:          getDuration("EXPORT_LINK_TTL", 24*time.Hour),
+		AdminUserIDs:           parseList(getEnv("ADMIN_USER_IDS", "")),
+		UploadDir:              getEnv("UPLOAD_DIR", "./uploads"),
+		AvatarMaxBytes:         getInt64("AVATAR_MAX_BYTES", 5<<20),
+		InviteTTL:              getDuration("INVITE_TTL", 7*24*time.Hour),
+		PublicURL:              getEnv("PUBLIC_URL", "http://localhost:8000"),
+		SMTPHost:               getEnv("SMTP_HOST", ""),
+		SMTPPort:               getEnv("SMTP_PORT", "587"),
+		SMTPUsername:           getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:           getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:               getEnv("SMTP_FROM", "alerts@localhost"),
+		NotificationRetryDelay: getDuration("NOTIFICATION_RETRY_DELAY", 30*time.Second),
+		CompactionInterval:     getDuration("COMPACTION_INTERVAL", time.Hour),
+		LogRetention:           getDuration("LOG_RETENTION", 30*24*time.Hour),
+		HourlyRollupRetention:  getDuration("HOURLY_ROLLUP_RETENTION", 365*24*time.Hour),
+		EgressAllowlist:        egressAllowlist,
 	}, nil
 }
 
@@ -46,6 +129,18 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// defaultWorkerID names this process in leases. The random suffix keeps it
+// unique across restarts and between processes on one host.
+func defaultWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "worker"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return hostname + "-" + hex.EncodeToString(suffix)
+}
+
 func getDuration(key string, fallback time.Duration) time.Duration {
 	raw := os.Getenv(key)
 	if raw == "" {
@@ -58,7 +153,19 @@ func getDuration(key string, fallback time.Duration) time.Duration {
 	return parsed
 }
 
-func parseCSV(value string) []string {
+func getInt64(key string, fallback int64) int64 {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || parsed <= 0 {
+		return fallback
+	}
+	return parsed
+}
+
+func parseList(value string) []string {
 	parts := strings.Split(value, ",")
 	out := make([]string, 0, len(parts))
 	for _, part := range parts {
@@ -67,6 +174,29 @@ func parseCSV(value string) []string {
 			out = append(out, trimmed)
 		}
 	}
+	return out
+}
+
+// parsePrefixes reads a list of CIDR ranges, where a bare address stands for
+// just that address.
+func parsePrefixes(value string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range parseList(value) {
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address or CIDR range %q", entry)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func parseCSV(value string) []string {
+	out := parseList(value)
 	if len(out) == 0 {
 		return []string{"*"}
 	}
@@ -3,12 +3,22 @@ package config
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 
 	_ "modernc.org/sqlite"
 )
 
+// OpenDB makes every pooled connection wait for locks held by concurrent
+// writers instead of failing straight away with SQLITE_BUSY.
 func OpenDB(dbPath string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", dbPath)
+	separator := "?"
+	if strings.Contains(dbPath, "?") {
+		separator = "&"
+	}
+
+	db, err := sql.Open("sqlite", dbPath+separator+"_pragma=busy_timeout(5000)")
 	if err != nil {
 		return nil, err
 	}
@@ -26,7 +36,7 @@ func Migrate(ctx context.Context, db *sql.DB) error {
 		`CREATE TABLE IF NOT EXISTS users (
 			id TEXT PRIMARY KEY,
 			username TEXT NOT NULL UNIQUE,
-			email TEXT NOT NULL UNIQUE,
+			email TEXT NOT NULL UNIQUE COLLATE NOCASE,
 			password TEXT NOT NULL,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		);`,
@@ -68,6 +78,244 @@ func Migrate(ctx context.Context, db *sql.DB) error {
 			expires_at DATETIME,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		);`,
+		`CREATE TABLE IF NOT EXISTS data_exports (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			status TEXT NOT NULL,
+			token TEXT UNIQUE,
+			archive BLOB,
+			error_message TEXT,
+			completed_at DATETIME,
+			expires_at DATETIME,
+			downloaded_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);`,
+		`CREATE TABLE IF NOT EXISTS audit_logs (
+			id TEXT PRIMARY KEY,
+			actor_id TEXT,
+			action TEXT NOT NULL,
+			resource_type TEXT,
+			resource_id TEXT,
+			ip_address TEXT,
+			user_agent TEXT,
+			before_value TEXT,
+			after_value TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS organizations (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			created_by TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		);`,
+		`CREATE TABLE IF NOT EXISTS organization_members (
+			organization_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			role TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (organization_id, user_id),
+			FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_organization_members_user ON organization_members (user_id);`,
+		`CREATE TABLE IF NOT EXISTS organization_invites (
+			id TEXT PRIMARY KEY,
+			organization_id TEXT NOT NULL,
+			email TEXT NOT NULL,
+			role TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			invited_by TEXT NOT NULL,
+			expires_at DATETIME NOT NULL,
+			accepted_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE
+		);`,
+		`CREATE TABLE IF NOT EXISTS incidents (
+			id TEXT PRIMARY KEY,
+			monitor_id TEXT NOT NULL,
+			status TEXT NOT NULL,
+			started_at DATETIME NOT NULL,
+			resolved_at DATETIME,
+			first_error TEXT NOT NULL DEFAULT '',
+			check_count INTEGER NOT NULL DEFAULT 1,
+			notes TEXT NOT NULL DEFAULT '',
+			acknowledged_by TEXT REFERENCES users(id),
+			acknowledged_at DATETIME,
+			FOREIGN KEY (monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_incidents_monitor_started ON incidents (monitor_id, started_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_incidents_status ON incidents (status);`,
+		`CREATE TABLE IF NOT EXISTS notification_channels (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			type TEXT NOT NULL,
+			config TEXT NOT NULL,
+			template TEXT NOT NULL DEFAULT '',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_notification_channels_user ON notification_channels (user_id);`,
+		`CREATE TABLE IF NOT EXISTS monitor_notification_channels (
+			monitor_id TEXT NOT NULL,
+			channel_id TEXT NOT NULL,
+			PRIMARY KEY (monitor_id, channel_id),
+			FOREIGN KEY (monitor_id) REFERENCES monitors(id) ON DELETE CASCADE,
+			FOREIGN KEY (channel_id) REFERENCES notification_channels(id) ON DELETE CASCADE
+		);`,
+		`CREATE TABLE IF NOT EXISTS notification_deliveries (
+			id TEXT PRIMARY KEY,
+			channel_id TEXT NOT NULL,
+			monitor_id TEXT,
+			event TEXT NOT NULL,
+			subject TEXT NOT NULL,
+			message TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			response_code INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			next_attempt_at DATETIME,
+			delivered_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (channel_id) REFERENCES notification_channels(id) ON DELETE CASCADE
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_notification_deliveries_due ON notification_deliveries (status, next_attempt_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_notification_deliveries_channel ON notification_deliveries (channel_id, created_at);`,
+		`CREATE TABLE IF NOT EXISTS oncall_schedules (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			organization_id TEXT REFERENCES organizations(id),
+			name TEXT NOT NULL,
+			rotation_start DATETIME NOT NULL,
+			rotation_days INTEGER NOT NULL DEFAULT 7,
+			participants TEXT NOT NULL DEFAULT '[]',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id)
+		);`,
+		`CREATE TABLE IF NOT EXISTS oncall_overrides (
+			id TEXT PRIMARY KEY,
+			schedule_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			starts_at DATETIME NOT NULL,
+			ends_at DATETIME NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (schedule_id) REFERENCES oncall_schedules(id) ON DELETE CASCADE
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_oncall_overrides_schedule ON oncall_overrides (schedule_id, ends_at);`,
+		`CREATE TABLE IF NOT EXISTS escalation_policies (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			organization_id TEXT REFERENCES organizations(id),
+			name TEXT NOT NULL,
+			steps TEXT NOT NULL DEFAULT '[]',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id)
+		);`,
+		`CREATE TABLE IF NOT EXISTS maintenance_windows (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			organization_id TEXT REFERENCES organizations(id),
+			name TEXT NOT NULL,
+			starts_at DATETIME NOT NULL,
+			ends_at DATETIME NOT NULL,
+			recurrence TEXT NOT NULL DEFAULT '',
+			timezone TEXT NOT NULL DEFAULT 'UTC',
+			mode TEXT NOT NULL DEFAULT 'pause',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id)
+		);`,
+		`CREATE TABLE IF NOT EXISTS maintenance_window_monitors (
+			window_id TEXT NOT NULL,
+			monitor_id TEXT NOT NULL,
+			PRIMARY KEY (window_id, monitor_id),
+			FOREIGN KEY (window_id) REFERENCES maintenance_windows(id) ON DELETE CASCADE,
+			FOREIGN KEY (monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_maintenance_window_monitors_monitor ON maintenance_window_monitors (monitor_id);`,
+		`CREATE TABLE IF NOT EXISTS monitor_pauses (
+			id INTEGER PRIMARY KEY,
+			monitor_id TEXT NOT NULL,
+			started_at DATETIME NOT NULL,
+			ended_at DATETIME,
+			FOREIGN KEY (monitor_id) REFERENCES monitors(id) ON DELETE CASCADE
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_monitor_pauses_monitor ON monitor_pauses (monitor_id, started_at);`,
+		`CREATE TABLE IF NOT EXISTS monitor_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			type TEXT NOT NULL,
+			monitor_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			organization_id TEXT,
+			data TEXT NOT NULL,
+			created_at DATETIME NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_monitor_events_created ON monitor_events (created_at);`,
+		`CREATE TABLE IF NOT EXISTS monitor_rollups_hourly (
+			monitor_id TEXT NOT NULL,
+			bucket_start DATETIME NOT NULL,
+			checks INTEGER NOT NULL,
+			up_checks INTEGER NOT NULL,
+			min_response_ms INTEGER NOT NULL,
+			avg_response_ms INTEGER NOT NULL,
+			max_response_ms INTEGER NOT NULL,
+			p95_response_ms INTEGER NOT NULL,
+			first_checked_at DATETIME NOT NULL,
+			PRIMARY KEY (monitor_id, bucket_start)
+		);`,
+		`CREATE TABLE IF NOT EXISTS monitor_rollups_daily (
+			monitor_id TEXT NOT NULL,
+			bucket_start DATETIME NOT NULL,
+			checks INTEGER NOT NULL,
+			up_checks INTEGER NOT NULL,
+			min_response_ms INTEGER NOT NULL,
+			avg_response_ms INTEGER NOT NULL,
+			max_response_ms INTEGER NOT NULL,
+			p95_response_ms INTEGER NOT NULL,
+			first_checked_at DATETIME NOT NULL,
+			PRIMARY KEY (monitor_id, bucket_start)
+		);`,
+		`CREATE TABLE IF NOT EXISTS rollup_watermarks (
+			granularity TEXT PRIMARY KEY,
+			rolled_until DATETIME NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS probes (
+			id TEXT PRIMARY KEY,
+			location TEXT NOT NULL UNIQUE,
+			token_hash TEXT NOT NULL UNIQUE,
+			last_seen_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);`,
+		`CREATE TABLE IF NOT EXISTS job_leases (
+			name TEXT PRIMARY KEY,
+			worker_id TEXT NOT NULL,
+			leased_until DATETIME NOT NULL
+		);`,
+		`CREATE TABLE IF NOT EXISTS status_pages (
+			id TEXT PRIMARY KEY,
+			user_id TEXT NOT NULL,
+			organization_id TEXT REFERENCES organizations(id),
+			slug TEXT NOT NULL UNIQUE,
+			title TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			custom_css TEXT NOT NULL DEFAULT '',
+			sections TEXT NOT NULL DEFAULT '[]',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id)
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_created ON audit_logs (actor_id, created_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_logs_created ON audit_logs (created_at);`,
+		`CREATE TRIGGER IF NOT EXISTS audit_logs_no_update BEFORE UPDATE ON audit_logs
+		BEGIN
+			SELECT RAISE(ABORT, 'audit_logs is append-only');
+		END;`,
+		`CREATE TRIGGER IF NOT EXISTS audit_logs_no_delete BEFORE DELETE ON audit_logs
+		BEGIN
+			SELECT RAISE(ABORT, 'audit_logs is append-only');
+		END;`,
 	}
 
 	for _, table := range tables {
@@ -76,5 +324,157 @@ func Migrate(ctx context.Context, db *sql.DB) error {
 		}
 	}
 
+	columns := []struct {
+		table      string
+		name       string
+		definition string
+	}{
+		{"snippets", "user_id", "TEXT REFERENCES users(id)"},
+		{"users", "display_name", "TEXT NOT NULL DEFAULT ''"},
+		{"users", "bio", "TEXT NOT NULL DEFAULT ''"},
+		{"users", "avatar_url", "TEXT NOT NULL DEFAULT ''"},
+		{"monitors", "organization_id", "TEXT REFERENCES organizations(id)"},
+		{"monitors", "method", "TEXT NOT NULL DEFAULT 'GET'"},
+		{"monitors", "headers", "TEXT NOT NULL DEFAULT '[]'"},
+		{"monitors", "body", "TEXT NOT NULL DEFAULT ''"},
+		{"monitors", "accepted_statuses", "TEXT NOT NULL DEFAULT '200-399'"},
+		{"monitors", "follow_redirects", "INTEGER NOT NULL DEFAULT 1"},
+		{"monitors", "assertions", "TEXT NOT NULL DEFAULT '[]'"},
+		{"monitors", "max_response_bytes", "INTEGER NOT NULL DEFAULT 1048576"},
+		{"monitors", "type", "TEXT NOT NULL DEFAULT 'http'"},
+		{"monitors", "type_config", "TEXT NOT NULL DEFAULT '{}'"},
+		{"monitor_logs", "details", "TEXT"},
+		{"monitors", "ping_token", "TEXT"},
+		{"monitors", "grace_seconds", "INTEGER NOT NULL DEFAULT 300"},
+		{"monitors", "last_ping_at", "DATETIME"},
+		{"monitors", "ping_started_at", "DATETIME"},
+		{"monitor_logs", "dns_ms", "INTEGER"},
+		{"monitor_logs", "connect_ms", "INTEGER"},
+		{"monitor_logs", "tls_ms", "INTEGER"},
+		{"monitor_logs", "ttfb_ms", "INTEGER"},
+		{"monitor_logs", "transfer_ms", "INTEGER"},
+		{"monitors", "retries", "INTEGER NOT NULL DEFAULT 0"},
+		{"monitors", "retry_delay_ms", "INTEGER NOT NULL DEFAULT 1000"},
+		{"monitors", "failure_threshold", "INTEGER NOT NULL DEFAULT 1"},
+		{"monitors", "recovery_threshold", "INTEGER NOT NULL DEFAULT 1"},
+		{"monitors", "sla_target", "REAL NOT NULL DEFAULT 99.9"},
+		{"monitors", "status", "TEXT NOT NULL DEFAULT 'pending'"},
+		{"monitors", "consecutive_failures", "INTEGER NOT NULL DEFAULT 0"},
+		{"monitors", "consecutive_successes", "INTEGER NOT NULL DEFAULT 0"},
+		{"monitors", "status_changed_at", "DATETIME"},
+		{"monitors", "state_counted_at", "DATETIME"},
+		{"monitors", "next_check_at", "DATETIME"},
+		{"monitors", "leased_by", "TEXT"},
+		{"monitors", "leased_until", "DATETIME"},
+		{"monitors", "locations", "TEXT NOT NULL DEFAULT '[\"local\"]'"},
+		{"monitors", "quorum", "INTEGER NOT NULL DEFAULT 1"},
+		{"monitor_logs", "location", "TEXT NOT NULL DEFAULT 'local'"},
+		{"monitor_logs", "manual", "INTEGER NOT NULL DEFAULT 0"},
+		{"notification_deliveries", "recipient", "TEXT NOT NULL DEFAULT ''"},
+		{"notification_deliveries", "incident_id", "TEXT"},
+		{"monitors", "escalation_policy_id", "TEXT"},
+		{"incidents", "escalation_policy_id", "TEXT"},
+		{"incidents", "escalation_step", "INTEGER NOT NULL DEFAULT 0"},
+		{"incidents", "next_escalation_at", "DATETIME"},
+	}
+
+	for _, column := range columns {
+		if err := addColumnIfMissing(ctx, db, column.table, column.name, column.definition); err != nil {
+			return err
+		}
+	}
+
+	indexes := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_monitors_ping_token ON monitors (ping_token);`,
+		`CREATE INDEX IF NOT EXISTS idx_monitor_logs_monitor_checked ON monitor_logs (monitor_id, checked_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_monitor_logs_checked ON monitor_logs (checked_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_incidents_next_escalation ON incidents (next_escalation_at);`,
+		// Older versions could open a second incident for a monitor. Keep the
+		// latest one open so only one can be open from now on.
+		`UPDATE incidents SET status = 'resolved',
+			resolved_at = (SELECT MAX(newer.started_at) FROM incidents newer WHERE newer.monitor_id = incidents.monitor_id AND newer.status = 'open')
+		WHERE status = 'open' AND EXISTS (
+			SELECT 1 FROM incidents newer
+			WHERE newer.monitor_id = incidents.monitor_id AND newer.status = 'open'
+				AND (newer.started_at > incidents.started_at OR (newer.started_at = incidents.started_at AND newer.id > incidents.id))
+		);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_incidents_open_monitor ON incidents (monitor_id) WHERE status = 'open';`,
+		// Monitors paused before pauses were recorded count as paused since
+		// they were last toggled.
+		`INSERT INTO monitor_pauses (monitor_id, started_at)
+		SELECT m.id, COALESCE((
+			SELECT MAX(a.created_at) FROM audit_logs a
+			WHERE a.action = 'monitor.toggled' AND a.resource_type = 'monitor' AND a.resource_id = m.id
+		), CURRENT_TIMESTAMP)
+		FROM monitors m
+		WHERE m.is_active = 0 AND NOT EXISTS (SELECT 1 FROM monitor_pauses p WHERE p.monitor_id = m.id AND p.ended_at IS NULL);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_monitor_pauses_open ON monitor_pauses (monitor_id) WHERE ended_at IS NULL;`,
+		// avatar_url used to be writable; only uploaded avatars are kept.
+		`UPDATE users SET avatar_url = '' WHERE avatar_url <> '' AND avatar_url NOT LIKE '/avatars/%';`,
+		// Deleting a monitor used to leave these behind. Orphaned logs and
+		// rollups age out with the retention settings.
+		`DELETE FROM incidents WHERE NOT EXISTS (SELECT 1 FROM monitors m WHERE m.id = incidents.monitor_id);`,
+		`DELETE FROM monitor_notification_channels WHERE NOT EXISTS (SELECT 1 FROM monitors m WHERE m.id = monitor_notification_channels.monitor_id);`,
+		`DELETE FROM maintenance_window_monitors WHERE NOT EXISTS (SELECT 1 FROM monitors m WHERE m.id = maintenance_window_monitors.monitor_id);`,
+		`DELETE FROM monitor_pauses WHERE NOT EXISTS (SELECT 1 FROM monitors m WHERE m.id = monitor_pauses.monitor_id);`,
+		// Emails are stored lowercased and unique regardless of case;
+		// checkDuplicateEmails has made sure no two accounts collide.
+		`UPDATE users SET email = lower(trim(email))
+		WHERE email <> lower(trim(email));`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_nocase ON users (email COLLATE NOCASE);`,
+	}
+
+	if err := checkDuplicateEmails(ctx, db); err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		if _, err := db.ExecContext(ctx, index); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+// checkDuplicateEmails stops the migration with the accounts to sort out when
+// emails that differ only in case would break the unique email index.
+func checkDuplicateEmails(ctx context.Context, db *sql.DB) error {
+	var email, ids string
+	err := db.QueryRowContext(ctx, `
+SELECT lower(trim(email)), group_concat(id, ', ') FROM users
+GROUP BY lower(trim(email)) HAVING COUNT(*) > 1
+LIMIT 1
+`).Scan(&email, &ids)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("users %s share the email %s apart from case; merge or remove all but one of them", ids, email)
+}
+
+func addColumnIfMissing(ctx context.Context, db *sql.DB, table, column, definition string) error {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, "ALTER TABLE "+table+" ADD COLUMN "+column+" "+definition)
+	return err
+}
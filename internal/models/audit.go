@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditActionLoginSucceeded  = "auth.login_succeeded"
+	AuditActionLoginFailed     = "auth.login_failed"
+	AuditActionTokenIssued     = "auth.token_issued"
+	AuditActionPasswordChanged = "auth.password_changed"
+	AuditActionProfileUpdated  = "user.profile_updated"
+	AuditActionMonitorCreated  = "monitor.created"
+	AuditActionMonitorUpdated  = "monitor.updated"
+	AuditActionMonitorDeleted  = "monitor.deleted"
+	AuditActionMonitorToggled  = "monitor.toggled"
+	AuditActionSnippetCreated  = "snippet.created"
+
+	AuditActionOrganizationCreated = "organization.created"
+	AuditActionMemberRoleChanged   = "organization.member_role_changed"
+	AuditActionMemberRemoved       = "organization.member_removed"
+	AuditActionInviteCreated       = "organization.invite_created"
+	AuditActionInviteRevoked       = "organization.invite_revoked"
+	AuditActionInviteAccepted      = "organization.invite_accepted"
+
+	AuditActionIncidentAcknowledged = "incident.acknowledged"
+	AuditActionChannelCreated       = "notification_channel.created"
+	AuditActionChannelDeleted       = "notification_channel.deleted"
+
+	AuditActionScheduleCreated         = "oncall_schedule.created"
+	AuditActionScheduleDeleted         = "oncall_schedule.deleted"
+	AuditActionOverrideCreated         = "oncall_schedule.override_created"
+	AuditActionEscalationPolicyCreated = "escalation_policy.created"
+	AuditActionEscalationPolicyDeleted = "escalation_policy.deleted"
+	AuditActionMonitorPolicyChanged    = "monitor.escalation_policy_changed"
+	AuditActionMaintenanceCreated      = "maintenance_window.created"
+	AuditActionMaintenanceDeleted      = "maintenance_window.deleted"
+	AuditActionStatusPageCreated       = "status_page.created"
+	AuditActionStatusPageUpdated       = "status_page.updated"
+	AuditActionStatusPageDeleted       = "status_page.deleted"
+	AuditActionProbeCreated            = "probe.created"
+	AuditActionProbeDeleted            = "probe.deleted"
+)
+
+type AuditLog struct {
+	ID           string          `json:"id"`
+	ActorID      string          `json:"actor_id,omitempty"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type,omitempty"`
+	ResourceID   string          `json:"resource_id,omitempty"`
+	IPAddress    string          `json:"ip_address,omitempty"`
+	UserAgent    string          `json:"user_agent,omitempty"`
+	Before       json.RawMessage `json:"before,omitempty"`
+	After        json.RawMessage `json:"after,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+type AuditFilter struct {
+	ActorID string
+	Action  string
+	From    *time.Time
+	To      *time.Time
+	Limit   int
+	Offset  int
+}
+
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
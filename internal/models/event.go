@@ -0,0 +1,25 @@
+package models
+
+const (
+	MonitorEventCheck    = "check"
+	MonitorEventState    = "state"
+	MonitorEventIncident = "incident"
+)
+
+// MonitorEvent is a live update about a monitor. Data is a MonitorLog for
+// checks, a MonitorStateChange for state changes and an Incident for
+// incidents. The owner fields decide who receives it.
+type MonitorEvent struct {
+	ID             uint64
+	Type           string
+	MonitorID      string
+	UserID         string
+	OrganizationID string
+	Data           any
+}
+
+type MonitorStateChange struct {
+	MonitorID      string `json:"monitor_id"`
+	PreviousStatus string `json:"previous_status"`
+	MonitorState
+}
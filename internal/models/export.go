@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+const (
+	ExportStatusPending    = "pending"
+	ExportStatusReady      = "ready"
+	ExportStatusFailed     = "failed"
+	ExportStatusDownloaded = "downloaded"
+)
+
+type DataExport struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	Status       string     `json:"status"`
+	Token        string     `json:"-"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	DownloadedAt *time.Time `json:"downloaded_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	IncidentStatusOpen     = "open"
+	IncidentStatusResolved = "resolved"
+)
+
+// Incident covers the time a monitor spent down, from the check that
+// confirmed the outage to the one that confirmed recovery.
+type Incident struct {
+	ID                 string     `json:"id"`
+	MonitorID          string     `json:"monitor_id"`
+	MonitorName        string     `json:"monitor_name"`
+	Status             string     `json:"status"`
+	StartedAt          time.Time  `json:"started_at"`
+	ResolvedAt         *time.Time `json:"resolved_at,omitempty"`
+	DurationSeconds    int64      `json:"duration_seconds"`
+	FirstError         string     `json:"first_error"`
+	CheckCount         int        `json:"check_count"`
+	Notes              string     `json:"notes,omitempty"`
+	AcknowledgedBy     string     `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt     *time.Time `json:"acknowledged_at,omitempty"`
+	EscalationPolicyID string     `json:"escalation_policy_id,omitempty"`
+	EscalationStep     int        `json:"escalation_step"`
+	NextEscalationAt   *time.Time `json:"next_escalation_at,omitempty"`
+}
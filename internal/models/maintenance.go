@@ -0,0 +1,149 @@
+package models
+
+import (
+	"time"
+
+	"learn/pkg/recurrence"
+)
+
+const (
+	MaintenanceModePause  = "pause"
+	MaintenanceModeRecord = "record"
+)
+
+// MaintenanceWindow silences its monitors while it is active. Without a
+// Recurrence it runs from StartsAt to EndsAt. With one (a cron expression or
+// RRULE evaluated in Timezone) it recurs from StartsAt on, each occurrence
+// lasting as long as EndsAt - StartsAt.
+type MaintenanceWindow struct {
+	ID             string    `json:"id"`
+	UserID         string    `json:"user_id"`
+	OrganizationID string    `json:"organization_id,omitempty"`
+	Name           string    `json:"name"`
+	StartsAt       time.Time `json:"starts_at"`
+	EndsAt         time.Time `json:"ends_at"`
+	Recurrence     string    `json:"recurrence,omitempty"`
+	Timezone       string    `json:"timezone"`
+	Mode           string    `json:"mode"`
+	MonitorIDs     []string  `json:"monitor_ids"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (w MaintenanceWindow) schedule() (recurrence.Schedule, error) {
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	return recurrence.Parse(w.Recurrence, w.StartsAt, loc)
+}
+
+// Validate checks that the recurrence and timezone can be evaluated.
+func (w MaintenanceWindow) Validate() error {
+	if w.Recurrence == "" {
+		_, err := time.LoadLocation(w.Timezone)
+		return err
+	}
+	_, err := w.schedule()
+	return err
+}
+
+// OccurrenceAt returns the occurrence that covers t, if any.
+func (w MaintenanceWindow) OccurrenceAt(t time.Time) (start, end time.Time, ok bool) {
+	duration := w.EndsAt.Sub(w.StartsAt)
+	if w.Recurrence == "" {
+		return w.StartsAt, w.EndsAt, !t.Before(w.StartsAt) && t.Before(w.EndsAt)
+	}
+
+	schedule, err := w.schedule()
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	from := t.Add(-duration)
+	if from.Before(w.StartsAt) {
+		from = w.StartsAt.Add(-time.Nanosecond)
+	}
+	start = schedule.Next(from)
+	if start.IsZero() || start.After(t) {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, start.Add(duration), true
+}
+
+// NextOccurrence returns the first occurrence starting after t, or nil when
+// the window will not happen again.
+func (w MaintenanceWindow) NextOccurrence(t time.Time) *time.Time {
+	if w.Recurrence == "" {
+		if w.StartsAt.After(t) {
+			return &w.StartsAt
+		}
+		return nil
+	}
+
+	schedule, err := w.schedule()
+	if err != nil {
+		return nil
+	}
+	if t.Before(w.StartsAt) {
+		t = w.StartsAt.Add(-time.Nanosecond)
+	}
+	next := schedule.Next(t)
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
+
+// Occurrences returns the parts of the window's occurrences that fall within
+// [from, to), in order.
+func (w MaintenanceWindow) Occurrences(from, to time.Time) []TimeRange {
+	var ranges []TimeRange
+	add := func(start, end time.Time) {
+		start, end = later(start, from), earlier(end, to)
+		if start.Before(end) {
+			ranges = append(ranges, TimeRange{Start: start, End: end})
+		}
+	}
+
+	if w.Recurrence == "" {
+		add(w.StartsAt, w.EndsAt)
+		return ranges
+	}
+
+	schedule, err := w.schedule()
+	if err != nil {
+		return nil
+	}
+	duration := w.EndsAt.Sub(w.StartsAt)
+	if start, end, ok := w.OccurrenceAt(from); ok {
+		add(start, end)
+	}
+	t := later(from, w.StartsAt).Add(-time.Nanosecond)
+	for range maxOccurrences {
+		start := schedule.Next(t)
+		if start.IsZero() || !start.Before(to) {
+			break
+		}
+		if len(ranges) == 0 || start.After(ranges[len(ranges)-1].Start) {
+			add(start, start.Add(duration))
+		}
+		t = start
+	}
+	return ranges
+}
+
+// maxOccurrences bounds Occurrences for rules that recur every minute.
+const maxOccurrences = 100000
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earlier(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
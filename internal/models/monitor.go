@@ -1,39 +1,283 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Monitor struct {
-	ID              string    `json:"id"`
-	UserID          string    `json:"user_id"`
-	Name            string    `json:"name"`
-	URL             string    `json:"url"`
-	IntervalSeconds int       `json:"interval_seconds"`
-	IsActive        bool      `json:"is_active"`
-	CreatedAt       time.Time `json:"created_at"`
-	LastStatus      string    `json:"last_status,omitempty"`
+	ID                 string             `json:"id"`
+	UserID             string             `json:"user_id"`
+	OrganizationID     string             `json:"organization_id,omitempty"`
+	Name               string             `json:"name"`
+	Type               string             `json:"type"`
+	URL                string             `json:"url"`
+	IntervalSeconds    int                `json:"interval_seconds"`
+	Method             string             `json:"method"`
+	Headers            []MonitorHeader    `json:"headers"`
+	Body               string             `json:"body,omitempty"`
+	AcceptedStatuses   string             `json:"accepted_statuses"`
+	FollowRedirects    bool               `json:"follow_redirects"`
+	Assertions         []MonitorAssertion `json:"assertions"`
+	MaxResponseBytes   int64              `json:"max_response_bytes"`
+	DNS                *DNSCheckConfig    `json:"dns,omitempty"`
+	TLS                *TLSCheckConfig    `json:"tls,omitempty"`
+	PingToken          string             `json:"ping_token,omitempty"`
+	GraceSeconds       int                `json:"grace_seconds"`
+	LastPingAt         *time.Time         `json:"last_ping_at,omitempty"`
+	PingStartedAt      *time.Time         `json:"ping_started_at,omitempty"`
+	Retries            int                `json:"retries"`
+	RetryDelayMs       int                `json:"retry_delay_ms"`
+	FailureThreshold   int                `json:"failure_threshold"`
+	RecoveryThreshold  int                `json:"recovery_threshold"`
+	SLATarget          float64            `json:"sla_target"`
+	Locations          []string           `json:"locations"`
+	Quorum             int                `json:"quorum"`
+	EscalationPolicyID string             `json:"escalation_policy_id,omitempty"`
+	IsActive           bool               `json:"is_active"`
+	NextCheckAt        *time.Time         `json:"next_check_at,omitempty"`
+	CreatedAt          time.Time          `json:"created_at"`
+	MonitorState
+	// LastStatus repeats Status for clients written before it replaced
+	// last_status.
+	//
+	// Deprecated: use Status.
+	LastStatus string `json:"last_status,omitempty"`
+}
+
+// ChecksFrom reports whether the monitor is checked from location.
+func (m Monitor) ChecksFrom(location string) bool {
+	return slices.Contains(m.Locations, location)
+}
+
+// MonitorState is the confirmed status of a monitor. It only changes once
+// FailureThreshold failures or RecoveryThreshold successes arrive in a row.
+type MonitorState struct {
+	Status               string     `json:"status"`
+	ConsecutiveFailures  int        `json:"consecutive_failures"`
+	ConsecutiveSuccesses int        `json:"consecutive_successes"`
+	StatusChangedAt      *time.Time `json:"status_changed_at,omitempty"`
+	// CountedAt is when a result last moved the counters. Monitors checked
+	// from several locations count one combined result per interval.
+	CountedAt *time.Time `json:"-"`
+}
+
+const (
+	MonitorTypeHTTP = "http"
+	MonitorTypeTCP  = "tcp"
+	MonitorTypeDNS  = "dns"
+	MonitorTypeTLS  = "tls"
+
+	MonitorTypeHeartbeat = "heartbeat"
+)
+
+// LocationLocal is where the server's own workers check from. Other locations
+// are the names of registered probes.
+const LocationLocal = "local"
+
+const (
+	PingEventStart   = "start"
+	PingEventSuccess = "success"
+	PingEventFail    = "fail"
+)
+
+const (
+	MonitorStatusPending     = "pending"
+	MonitorStatusUp          = "up"
+	MonitorStatusDown        = "down"
+	MonitorStatusDegraded    = "degraded"
+	MonitorStatusMaintenance = "maintenance"
+)
+
+type DNSCheckConfig struct {
+	RecordType string `json:"record_type"`
+	Expected   string `json:"expected,omitempty"`
+	Resolver   string `json:"resolver,omitempty"`
+}
+
+type TLSCheckConfig struct {
+	ExpiryThresholdDays int `json:"expiry_threshold_days"`
+}
+
+const (
+	DefaultDNSRecordType           = "A"
+	DefaultTLSExpiryThresholdDays  = 14
+	DefaultHeartbeatGraceSeconds   = 300
+	DefaultMonitorMethod           = "GET"
+	DefaultMonitorAcceptedStatuses = "200-399"
+	DefaultMonitorSLATarget        = 99.9
+	DefaultMonitorMaxResponseBytes = 1 << 20
+	DefaultMonitorRetryDelayMs     = 1000
+)
+
+const (
+	AssertionBodyContains    = "body_contains"
+	AssertionBodyNotContains = "body_not_contains"
+	AssertionBodyRegex       = "body_regex"
+	AssertionJSONPathEquals  = "json_path_equals"
+	AssertionJSONPathExists  = "json_path_exists"
+	AssertionHeaderMatches   = "header_matches"
+	AssertionBodyMaxBytes    = "body_max_bytes"
+)
+
+// MonitorAssertion is checked against every response. Target holds the JSON
+// path or header name for the assertion types that need one.
+type MonitorAssertion struct {
+	Type   string `json:"type"`
+	Target string `json:"target,omitempty"`
+	Value  string `json:"value,omitempty"`
+}
+
+// Unreadable marks a secret header that can't be decrypted with the current
+// ENCRYPTION_KEY. Its Value holds the stored ciphertext and checks leave it
+// out until it is saved again with a new value.
+type MonitorHeader struct {
+	Name       string `json:"name"`
+	Value      string `json:"value,omitempty"`
+	Secret     bool   `json:"secret"`
+	Unreadable bool   `json:"unreadable,omitempty"`
+}
+
+// Secret header values never leave the server once saved.
+func (h MonitorHeader) MarshalJSON() ([]byte, error) {
+	type header MonitorHeader
+	if h.Secret {
+		h.Value = ""
+	}
+	return json.Marshal(header(h))
+}
+
+var ErrInvalidStatusRanges = errors.New("invalid status ranges")
+
+type StatusRange struct {
+	Min int
+	Max int
+}
+
+// ParseStatusRanges parses lists such as "200-299,301".
+func ParseStatusRanges(spec string) ([]StatusRange, error) {
+	var ranges []StatusRange
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		low, high, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(low))
+		if err != nil {
+			return nil, ErrInvalidStatusRanges
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(strings.TrimSpace(high)); err != nil {
+				return nil, ErrInvalidStatusRanges
+			}
+		}
+		if from < 100 || to > 599 || from > to {
+			return nil, ErrInvalidStatusRanges
+		}
+		ranges = append(ranges, StatusRange{Min: from, Max: to})
+	}
+	if len(ranges) == 0 {
+		return nil, ErrInvalidStatusRanges
+	}
+	return ranges, nil
+}
+
+func StatusAccepted(spec string, code int) bool {
+	ranges, err := ParseStatusRanges(spec)
+	if err != nil {
+		return code >= 200 && code < 400
+	}
+	for _, r := range ranges {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+	return false
+}
+
+type MonitorUpdate struct {
+	Name              *string
+	Type              *string
+	URL               *string
+	IntervalSeconds   *int
+	Method            *string
+	Headers           []MonitorHeader
+	Body              *string
+	AcceptedStatuses  *string
+	FollowRedirects   *bool
+	Assertions        []MonitorAssertion
+	MaxResponseBytes  *int64
+	DNS               *DNSCheckConfig
+	TLS               *TLSCheckConfig
+	GraceSeconds      *int
+	Retries           *int
+	RetryDelayMs      *int
+	FailureThreshold  *int
+	RecoveryThreshold *int
+	SLATarget         *float64
+	Locations         []string
+	Quorum            *int
 }
 
 type MonitorLog struct {
-	ID             string    `json:"id"`
-	MonitorID      string    `json:"monitor_id"`
-	Status         string    `json:"status"`
-	StatusCode     int       `json:"status_code"`
-	ResponseTimeMs int64     `json:"response_time_ms"`
-	ErrorMessage   string    `json:"error_message,omitempty"`
-	CheckedAt      time.Time `json:"checked_at"`
+	ID             string            `json:"id"`
+	MonitorID      string            `json:"monitor_id"`
+	Status         string            `json:"status"`
+	StatusCode     int               `json:"status_code"`
+	ResponseTimeMs int64             `json:"response_time_ms"`
+	ErrorMessage   string            `json:"error_message,omitempty"`
+	Details        map[string]string `json:"details,omitempty"`
+	Timings        *CheckTimings     `json:"timings,omitempty"`
+	Location       string            `json:"location"`
+	Manual         bool              `json:"manual,omitempty"`
+	CheckedAt      time.Time         `json:"checked_at"`
+}
+
+// CheckTimings splits an HTTP check's response time into its phases.
+type CheckTimings struct {
+	DNSMs      int64 `json:"dns_ms"`
+	ConnectMs  int64 `json:"connect_ms"`
+	TLSMs      int64 `json:"tls_ms"`
+	TTFBMs     int64 `json:"ttfb_ms"`
+	TransferMs int64 `json:"transfer_ms"`
+}
+
+type LatencyPercentiles struct {
+	P50 int64 `json:"p50"`
+	P95 int64 `json:"p95"`
+	P99 int64 `json:"p99"`
+}
+
+type LatencyStats struct {
+	Since    time.Time          `json:"since"`
+	Samples  int                `json:"samples"`
+	Total    LatencyPercentiles `json:"total_ms"`
+	DNS      LatencyPercentiles `json:"dns_ms"`
+	Connect  LatencyPercentiles `json:"connect_ms"`
+	TLS      LatencyPercentiles `json:"tls_ms"`
+	TTFB     LatencyPercentiles `json:"ttfb_ms"`
+	Transfer LatencyPercentiles `json:"transfer_ms"`
 }
 
 type MonitorWithLogs struct {
 	Monitor Monitor      `json:"monitor"`
 	Logs    []MonitorLog `json:"logs"`
 	Uptime  float64      `json:"uptime_percentage"`
+	Latency LatencyStats `json:"latency"`
 }
 
 type MonitorStats struct {
-	Total  int
-	Active int
-	Up     int
-	Down   int
+	Total    int
+	Active   int
+	Up       int
+	Down     int
+	Degraded int
 }
 
 type RecentMonitorLog struct {
@@ -41,9 +285,3 @@ type RecentMonitorLog struct {
 	MonitorName string `json:"monitor_name"`
 	MonitorURL  string `json:"monitor_url"`
 }
-
-type MonitorCheck struct {
-	ID              string
-	URL             string
-	IntervalSeconds int
-}
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+const (
+	NotificationChannelWebhook = "webhook"
+	NotificationChannelEmail   = "email"
+	NotificationChannelSlack   = "slack"
+	NotificationChannelDiscord = "discord"
+)
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+)
+
+const (
+	NotificationEventTest      = "test"
+	NotificationEventEscalated = "incident.escalated"
+)
+
+// NotificationChannel is somewhere alerts are sent. URL is the webhook for
+// webhook, Slack and Discord channels; Email is the address for email ones.
+type NotificationChannel struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	URL       string    `json:"url,omitempty"`
+	Email     string    `json:"email,omitempty"`
+	Secret    string    `json:"-"`
+	HasSecret bool      `json:"has_secret"`
+	Template  string    `json:"template,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NotificationEvent is the data available to message templates and the JSON
+// body posted to generic webhooks.
+type NotificationEvent struct {
+	Event          string    `json:"event"`
+	MonitorID      string    `json:"monitor_id"`
+	MonitorName    string    `json:"monitor_name"`
+	MonitorURL     string    `json:"monitor_url"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status"`
+	StatusCode     int       `json:"status_code,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	IncidentID     string    `json:"incident_id,omitempty"`
+	EscalationStep int       `json:"escalation_step,omitempty"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+type NotificationDelivery struct {
+	ID            string     `json:"id"`
+	ChannelID     string     `json:"channel_id,omitempty"`
+	Recipient     string     `json:"recipient,omitempty"`
+	MonitorID     string     `json:"monitor_id,omitempty"`
+	IncidentID    string     `json:"incident_id,omitempty"`
+	Event         string     `json:"event"`
+	Subject       string     `json:"subject"`
+	Message       string     `json:"message"`
+	Payload       string     `json:"-"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	ResponseCode  int        `json:"response_code,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
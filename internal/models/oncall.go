@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+const DefaultRotationDays = 7
+
+// OnCallSchedule hands on-call duty to the next participant every
+// RotationDays, counting from RotationStart. Overrides take precedence.
+type OnCallSchedule struct {
+	ID             string           `json:"id"`
+	UserID         string           `json:"user_id"`
+	OrganizationID string           `json:"organization_id,omitempty"`
+	Name           string           `json:"name"`
+	RotationStart  time.Time        `json:"rotation_start"`
+	RotationDays   int              `json:"rotation_days"`
+	Participants   []string         `json:"participants"`
+	Overrides      []OnCallOverride `json:"overrides"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
+
+type OnCallOverride struct {
+	ID         string    `json:"id"`
+	ScheduleID string    `json:"schedule_id"`
+	UserID     string    `json:"user_id"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// OnCallAt returns the user on call at t, or an empty string when the
+// schedule has nobody.
+func (s OnCallSchedule) OnCallAt(t time.Time) string {
+	var chosen *OnCallOverride
+	for i, override := range s.Overrides {
+		if t.Before(override.StartsAt) || !t.Before(override.EndsAt) {
+			continue
+		}
+		if chosen == nil || override.CreatedAt.After(chosen.CreatedAt) {
+			chosen = &s.Overrides[i]
+		}
+	}
+	if chosen != nil {
+		return chosen.UserID
+	}
+
+	if len(s.Participants) == 0 {
+		return ""
+	}
+	period := time.Duration(max(s.RotationDays, 1)) * 24 * time.Hour
+	shift := int64(t.Sub(s.RotationStart) / period)
+	if t.Before(s.RotationStart) {
+		shift--
+	}
+	count := int64(len(s.Participants))
+	return s.Participants[((shift%count)+count)%count]
+}
+
+const (
+	EscalationTargetSchedule = "schedule"
+	EscalationTargetUser     = "user"
+	EscalationTargetChannel  = "channel"
+)
+
+// EscalationPolicy lists who to alert, in order, while an incident stays
+// unacknowledged. Each step fires DelayMinutes after the previous one.
+type EscalationPolicy struct {
+	ID             string           `json:"id"`
+	UserID         string           `json:"user_id"`
+	OrganizationID string           `json:"organization_id,omitempty"`
+	Name           string           `json:"name"`
+	Steps          []EscalationStep `json:"steps"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
+
+type EscalationStep struct {
+	DelayMinutes int                `json:"delay_minutes"`
+	Targets      []EscalationTarget `json:"targets"`
+}
+
+type EscalationTarget struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
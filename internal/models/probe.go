@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Probe is a remote agent that checks monitors from its location and reports
+// the results back. It authenticates with a token that is only shown when the
+// probe is created.
+type Probe struct {
+	ID         string     `json:"id"`
+	Location   string     `json:"location"`
+	TokenHash  string     `json:"-"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Location is somewhere monitors can be checked from.
+type Location struct {
+	Name       string     `json:"name"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// ProbeCheck is a monitor as handed to a probe. Unlike in API responses it
+// carries the values of secret headers, which the probe needs to check.
+type ProbeCheck struct {
+	Monitor
+	Headers []ProbeHeader `json:"headers"`
+}
+
+type ProbeHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ProbeResult is one check result reported by a probe.
+type ProbeResult struct {
+	MonitorID      string            `json:"monitor_id"`
+	Status         string            `json:"status"`
+	StatusCode     int               `json:"status_code"`
+	ResponseTimeMs int64             `json:"response_time_ms"`
+	ErrorMessage   string            `json:"error_message,omitempty"`
+	Details        map[string]string `json:"details,omitempty"`
+	Timings        *CheckTimings     `json:"timings,omitempty"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+const (
+	RollupHour = "hour"
+	RollupDay  = "day"
+)
+
+// RollupSize returns the bucket length of a granularity.
+func RollupSize(granularity string) time.Duration {
+	if granularity == RollupDay {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+// MonitorRollup summarises the checks of one monitor in an hour or a UTC day.
+// Checks made during maintenance are left out, and the latency figures cover
+// successful checks only.
+type MonitorRollup struct {
+	MonitorID        string    `json:"-"`
+	BucketStart      time.Time `json:"bucket_start"`
+	Checks           int       `json:"checks"`
+	UpChecks         int       `json:"up_checks"`
+	MinResponseMs    int64     `json:"min_response_ms"`
+	AvgResponseMs    int64     `json:"avg_response_ms"`
+	MaxResponseMs    int64     `json:"max_response_ms"`
+	P95ResponseMs    int64     `json:"p95_response_ms"`
+	FirstCheckedAt   time.Time `json:"-"`
+	UptimePercentage *float64  `json:"uptime_percentage"`
+}
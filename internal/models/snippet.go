@@ -4,6 +4,7 @@ import "time"
 
 type Snippet struct {
 	ID            string     `json:"id"`
+	UserID        *string    `json:"-"`
 	Hash          string     `json:"hash"`
 	Content       string     `json:"content"`
 	PasswordHash  *string    `json:"-"`
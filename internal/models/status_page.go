@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+const StatusPageDays = 90
+
+const (
+	StatusPageOperational   = "operational"
+	StatusPageDegraded      = "degraded"
+	StatusPagePartialOutage = "partial_outage"
+	StatusPageMajorOutage   = "major_outage"
+	StatusPageMaintenance   = "maintenance"
+)
+
+type StatusPage struct {
+	ID             string              `json:"id"`
+	UserID         string              `json:"user_id"`
+	OrganizationID string              `json:"organization_id,omitempty"`
+	Slug           string              `json:"slug"`
+	Title          string              `json:"title"`
+	Description    string              `json:"description"`
+	CustomCSS      string              `json:"custom_css"`
+	Sections       []StatusPageSection `json:"sections"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
+
+type StatusPageSection struct {
+	Name       string   `json:"name"`
+	MonitorIDs []string `json:"monitor_ids"`
+}
+
+func (p StatusPage) MonitorIDs() []string {
+	var ids []string
+	for _, section := range p.Sections {
+		ids = append(ids, section.MonitorIDs...)
+	}
+	return ids
+}
+
+// PublicStatusPage is what visitors of a status page see. It carries monitor
+// names and states only, never their URLs, IDs or check errors.
+type PublicStatusPage struct {
+	Slug        string                 `json:"slug"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Status      string                 `json:"status"`
+	Sections    []PublicStatusSection  `json:"sections"`
+	Incidents   []PublicStatusIncident `json:"incidents"`
+	GeneratedAt time.Time              `json:"generated_at"`
+	CustomCSS   string                 `json:"-"`
+}
+
+type PublicStatusSection struct {
+	Name     string                `json:"name"`
+	Monitors []PublicStatusMonitor `json:"monitors"`
+}
+
+type PublicStatusMonitor struct {
+	Name   string        `json:"name"`
+	Status string        `json:"status"`
+	Uptime *float64      `json:"uptime_percentage"`
+	Days   []DailyUptime `json:"days"`
+}
+
+type PublicStatusIncident struct {
+	MonitorName     string    `json:"monitor_name"`
+	StartedAt       time.Time `json:"started_at"`
+	DurationSeconds int64     `json:"duration_seconds"`
+	Acknowledged    bool      `json:"acknowledged"`
+}
+
+// DailyUptime is the share of a UTC day's checks that succeeded. Uptime is nil
+// when no checks outside maintenance were made that day.
+type DailyUptime struct {
+	Date   string   `json:"date"`
+	Checks int      `json:"checks"`
+	Uptime *float64 `json:"uptime_percentage"`
+}
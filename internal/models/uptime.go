@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+type TimeRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// UptimeReport is the time-weighted availability of a monitor over a range.
+// Downtime is the time spent in confirmed down state, maintenance and paused
+// time are left out of both, and the range is clipped to when the monitor was
+// first checked.
+type UptimeReport struct {
+	Label              string     `json:"label"`
+	From               time.Time  `json:"from"`
+	To                 time.Time  `json:"to"`
+	MonitoredSeconds   int64      `json:"monitored_seconds"`
+	DowntimeSeconds    int64      `json:"downtime_seconds"`
+	MaintenanceSeconds int64      `json:"maintenance_seconds"`
+	PausedSeconds      int64      `json:"paused_seconds"`
+	Incidents          int        `json:"incidents"`
+	UptimePercentage   *float64   `json:"uptime_percentage"`
+	SLA                *SLAReport `json:"sla,omitempty"`
+}
+
+// SLAReport compares the uptime with a target. The error budget is the
+// downtime the target allows over the monitored time; remaining budget goes
+// negative once the target is missed.
+type SLAReport struct {
+	TargetPercentage               float64 `json:"target_percentage"`
+	Met                            bool    `json:"met"`
+	ErrorBudgetSeconds             int64   `json:"error_budget_seconds"`
+	ErrorBudgetRemainingSeconds    int64   `json:"error_budget_remaining_seconds"`
+	ErrorBudgetRemainingPercentage float64 `json:"error_budget_remaining_percentage"`
+}
@@ -7,21 +7,46 @@ type User struct {
 	Username     string
 	Email        string
 	PasswordHash string
+	DisplayName  string
+	Bio          string
+	AvatarURL    string
 	CreatedAt    time.Time
 }
 
 type UserResponse struct {
-	ID        string    `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type PublicProfile struct {
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+	JoinedAt    time.Time `json:"joined_at"`
+	PostCount   int       `json:"post_count"`
+}
+
+type UserFilter struct {
+	Search string
+	Limit  int
+	Offset int
 }
 
 func (u User) Response() UserResponse {
 	return UserResponse{
-		ID:        u.ID,
-		Username:  u.Username,
-		Email:     u.Email,
-		CreatedAt: u.CreatedAt,
+		ID:          u.ID,
+		Username:    u.Username,
+		Email:       u.Email,
+		DisplayName: u.DisplayName,
+		Bio:         u.Bio,
+		AvatarURL:   u.AvatarURL,
+		CreatedAt:   u.CreatedAt,
 	}
 }
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"learn/internal/models"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry models.AuditLog) error
+	List(ctx context.Context, filter models.AuditFilter) ([]models.AuditLog, error)
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"learn/internal/models"
+)
+
+type ExportRepository interface {
+	Create(ctx context.Context, export models.DataExport) (models.DataExport, error)
+	GetByID(ctx context.Context, userID, id string) (models.DataExport, error)
+	GetPendingByUser(ctx context.Context, userID string) (models.DataExport, error)
+	MarkReady(ctx context.Context, id, token string, archive []byte, completedAt, expiresAt time.Time) error
+	MarkFailed(ctx context.Context, id, message string, completedAt time.Time) error
+	FailPendingBefore(ctx context.Context, createdBefore time.Time, message string, completedAt time.Time) (int64, error)
+	ConsumeArchive(ctx context.Context, token string, now time.Time) ([]byte, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"learn/internal/models"
+)
+
+var ErrIncidentOpen = errors.New("monitor already has an open incident")
+
+type IncidentRepository interface {
+	Create(ctx context.Context, incident models.Incident) error
+	GetOpenByMonitor(ctx context.Context, monitorID string) (models.Incident, error)
+	RecordCheck(ctx context.Context, id string) error
+	Resolve(ctx context.Context, id string, at time.Time) error
+	GetByID(ctx context.Context, userID, id string) (models.Incident, error)
+	ListByMonitor(ctx context.Context, monitorID string, limit int) ([]models.Incident, error)
+	ListOverlapping(ctx context.Context, monitorID string, from, to time.Time) ([]models.Incident, error)
+	ListByUser(ctx context.Context, userID, status string, limit int) ([]models.Incident, error)
+	Acknowledge(ctx context.Context, userID, id, notes string, at time.Time) (bool, error)
+	ListDueEscalations(ctx context.Context, now time.Time, limit int) ([]models.Incident, error)
+	AdvanceEscalation(ctx context.Context, id string, from, to int, next *time.Time) (bool, error)
+}
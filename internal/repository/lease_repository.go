@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"learn/internal/models"
+)
+
+type LeaseRepository interface {
+	ClaimCheck(ctx context.Context, monitor models.Monitor, workerID string, nextCheckAt, leasedUntil, now time.Time) (bool, error)
+	ReleaseCheck(ctx context.Context, monitorID, workerID string) error
+	ClaimJob(ctx context.Context, name, workerID string, leasedUntil, now time.Time) (bool, error)
+	ReleaseJobs(ctx context.Context, workerID string) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"learn/internal/models"
+)
+
+type MaintenanceRepository interface {
+	Create(ctx context.Context, window models.MaintenanceWindow) (models.MaintenanceWindow, error)
+	ListByUser(ctx context.Context, userID string) ([]models.MaintenanceWindow, error)
+	GetByID(ctx context.Context, id string) (models.MaintenanceWindow, error)
+	Delete(ctx context.Context, userID, id string) (bool, error)
+	ListByMonitor(ctx context.Context, monitorID string) ([]models.MaintenanceWindow, error)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"learn/internal/models"
+)
+
+type MonitorEventRepository interface {
+	Append(ctx context.Context, event models.MonitorEvent, createdAt time.Time) error
+	ListAfter(ctx context.Context, afterID uint64, limit int) ([]models.MonitorEvent, error)
+	Bounds(ctx context.Context) (oldest, latest uint64, err error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
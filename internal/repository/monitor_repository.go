@@ -11,12 +11,27 @@ type MonitorRepository interface {
 	Create(ctx context.Context, monitor models.Monitor) (models.Monitor, error)
 	ListByUser(ctx context.Context, userID string) ([]models.Monitor, error)
 	GetByID(ctx context.Context, userID, id string) (models.Monitor, error)
+	GetByIDUnscoped(ctx context.Context, id string) (models.Monitor, error)
+	GetByPingToken(ctx context.Context, token string) (models.Monitor, error)
+	RecordPingStart(ctx context.Context, id string, at time.Time) error
+	RecordPing(ctx context.Context, id string, at time.Time) error
+	Update(ctx context.Context, userID string, monitor models.Monitor) (bool, error)
+	UpdateState(ctx context.Context, id string, from, to models.MonitorState) (bool, error)
+	SetEscalationPolicy(ctx context.Context, id, policyID string) error
 	Delete(ctx context.Context, userID, id string) (bool, error)
 	Toggle(ctx context.Context, userID, id string) (bool, error)
+	ListPauses(ctx context.Context, monitorID string, from, to time.Time) ([]models.TimeRange, error)
 	ListLogs(ctx context.Context, monitorID string, limit int) ([]models.MonitorLog, error)
+	ListAllLogs(ctx context.Context, monitorID string) ([]models.MonitorLog, error)
+	ListLogsSince(ctx context.Context, monitorID string, since time.Time) ([]models.MonitorLog, error)
+	ListLogsBetween(ctx context.Context, monitorID string, from, to time.Time) ([]models.MonitorLog, error)
+	ListLoggedMonitorIDs(ctx context.Context, from, to time.Time) ([]string, error)
+	DeleteLogsBefore(ctx context.Context, before time.Time) (int64, error)
 	ListRecentLogs(ctx context.Context, userID string, limit int) ([]models.RecentMonitorLog, error)
 	CountStats(ctx context.Context, userID string) (models.MonitorStats, error)
-	ListActive(ctx context.Context) ([]models.MonitorCheck, error)
-	GetLastCheck(ctx context.Context, monitorID string) (time.Time, error)
+	ListActiveByLocation(ctx context.Context, location string) ([]models.Monitor, error)
+	GetOldestCheck(ctx context.Context) (time.Time, error)
+	GetFirstCheck(ctx context.Context, monitorID string) (time.Time, error)
+	ListLastChecks(ctx context.Context) (map[string]time.Time, error)
 	CreateLog(ctx context.Context, log models.MonitorLog) error
 }
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"learn/internal/models"
+)
+
+type NotificationRepository interface {
+	CreateChannel(ctx context.Context, channel models.NotificationChannel) (models.NotificationChannel, error)
+	ListChannels(ctx context.Context, userID string) ([]models.NotificationChannel, error)
+	GetChannel(ctx context.Context, id string) (models.NotificationChannel, error)
+	DeleteChannel(ctx context.Context, userID, id string) (bool, error)
+	SetMonitorChannels(ctx context.Context, monitorID string, channelIDs []string) error
+	ListMonitorChannels(ctx context.Context, monitorID string) ([]models.NotificationChannel, error)
+	CreateDelivery(ctx context.Context, delivery models.NotificationDelivery) error
+	ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.NotificationDelivery, error)
+	ClaimDelivery(ctx context.Context, id string, now, until time.Time) (bool, error)
+	UpdateDelivery(ctx context.Context, delivery models.NotificationDelivery) error
+	ListDeliveries(ctx context.Context, channelID string, limit int) ([]models.NotificationDelivery, error)
+	ListIncidentDeliveries(ctx context.Context, incidentID string, limit int) ([]models.NotificationDelivery, error)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"learn/internal/models"
+)
+
+type OnCallRepository interface {
+	CreateSchedule(ctx context.Context, schedule models.OnCallSchedule) (models.OnCallSchedule, error)
+	ListSchedules(ctx context.Context, userID string) ([]models.OnCallSchedule, error)
+	GetSchedule(ctx context.Context, id string) (models.OnCallSchedule, error)
+	DeleteSchedule(ctx context.Context, userID, id string) (bool, error)
+	CreateOverride(ctx context.Context, override models.OnCallOverride) error
+	DeleteOverride(ctx context.Context, scheduleID, id string) (bool, error)
+	CreatePolicy(ctx context.Context, policy models.EscalationPolicy) (models.EscalationPolicy, error)
+	ListPolicies(ctx context.Context, userID string) ([]models.EscalationPolicy, error)
+	GetPolicy(ctx context.Context, id string) (models.EscalationPolicy, error)
+	DeletePolicy(ctx context.Context, userID, id string) (bool, error)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"learn/internal/models"
+)
+
+var ErrAlreadyMember = errors.New("user is already a member")
+
+type OrganizationRepository interface {
+	Create(ctx context.Context, organization models.Organization) (models.Organization, error)
+	ListByUser(ctx context.Context, userID string) ([]models.Organization, error)
+	GetForUser(ctx context.Context, userID, id string) (models.Organization, error)
+	GetMemberRole(ctx context.Context, organizationID, userID string) (string, error)
+	ListMembers(ctx context.Context, organizationID string) ([]models.OrganizationMember, error)
+	UpdateMemberRole(ctx context.Context, organizationID, userID, role string) (bool, error)
+	RemoveMember(ctx context.Context, organizationID, userID string) (bool, error)
+	CountOwners(ctx context.Context, organizationID string) (int, error)
+	CreateInvite(ctx context.Context, invite models.OrganizationInvite) (models.OrganizationInvite, error)
+	ListPendingInvites(ctx context.Context, organizationID string, now time.Time) ([]models.OrganizationInvite, error)
+	DeleteInvite(ctx context.Context, organizationID, id string) (bool, error)
+	GetInviteByTokenHash(ctx context.Context, tokenHash string) (models.OrganizationInvite, error)
+	AcceptInvite(ctx context.Context, invite models.OrganizationInvite, userID string, now time.Time) error
+}
@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+
+	"learn/internal/models"
+)
+
+type PostRepository interface {
+	ListByUser(ctx context.Context, userID string) ([]models.Post, error)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"learn/internal/models"
+)
+
+var ErrProbeExists = errors.New("probe already exists")
+
+type ProbeRepository interface {
+	Create(ctx context.Context, probe models.Probe) (models.Probe, error)
+	List(ctx context.Context) ([]models.Probe, error)
+	GetByID(ctx context.Context, id string) (models.Probe, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (models.Probe, error)
+	Delete(ctx context.Context, id string) (bool, error)
+	Touch(ctx context.Context, id string, at time.Time) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"learn/internal/models"
+)
+
+type RollupRepository interface {
+	RolledUntil(ctx context.Context, granularity string) (time.Time, error)
+	Save(ctx context.Context, granularity string, rollups []models.MonitorRollup, until time.Time) error
+	List(ctx context.Context, monitorID, granularity string, from, to time.Time) ([]models.MonitorRollup, error)
+	DeleteBefore(ctx context.Context, granularity string, before time.Time) (int64, error)
+}
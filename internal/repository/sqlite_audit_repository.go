@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"learn/internal/models"
+)
+
+type SQLiteAuditRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteAuditRepository(db *sql.DB) *SQLiteAuditRepository {
+	return &SQLiteAuditRepository{db: db}
+}
+
+func (r *SQLiteAuditRepository) Create(ctx context.Context, entry models.AuditLog) error {
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO audit_logs (id, actor_id, action, resource_type, resource_id, ip_address, user_agent, before_value, after_value)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, entry.ID, nullString(entry.ActorID), entry.Action, nullString(entry.ResourceType), nullString(entry.ResourceID),
+		nullString(entry.IPAddress), nullString(entry.UserAgent), nullString(string(entry.Before)), nullString(string(entry.After)))
+	return err
+}
+
+func (r *SQLiteAuditRepository) List(ctx context.Context, filter models.AuditFilter) ([]models.AuditLog, error) {
+	var conditions []string
+	var args []any
+	if filter.ActorID != "" {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, filter.ActorID)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, formatTime(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, formatTime(*filter.To))
+	}
+
+	query := `
+SELECT id, COALESCE(actor_id, ''), action, COALESCE(resource_type, ''), COALESCE(resource_id, ''),
+	COALESCE(ip_address, ''), COALESCE(user_agent, ''), COALESCE(before_value, ''), COALESCE(after_value, ''), created_at
+FROM audit_logs`
+	if len(conditions) > 0 {
+		query += "\nWHERE " + strings.Join(conditions, " AND ")
+	}
+	query += "\nORDER BY created_at DESC, rowid DESC\nLIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.AuditLog
+	for rows.Next() {
+		var entry models.AuditLog
+		var before, after string
+		if err := rows.Scan(&entry.ID, &entry.ActorID, &entry.Action, &entry.ResourceType, &entry.ResourceID,
+			&entry.IPAddress, &entry.UserAgent, &before, &after, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		if before != "" {
+			entry.Before = []byte(before)
+		}
+		if after != "" {
+			entry.After = []byte(after)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func nullString(value string) sql.NullString {
+	if value == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: value, Valid: true}
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"learn/internal/models"
+)
+
+type SQLiteExportRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteExportRepository(db *sql.DB) *SQLiteExportRepository {
+	return &SQLiteExportRepository{db: db}
+}
+
+func (r *SQLiteExportRepository) Create(ctx context.Context, export models.DataExport) (models.DataExport, error) {
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO data_exports (id, user_id, status)
+VALUES (?, ?, ?)
+`, export.ID, export.UserID, export.Status)
+	if err != nil {
+		return models.DataExport{}, err
+	}
+
+	return r.GetByID(ctx, export.UserID, export.ID)
+}
+
+func (r *SQLiteExportRepository) GetByID(ctx context.Context, userID, id string) (models.DataExport, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT id, user_id, status, COALESCE(token, ''), COALESCE(error_message, ''), completed_at, expires_at, downloaded_at, created_at
+FROM data_exports
+WHERE id = ? AND user_id = ?
+`, id, userID)
+
+	return scanExport(row)
+}
+
+func (r *SQLiteExportRepository) GetPendingByUser(ctx context.Context, userID string) (models.DataExport, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT id, user_id, status, COALESCE(token, ''), COALESCE(error_message, ''), completed_at, expires_at, downloaded_at, created_at
+FROM data_exports
+WHERE user_id = ? AND status = ?
+ORDER BY created_at DESC
+LIMIT 1
+`, userID, models.ExportStatusPending)
+
+	return scanExport(row)
+}
+
+func (r *SQLiteExportRepository) MarkReady(ctx context.Context, id, token string, archive []byte, completedAt, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+UPDATE data_exports
+SET status = ?, token = ?, archive = ?, completed_at = ?, expires_at = ?
+WHERE id = ?
+`, models.ExportStatusReady, token, archive, formatTime(completedAt), formatTime(expiresAt), id)
+	return err
+}
+
+func (r *SQLiteExportRepository) MarkFailed(ctx context.Context, id, message string, completedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+UPDATE data_exports
+SET status = ?, error_message = ?, completed_at = ?
+WHERE id = ?
+`, models.ExportStatusFailed, message, formatTime(completedAt), id)
+	return err
+}
+
+func (r *SQLiteExportRepository) FailPendingBefore(ctx context.Context, createdBefore time.Time, message string, completedAt time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+UPDATE data_exports
+SET status = ?, error_message = ?, completed_at = ?
+WHERE status = ? AND created_at < ?
+`, models.ExportStatusFailed, message, formatTime(completedAt), models.ExportStatusPending, formatTime(createdBefore))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *SQLiteExportRepository) ConsumeArchive(ctx context.Context, token string, now time.Time) ([]byte, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var archive []byte
+	if err := tx.QueryRowContext(ctx, `
+SELECT archive FROM data_exports
+WHERE token = ? AND status = ? AND archive IS NOT NULL AND expires_at > ?
+`, token, models.ExportStatusReady, formatTime(now)).Scan(&archive); err != nil {
+		return nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+UPDATE data_exports
+SET status = ?, archive = NULL, downloaded_at = ?
+WHERE token = ? AND status = ?
+`, models.ExportStatusDownloaded, formatTime(now), token, models.ExportStatusReady)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+func (r *SQLiteExportRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM data_exports WHERE expires_at < ?", formatTime(now))
+	if err != nil {
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func scanExport(row rowScanner) (models.DataExport, error) {
+	var export models.DataExport
+	var completedAtValue, expiresAtValue, downloadedAtValue, createdAtValue any
+	if err := row.Scan(&export.ID, &export.UserID, &export.Status, &export.Token, &export.ErrorMessage, &completedAtValue, &expiresAtValue, &downloadedAtValue, &createdAtValue); err != nil {
+		return models.DataExport{}, err
+	}
+	if parsed, ok := parseTimeValue(completedAtValue); ok {
+		export.CompletedAt = &parsed
+	}
+	if parsed, ok := parseTimeValue(expiresAtValue); ok {
+		export.ExpiresAt = &parsed
+	}
+	if parsed, ok := parseTimeValue(downloadedAtValue); ok {
+		export.DownloadedAt = &parsed
+	}
+	if parsed, ok := parseTimeValue(createdAtValue); ok {
+		export.CreatedAt = parsed
+	}
+	return export, nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"learn/internal/models"
+)
+
+const incidentColumns = `i.id, i.monitor_id, m.name, i.status, i.started_at, i.resolved_at, i.first_error, i.check_count, i.notes,
+	COALESCE(i.acknowledged_by, ''), i.acknowledged_at, COALESCE(i.escalation_policy_id, ''), i.escalation_step, i.next_escalation_at`
+
+type SQLiteIncidentRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteIncidentRepository(db *sql.DB) *SQLiteIncidentRepository {
+	return &SQLiteIncidentRepository{db: db}
+}
+
+func (r *SQLiteIncidentRepository) Create(ctx context.Context, incident models.Incident) error {
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO incidents (id, monitor_id, status, started_at, first_error, check_count, escalation_policy_id, escalation_step, next_escalation_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, incident.ID, incident.MonitorID, incident.Status, formatTime(incident.StartedAt), incident.FirstError, incident.CheckCount,
+		nullString(incident.EscalationPolicyID), incident.EscalationStep, nullTime(incident.NextEscalationAt))
+	if err != nil && isSQLiteUniqueConstraint(err) {
+		return ErrIncidentOpen
+	}
+	return err
+}
+
+func (r *SQLiteIncidentRepository) GetOpenByMonitor(ctx context.Context, monitorID string) (models.Incident, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT `+incidentColumns+`
+FROM incidents i
+JOIN monitors m ON m.id = i.monitor_id
+WHERE i.monitor_id = ? AND i.status = ?
+ORDER BY i.started_at DESC
+LIMIT 1
+`, monitorID, models.IncidentStatusOpen)
+
+	return scanIncident(row)
+}
+
+func (r *SQLiteIncidentRepository) RecordCheck(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE incidents SET check_count = check_count + 1 WHERE id = ?`, id)
+	return err
+}
+
+func (r *SQLiteIncidentRepository) Resolve(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+UPDATE incidents SET status = ?, resolved_at = ?, check_count = check_count + 1, next_escalation_at = NULL
+WHERE id = ? AND status = ?
+`, models.IncidentStatusResolved, formatTime(at), id, models.IncidentStatusOpen)
+	return err
+}
+
+func (r *SQLiteIncidentRepository) GetByID(ctx context.Context, userID, id string) (models.Incident, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT `+incidentColumns+`
+FROM incidents i
+JOIN monitors m ON m.id = i.monitor_id
+WHERE i.id = ? AND `+monitorReadScope+`
+`, id, userID, userID)
+
+	return scanIncident(row)
+}
+
+func (r *SQLiteIncidentRepository) ListByMonitor(ctx context.Context, monitorID string, limit int) ([]models.Incident, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+incidentColumns+`
+FROM incidents i
+JOIN monitors m ON m.id = i.monitor_id
+WHERE i.monitor_id = ?
+ORDER BY i.started_at DESC
+LIMIT ?
+`, monitorID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanIncidents(rows)
+}
+
+func (r *SQLiteIncidentRepository) ListOverlapping(ctx context.Context, monitorID string, from, to time.Time) ([]models.Incident, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+incidentColumns+`
+FROM incidents i
+JOIN monitors m ON m.id = i.monitor_id
+WHERE i.monitor_id = ? AND i.started_at < ? AND (i.resolved_at IS NULL OR i.resolved_at > ?)
+ORDER BY i.started_at ASC
+`, monitorID, formatTime(to), formatTime(from))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanIncidents(rows)
+}
+
+func (r *SQLiteIncidentRepository) ListByUser(ctx context.Context, userID, status string, limit int) ([]models.Incident, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+incidentColumns+`
+FROM incidents i
+JOIN monitors m ON m.id = i.monitor_id
+WHERE (? = '' OR i.status = ?) AND `+monitorReadScope+`
+ORDER BY i.started_at DESC
+LIMIT ?
+`, status, status, userID, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanIncidents(rows)
+}
+
+func (r *SQLiteIncidentRepository) Acknowledge(ctx context.Context, userID, id, notes string, at time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+UPDATE incidents SET
+	acknowledged_by = COALESCE(acknowledged_by, ?),
+	acknowledged_at = COALESCE(acknowledged_at, ?),
+	notes = CASE WHEN ? = '' THEN notes ELSE ? END,
+	next_escalation_at = NULL
+WHERE id = ? AND monitor_id IN (SELECT id FROM monitors WHERE `+monitorWriteScope+`)
+`, userID, formatTime(at), notes, notes, id, userID, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *SQLiteIncidentRepository) ListDueEscalations(ctx context.Context, now time.Time, limit int) ([]models.Incident, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+incidentColumns+`
+FROM incidents i
+JOIN monitors m ON m.id = i.monitor_id
+WHERE i.status = ? AND i.acknowledged_at IS NULL AND i.next_escalation_at <= ?
+ORDER BY i.next_escalation_at ASC
+LIMIT ?
+`, models.IncidentStatusOpen, formatTime(now), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanIncidents(rows)
+}
+
+// AdvanceEscalation moves an incident from escalation step from to step to
+// unless it was acknowledged, resolved or escalated by another process in the
+// meantime.
+func (r *SQLiteIncidentRepository) AdvanceEscalation(ctx context.Context, id string, from, to int, next *time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+UPDATE incidents SET escalation_step = ?, next_escalation_at = ?
+WHERE id = ? AND status = ? AND acknowledged_at IS NULL AND escalation_step = ?
+`, to, nullTime(next), id, models.IncidentStatusOpen, from)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func scanIncident(row rowScanner) (models.Incident, error) {
+	var incident models.Incident
+	var resolvedAt, acknowledgedAt, nextEscalationAt any
+	if err := row.Scan(&incident.ID, &incident.MonitorID, &incident.MonitorName, &incident.Status, &incident.StartedAt, &resolvedAt,
+		&incident.FirstError, &incident.CheckCount, &incident.Notes, &incident.AcknowledgedBy, &acknowledgedAt,
+		&incident.EscalationPolicyID, &incident.EscalationStep, &nextEscalationAt); err != nil {
+		return models.Incident{}, err
+	}
+	if parsed, ok := parseTimeValue(nextEscalationAt); ok {
+		incident.NextEscalationAt = &parsed
+	}
+
+	end := time.Now()
+	if parsed, ok := parseTimeValue(resolvedAt); ok {
+		incident.ResolvedAt = &parsed
+		end = parsed
+	}
+	if parsed, ok := parseTimeValue(acknowledgedAt); ok {
+		incident.AcknowledgedAt = &parsed
+	}
+	incident.DurationSeconds = int64(end.Sub(incident.StartedAt).Seconds())
+	return incident, nil
+}
+
+func scanIncidents(rows *sql.Rows) ([]models.Incident, error) {
+	var incidents []models.Incident
+	for rows.Next() {
+		incident, err := scanIncident(rows)
+		if err != nil {
+			return nil, err
+		}
+		incidents = append(incidents, incident)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return incidents, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"learn/internal/models"
+)
+
+type SQLiteLeaseRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteLeaseRepository(db *sql.DB) *SQLiteLeaseRepository {
+	return &SQLiteLeaseRepository{db: db}
+}
+
+// ClaimCheck takes the monitor's current check for workerID. It only succeeds
+// while the monitor is active, its next_check_at is still what was read into
+// monitor and no other worker holds an unexpired lease, so of several workers
+// racing for the same check exactly one wins.
+func (r *SQLiteLeaseRepository) ClaimCheck(ctx context.Context, monitor models.Monitor, workerID string, nextCheckAt, leasedUntil, now time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+UPDATE monitors SET next_check_at = ?, leased_by = ?, leased_until = ?
+WHERE id = ? AND is_active = 1 AND next_check_at IS ?
+	AND (leased_by IS NULL OR leased_by = ? OR leased_until IS NULL OR leased_until <= ?)
+`, formatTime(nextCheckAt), workerID, formatTime(leasedUntil), monitor.ID, nullTime(monitor.NextCheckAt), workerID, formatTime(now))
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *SQLiteLeaseRepository) ReleaseCheck(ctx context.Context, monitorID, workerID string) error {
+	_, err := r.db.ExecContext(ctx, `
+UPDATE monitors SET leased_by = NULL, leased_until = NULL WHERE id = ? AND leased_by = ?
+`, monitorID, workerID)
+	return err
+}
+
+// ClaimJob takes or renews the named lease for workerID unless another worker
+// holds it and it has not expired yet.
+func (r *SQLiteLeaseRepository) ClaimJob(ctx context.Context, name, workerID string, leasedUntil, now time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+INSERT INTO job_leases (name, worker_id, leased_until) VALUES (?, ?, ?)
+ON CONFLICT (name) DO UPDATE SET worker_id = excluded.worker_id, leased_until = excluded.leased_until
+WHERE job_leases.worker_id = excluded.worker_id OR job_leases.leased_until <= ?
+`, name, workerID, formatTime(leasedUntil), formatTime(now))
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *SQLiteLeaseRepository) ReleaseJobs(ctx context.Context, workerID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM job_leases WHERE worker_id = ?`, workerID)
+	return err
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"learn/internal/models"
+)
+
+const maintenanceWindowColumns = `w.id, w.user_id, COALESCE(w.organization_id, ''), w.name, w.starts_at, w.ends_at, w.recurrence, w.timezone,
+	w.mode, w.created_at`
+
+type SQLiteMaintenanceRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteMaintenanceRepository(db *sql.DB) *SQLiteMaintenanceRepository {
+	return &SQLiteMaintenanceRepository{db: db}
+}
+
+func (r *SQLiteMaintenanceRepository) Create(ctx context.Context, window models.MaintenanceWindow) (models.MaintenanceWindow, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO maintenance_windows (id, user_id, organization_id, name, starts_at, ends_at, recurrence, timezone, mode)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, window.ID, window.UserID, nullString(window.OrganizationID), window.Name, formatTime(window.StartsAt), formatTime(window.EndsAt),
+		window.Recurrence, window.Timezone, window.Mode); err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+	for _, monitorID := range window.MonitorIDs {
+		if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO maintenance_window_monitors (window_id, monitor_id)
+VALUES (?, ?)
+`, window.ID, monitorID); err != nil {
+			return models.MaintenanceWindow{}, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+
+	return r.GetByID(ctx, window.ID)
+}
+
+func (r *SQLiteMaintenanceRepository) ListByUser(ctx context.Context, userID string) ([]models.MaintenanceWindow, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+maintenanceWindowColumns+`
+FROM maintenance_windows w
+WHERE `+monitorReadScope+`
+ORDER BY w.starts_at DESC
+`, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows, err := scanMaintenanceWindows(rows)
+	if err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	return r.withMonitors(ctx, windows)
+}
+
+func (r *SQLiteMaintenanceRepository) GetByID(ctx context.Context, id string) (models.MaintenanceWindow, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT `+maintenanceWindowColumns+`
+FROM maintenance_windows w
+WHERE w.id = ?
+`, id)
+
+	window, err := scanMaintenanceWindow(row)
+	if err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+	if window.MonitorIDs, err = r.listMonitorIDs(ctx, id); err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+	return window, nil
+}
+
+func (r *SQLiteMaintenanceRepository) Delete(ctx context.Context, userID, id string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+DELETE FROM maintenance_windows WHERE id = ? AND `+monitorWriteScope+`
+`, id, userID, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil || rows == 0 {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM maintenance_window_monitors WHERE window_id = ?`, id); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *SQLiteMaintenanceRepository) ListByMonitor(ctx context.Context, monitorID string) ([]models.MaintenanceWindow, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+maintenanceWindowColumns+`
+FROM maintenance_windows w
+JOIN maintenance_window_monitors wm ON wm.window_id = w.id
+WHERE wm.monitor_id = ?
+`, monitorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMaintenanceWindows(rows)
+}
+
+func (r *SQLiteMaintenanceRepository) withMonitors(ctx context.Context, windows []models.MaintenanceWindow) ([]models.MaintenanceWindow, error) {
+	for i := range windows {
+		ids, err := r.listMonitorIDs(ctx, windows[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		windows[i].MonitorIDs = ids
+	}
+	return windows, nil
+}
+
+func (r *SQLiteMaintenanceRepository) listMonitorIDs(ctx context.Context, windowID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT wm.monitor_id
+FROM maintenance_window_monitors wm
+JOIN monitors m ON m.id = wm.monitor_id
+WHERE wm.window_id = ?
+ORDER BY m.name ASC
+`, windowID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func scanMaintenanceWindow(row rowScanner) (models.MaintenanceWindow, error) {
+	var window models.MaintenanceWindow
+	var startsAt, endsAt any
+	if err := row.Scan(&window.ID, &window.UserID, &window.OrganizationID, &window.Name, &startsAt, &endsAt, &window.Recurrence,
+		&window.Timezone, &window.Mode, &window.CreatedAt); err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+	window.StartsAt, _ = parseTimeValue(startsAt)
+	window.EndsAt, _ = parseTimeValue(endsAt)
+	return window, nil
+}
+
+func scanMaintenanceWindows(rows *sql.Rows) ([]models.MaintenanceWindow, error) {
+	var windows []models.MaintenanceWindow
+	for rows.Next() {
+		window, err := scanMaintenanceWindow(rows)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"learn/internal/models"
+)
+
+type SQLiteMonitorEventRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteMonitorEventRepository(db *sql.DB) *SQLiteMonitorEventRepository {
+	return &SQLiteMonitorEventRepository{db: db}
+}
+
+func (r *SQLiteMonitorEventRepository) Append(ctx context.Context, event models.MonitorEvent, createdAt time.Time) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+INSERT INTO monitor_events (type, monitor_id, user_id, organization_id, data, created_at)
+VALUES (?, ?, ?, ?, ?, ?)
+`, event.Type, event.MonitorID, event.UserID, nullString(event.OrganizationID), string(data), formatTime(createdAt))
+	return err
+}
+
+// ListAfter returns events in ID order. Their Data is the stored JSON.
+func (r *SQLiteMonitorEventRepository) ListAfter(ctx context.Context, afterID uint64, limit int) ([]models.MonitorEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT id, type, monitor_id, user_id, COALESCE(organization_id, ''), data
+FROM monitor_events
+WHERE id > ?
+ORDER BY id ASC
+LIMIT ?
+`, int64(afterID), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.MonitorEvent
+	for rows.Next() {
+		var event models.MonitorEvent
+		var id int64
+		var data string
+		if err := rows.Scan(&id, &event.Type, &event.MonitorID, &event.UserID, &event.OrganizationID, &data); err != nil {
+			return nil, err
+		}
+		event.ID = uint64(id)
+		event.Data = json.RawMessage(data)
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// Bounds returns the oldest stored event ID and the latest ID ever assigned,
+// which survives the events themselves being deleted. With no events stored,
+// oldest is latest+1.
+func (r *SQLiteMonitorEventRepository) Bounds(ctx context.Context) (uint64, uint64, error) {
+	var latest int64
+	err := r.db.QueryRowContext(ctx, "SELECT seq FROM sqlite_sequence WHERE name = 'monitor_events'").Scan(&latest)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, err
+	}
+
+	var oldest sql.NullInt64
+	if err := r.db.QueryRowContext(ctx, "SELECT MIN(id) FROM monitor_events").Scan(&oldest); err != nil {
+		return 0, 0, err
+	}
+	if !oldest.Valid {
+		return uint64(latest) + 1, uint64(latest), nil
+	}
+	return uint64(oldest.Int64), uint64(latest), nil
+}
+
+func (r *SQLiteMonitorEventRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM monitor_events WHERE created_at < ?", formatTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -1,26 +1,84 @@
 package repository
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
 	"time"
 
 	"learn/internal/models"
+	"learn/pkg/secretbox"
 )
 
+// Monitors are visible to their creator when personal, and to every member of
+// the owning organization otherwise. Viewers may read but not modify.
+const (
+	monitorReadScope  = `((organization_id IS NULL AND user_id = ?) OR organization_id IN (SELECT organization_id FROM organization_members WHERE user_id = ?))`
+	monitorWriteScope = `((organization_id IS NULL AND user_id = ?) OR organization_id IN (SELECT organization_id FROM organization_members WHERE user_id = ? AND role IN ('owner', 'admin', 'member')))`
+)
+
+const monitorColumns = `m.id, m.user_id, COALESCE(m.organization_id, ''), m.name, m.type, m.url, m.interval_seconds,
+	m.method, m.headers, m.body, m.accepted_statuses, m.follow_redirects, m.assertions, m.max_response_bytes, m.type_config,
+	COALESCE(m.ping_token, ''), m.grace_seconds, m.last_ping_at, m.ping_started_at, m.retries, m.retry_delay_ms,
+	m.failure_threshold, m.recovery_threshold, m.sla_target, m.locations, m.quorum, m.is_active, m.created_at, m.status, m.consecutive_failures,
+	m.consecutive_successes, m.status_changed_at, COALESCE(m.escalation_policy_id, ''), m.next_check_at,
+	m.state_counted_at`
+
+const monitorLogColumns = `ml.id, ml.monitor_id, ml.status, ml.status_code, ml.response_time_ms, COALESCE(ml.error_message, ''),
+	COALESCE(ml.details, ''), ml.dns_ms, ml.connect_ms, ml.tls_ms, ml.ttfb_ms, ml.transfer_ms, ml.location, ml.manual, ml.checked_at`
+
 type SQLiteMonitorRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	secrets *secretbox.Box
+}
+
+type monitorTypeConfig struct {
+	DNS *models.DNSCheckConfig `json:"dns,omitempty"`
+	TLS *models.TLSCheckConfig `json:"tls,omitempty"`
+}
+
+// storedMonitorHeader keeps secret values in their encrypted form.
+type storedMonitorHeader struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Secret bool   `json:"secret"`
 }
 
-func NewSQLiteMonitorRepository(db *sql.DB) *SQLiteMonitorRepository {
-	return &SQLiteMonitorRepository{db: db}
+func NewSQLiteMonitorRepository(db *sql.DB, secrets *secretbox.Box) *SQLiteMonitorRepository {
+	return &SQLiteMonitorRepository{db: db, secrets: secrets}
 }
 
 func (r *SQLiteMonitorRepository) Create(ctx context.Context, monitor models.Monitor) (models.Monitor, error) {
-	_, err := r.db.ExecContext(ctx, `
-INSERT INTO monitors (id, user_id, name, url, interval_seconds, is_active)
-VALUES (?, ?, ?, ?, ?, ?)
-`, monitor.ID, monitor.UserID, monitor.Name, monitor.URL, monitor.IntervalSeconds, boolToInt(monitor.IsActive))
+	headers, err := r.encodeHeaders(monitor.Headers)
+	if err != nil {
+		return models.Monitor{}, err
+	}
+	assertions, err := json.Marshal(monitor.Assertions)
+	if err != nil {
+		return models.Monitor{}, err
+	}
+	typeConfig, err := json.Marshal(monitorTypeConfig{DNS: monitor.DNS, TLS: monitor.TLS})
+	if err != nil {
+		return models.Monitor{}, err
+	}
+	locations, err := json.Marshal(monitor.Locations)
+	if err != nil {
+		return models.Monitor{}, err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+INSERT INTO monitors (id, user_id, organization_id, name, type, url, interval_seconds, method, headers, body, accepted_statuses, follow_redirects,
+	assertions, max_response_bytes, type_config, ping_token, grace_seconds, retries, retry_delay_ms, failure_threshold, recovery_threshold,
+	sla_target, locations, quorum, is_active)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, monitor.ID, monitor.UserID, nullString(monitor.OrganizationID), monitor.Name, monitor.Type, monitor.URL, monitor.IntervalSeconds,
+		monitor.Method, headers, monitor.Body, monitor.AcceptedStatuses, boolToInt(monitor.FollowRedirects),
+		string(assertions), monitor.MaxResponseBytes, string(typeConfig), nullString(monitor.PingToken), monitor.GraceSeconds,
+		monitor.Retries, monitor.RetryDelayMs, monitor.FailureThreshold, monitor.RecoveryThreshold, monitor.SLATarget,
+		string(locations), monitor.Quorum, boolToInt(monitor.IsActive))
 	if err != nil {
 		return models.Monitor{}, err
 	}
@@ -30,12 +88,11 @@ VALUES (?, ?, ?, ?, ?, ?)
 
 func (r *SQLiteMonitorRepository) ListByUser(ctx context.Context, userID string) ([]models.Monitor, error) {
 	rows, err := r.db.QueryContext(ctx, `
-SELECT m.id, m.user_id, m.name, m.url, m.interval_seconds, m.is_active, m.created_at,
-	COALESCE((SELECT status FROM monitor_logs WHERE monitor_id = m.id ORDER BY checked_at DESC LIMIT 1), 'pending') as last_status
+SELECT `+monitorColumns+`
 FROM monitors m
-WHERE m.user_id = ?
+WHERE `+monitorReadScope+`
 ORDER BY m.created_at DESC
-`, userID)
+`, userID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -43,12 +100,10 @@ ORDER BY m.created_at DESC
 
 	var monitors []models.Monitor
 	for rows.Next() {
-		var monitor models.Monitor
-		var isActive int
-		if err := rows.Scan(&monitor.ID, &monitor.UserID, &monitor.Name, &monitor.URL, &monitor.IntervalSeconds, &isActive, &monitor.CreatedAt, &monitor.LastStatus); err != nil {
+		monitor, err := r.scanMonitor(rows)
+		if err != nil {
 			return nil, err
 		}
-		monitor.IsActive = isActive == 1
 		monitors = append(monitors, monitor)
 	}
 	if err := rows.Err(); err != nil {
@@ -60,25 +115,72 @@ ORDER BY m.created_at DESC
 
 func (r *SQLiteMonitorRepository) GetByID(ctx context.Context, userID, id string) (models.Monitor, error) {
 	row := r.db.QueryRowContext(ctx, `
-SELECT id, user_id, name, url, interval_seconds, is_active, created_at
-FROM monitors
-WHERE id = ? AND user_id = ?
-`, id, userID)
+SELECT `+monitorColumns+`
+FROM monitors m
+WHERE m.id = ? AND `+monitorReadScope+`
+`, id, userID, userID)
 
-	var monitor models.Monitor
-	var isActive int
-	if err := row.Scan(&monitor.ID, &monitor.UserID, &monitor.Name, &monitor.URL, &monitor.IntervalSeconds, &isActive, &monitor.CreatedAt); err != nil {
-		return models.Monitor{}, err
-	}
-	monitor.IsActive = isActive == 1
+	return r.scanMonitor(row)
+}
 
-	return monitor, nil
+func (r *SQLiteMonitorRepository) GetByIDUnscoped(ctx context.Context, id string) (models.Monitor, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT `+monitorColumns+`
+FROM monitors m
+WHERE m.id = ?
+`, id)
+
+	return r.scanMonitor(row)
 }
 
-func (r *SQLiteMonitorRepository) Delete(ctx context.Context, userID, id string) (bool, error) {
+func (r *SQLiteMonitorRepository) GetByPingToken(ctx context.Context, token string) (models.Monitor, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT `+monitorColumns+`
+FROM monitors m
+WHERE m.ping_token = ?
+`, token)
+
+	return r.scanMonitor(row)
+}
+
+func (r *SQLiteMonitorRepository) RecordPingStart(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE monitors SET ping_started_at = ? WHERE id = ?`, formatTime(at), id)
+	return err
+}
+
+func (r *SQLiteMonitorRepository) RecordPing(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE monitors SET last_ping_at = ?, ping_started_at = NULL WHERE id = ?`, formatTime(at), id)
+	return err
+}
+
+func (r *SQLiteMonitorRepository) Update(ctx context.Context, userID string, monitor models.Monitor) (bool, error) {
+	headers, err := r.encodeHeaders(monitor.Headers)
+	if err != nil {
+		return false, err
+	}
+	assertions, err := json.Marshal(monitor.Assertions)
+	if err != nil {
+		return false, err
+	}
+	typeConfig, err := json.Marshal(monitorTypeConfig{DNS: monitor.DNS, TLS: monitor.TLS})
+	if err != nil {
+		return false, err
+	}
+	locations, err := json.Marshal(monitor.Locations)
+	if err != nil {
+		return false, err
+	}
+
 	result, err := r.db.ExecContext(ctx, `
-DELETE FROM monitors WHERE id = ? AND user_id = ?
-`, id, userID)
+UPDATE monitors SET name = ?, type = ?, url = ?, interval_seconds = ?, method = ?, headers = ?, body = ?, accepted_statuses = ?,
+	follow_redirects = ?, assertions = ?, max_response_bytes = ?, type_config = ?, ping_token = ?, grace_seconds = ?, retries = ?,
+	retry_delay_ms = ?, failure_threshold = ?, recovery_threshold = ?, sla_target = ?, locations = ?, quorum = ?,
+	next_check_at = CASE WHEN interval_seconds = ? THEN next_check_at END
+WHERE id = ? AND `+monitorWriteScope+`
+`, monitor.Name, monitor.Type, monitor.URL, monitor.IntervalSeconds, monitor.Method, headers, monitor.Body, monitor.AcceptedStatuses,
+		boolToInt(monitor.FollowRedirects), string(assertions), monitor.MaxResponseBytes, string(typeConfig),
+		nullString(monitor.PingToken), monitor.GraceSeconds, monitor.Retries, monitor.RetryDelayMs, monitor.FailureThreshold,
+		monitor.RecoveryThreshold, monitor.SLATarget, string(locations), monitor.Quorum, monitor.IntervalSeconds, monitor.ID, userID, userID)
 	if err != nil {
 		return false, err
 	}
@@ -89,10 +191,14 @@ DELETE FROM monitors WHERE id = ? AND user_id = ?
 	return rows > 0, nil
 }
 
-func (r *SQLiteMonitorRepository) Toggle(ctx context.Context, userID, id string) (bool, error) {
+// UpdateState moves a monitor from one state to the next. It reports false
+// without changing anything if the stored state is no longer from.
+func (r *SQLiteMonitorRepository) UpdateState(ctx context.Context, id string, from, to models.MonitorState) (bool, error) {
 	result, err := r.db.ExecContext(ctx, `
-UPDATE monitors SET is_active = NOT is_active WHERE id = ? AND user_id = ?
-`, id, userID)
+UPDATE monitors SET status = ?, consecutive_failures = ?, consecutive_successes = ?, status_changed_at = ?, state_counted_at = ?
+WHERE id = ? AND status = ? AND consecutive_failures = ? AND consecutive_successes = ?
+`, to.Status, to.ConsecutiveFailures, to.ConsecutiveSuccesses, nullTime(to.StatusChangedAt), nullTime(to.CountedAt),
+		id, from.Status, from.ConsecutiveFailures, from.ConsecutiveSuccesses)
 	if err != nil {
 		return false, err
 	}
@@ -103,12 +209,120 @@ UPDATE monitors SET is_active = NOT is_active WHERE id = ? AND user_id = ?
 	return rows > 0, nil
 }
 
+func (r *SQLiteMonitorRepository) SetEscalationPolicy(ctx context.Context, id, policyID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE monitors SET escalation_policy_id = ? WHERE id = ?`, nullString(policyID), id)
+	return err
+}
+
+// Delete removes the monitor along with its logs, incidents and everything
+// else kept per monitor. SQLite doesn't enforce foreign keys here, so the
+// cascades in the schema don't do it.
+func (r *SQLiteMonitorRepository) Delete(ctx context.Context, userID, id string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+DELETE FROM monitors WHERE id = ? AND `+monitorWriteScope+`
+`, id, userID, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil || rows == 0 {
+		return false, err
+	}
+
+	for _, table := range []string{
+		"monitor_logs",
+		"incidents",
+		"monitor_notification_channels",
+		"maintenance_window_monitors",
+		"monitor_pauses",
+		"monitor_rollups_hourly",
+		"monitor_rollups_daily",
+	} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE monitor_id = ?", id); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+// Toggle pauses or resumes a monitor and records when, so paused time can be
+// left out of its uptime.
+func (r *SQLiteMonitorRepository) Toggle(ctx context.Context, userID, id string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var isActive int
+	if err := tx.QueryRowContext(ctx, `
+UPDATE monitors SET is_active = NOT is_active WHERE id = ? AND `+monitorWriteScope+`
+RETURNING is_active
+`, id, userID, userID).Scan(&isActive); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	now := formatTime(time.Now())
+	if isActive == 0 {
+		_, err = tx.ExecContext(ctx, "INSERT INTO monitor_pauses (monitor_id, started_at) VALUES (?, ?)", id, now)
+	} else {
+		_, err = tx.ExecContext(ctx, "UPDATE monitor_pauses SET ended_at = ? WHERE monitor_id = ? AND ended_at IS NULL", now, id)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ListPauses returns the periods the monitor was paused that overlap
+// [from, to). A pause that hasn't ended yet ends at to.
+func (r *SQLiteMonitorRepository) ListPauses(ctx context.Context, monitorID string, from, to time.Time) ([]models.TimeRange, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT started_at, ended_at FROM monitor_pauses
+WHERE monitor_id = ? AND started_at < ? AND (ended_at IS NULL OR ended_at > ?)
+ORDER BY started_at ASC
+`, monitorID, formatTime(to), formatTime(from))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pauses []models.TimeRange
+	for rows.Next() {
+		var startedAt, endedAt any
+		if err := rows.Scan(&startedAt, &endedAt); err != nil {
+			return nil, err
+		}
+		pause := models.TimeRange{End: to}
+		pause.Start, _ = parseTimeValue(startedAt)
+		if parsed, ok := parseTimeValue(endedAt); ok {
+			pause.End = parsed
+		}
+		pauses = append(pauses, pause)
+	}
+	return pauses, rows.Err()
+}
+
 func (r *SQLiteMonitorRepository) ListLogs(ctx context.Context, monitorID string, limit int) ([]models.MonitorLog, error) {
 	rows, err := r.db.QueryContext(ctx, `
-SELECT id, monitor_id, status, status_code, response_time_ms, COALESCE(error_message, ''), checked_at
-FROM monitor_logs
-WHERE monitor_id = ?
-ORDER BY checked_at DESC
+SELECT `+monitorLogColumns+`
+FROM monitor_logs ml
+WHERE ml.monitor_id = ?
+ORDER BY ml.checked_at DESC
 LIMIT ?
 `, monitorID, limit)
 	if err != nil {
@@ -116,10 +330,214 @@ LIMIT ?
 	}
 	defer rows.Close()
 
+	return scanMonitorLogs(rows)
+}
+
+func (r *SQLiteMonitorRepository) ListAllLogs(ctx context.Context, monitorID string) ([]models.MonitorLog, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+monitorLogColumns+`
+FROM monitor_logs ml
+WHERE ml.monitor_id = ?
+ORDER BY ml.checked_at ASC
+`, monitorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMonitorLogs(rows)
+}
+
+func (r *SQLiteMonitorRepository) ListLogsSince(ctx context.Context, monitorID string, since time.Time) ([]models.MonitorLog, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+monitorLogColumns+`
+FROM monitor_logs ml
+WHERE ml.monitor_id = ? AND ml.checked_at >= ?
+ORDER BY ml.checked_at ASC
+`, monitorID, formatTime(since))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMonitorLogs(rows)
+}
+
+func (r *SQLiteMonitorRepository) ListLogsBetween(ctx context.Context, monitorID string, from, to time.Time) ([]models.MonitorLog, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+monitorLogColumns+`
+FROM monitor_logs ml
+WHERE ml.monitor_id = ? AND ml.checked_at >= ? AND ml.checked_at < ?
+ORDER BY ml.checked_at ASC
+`, monitorID, formatTime(from), formatTime(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMonitorLogs(rows)
+}
+
+func (r *SQLiteMonitorRepository) ListLoggedMonitorIDs(ctx context.Context, from, to time.Time) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT DISTINCT monitor_id FROM monitor_logs WHERE checked_at >= ? AND checked_at < ?
+`, formatTime(from), formatTime(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *SQLiteMonitorRepository) DeleteLogsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM monitor_logs WHERE checked_at < ?`, formatTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *SQLiteMonitorRepository) scanMonitor(row rowScanner, extra ...any) (models.Monitor, error) {
+	var monitor models.Monitor
+	var headers, assertions, typeConfig, locations string
+	var lastPingAt, pingStartedAt, statusChangedAt, nextCheckAt, stateCountedAt any
+	var followRedirects, isActive int
+	dest := []any{&monitor.ID, &monitor.UserID, &monitor.OrganizationID, &monitor.Name, &monitor.Type, &monitor.URL, &monitor.IntervalSeconds,
+		&monitor.Method, &headers, &monitor.Body, &monitor.AcceptedStatuses, &followRedirects, &assertions, &monitor.MaxResponseBytes,
+		&typeConfig, &monitor.PingToken, &monitor.GraceSeconds, &lastPingAt, &pingStartedAt, &monitor.Retries, &monitor.RetryDelayMs,
+		&monitor.FailureThreshold, &monitor.RecoveryThreshold, &monitor.SLATarget, &locations, &monitor.Quorum, &isActive, &monitor.CreatedAt, &monitor.Status,
+		&monitor.ConsecutiveFailures, &monitor.ConsecutiveSuccesses, &statusChangedAt, &monitor.EscalationPolicyID, &nextCheckAt,
+		&stateCountedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return models.Monitor{}, err
+	}
+	monitor.FollowRedirects = followRedirects == 1
+	monitor.IsActive = isActive == 1
+	monitor.LastStatus = monitor.Status
+	if parsed, ok := parseTimeValue(lastPingAt); ok {
+		monitor.LastPingAt = &parsed
+	}
+	if parsed, ok := parseTimeValue(pingStartedAt); ok {
+		monitor.PingStartedAt = &parsed
+	}
+	if parsed, ok := parseTimeValue(statusChangedAt); ok {
+		monitor.StatusChangedAt = &parsed
+	}
+	if parsed, ok := parseTimeValue(nextCheckAt); ok {
+		monitor.NextCheckAt = &parsed
+	}
+	if parsed, ok := parseTimeValue(stateCountedAt); ok {
+		monitor.CountedAt = &parsed
+	}
+
+	decoded, err := r.decodeHeaders(monitor.ID, headers)
+	if err != nil {
+		return models.Monitor{}, err
+	}
+	monitor.Headers = decoded
+
+	if err := json.Unmarshal([]byte(assertions), &monitor.Assertions); err != nil {
+		return models.Monitor{}, err
+	}
+	if err := json.Unmarshal([]byte(locations), &monitor.Locations); err != nil {
+		return models.Monitor{}, err
+	}
+
+	var config monitorTypeConfig
+	if err := json.Unmarshal([]byte(typeConfig), &config); err != nil {
+		return models.Monitor{}, err
+	}
+	monitor.DNS, monitor.TLS = config.DNS, config.TLS
+	return monitor, nil
+}
+
+func (r *SQLiteMonitorRepository) encodeHeaders(headers []models.MonitorHeader) (string, error) {
+	stored := make([]storedMonitorHeader, 0, len(headers))
+	for _, header := range headers {
+		value := header.Value
+		if header.Secret && !header.Unreadable {
+			sealed, err := r.secrets.Seal(value)
+			if err != nil {
+				return "", err
+			}
+			value = sealed
+		}
+		stored = append(stored, storedMonitorHeader{Name: header.Name, Value: value, Secret: header.Secret})
+	}
+	encoded, err := json.Marshal(stored)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// decodeHeaders decrypts secret header values. One that can't be decrypted
+// is flagged rather than failing the whole monitor, so a changed key only
+// affects the headers sealed with the old one.
+func (r *SQLiteMonitorRepository) decodeHeaders(monitorID, raw string) ([]models.MonitorHeader, error) {
+	var stored []storedMonitorHeader
+	if err := json.Unmarshal([]byte(raw), &stored); err != nil {
+		return nil, err
+	}
+	headers := make([]models.MonitorHeader, 0, len(stored))
+	for _, header := range stored {
+		decoded := models.MonitorHeader{Name: header.Name, Value: header.Value, Secret: header.Secret}
+		if header.Secret {
+			opened, err := r.secrets.Open(header.Value)
+			if err != nil {
+				log.Printf("Error decrypting header %s of monitor %s: %v", header.Name, monitorID, err)
+				decoded.Unreadable = true
+			} else {
+				decoded.Value = opened
+			}
+		}
+		headers = append(headers, decoded)
+	}
+	return headers, nil
+}
+
+func scanMonitorLog(row rowScanner, extra ...any) (models.MonitorLog, error) {
+	var log models.MonitorLog
+	var details string
+	var dnsMs, connectMs, tlsMs, ttfbMs, transferMs sql.NullInt64
+	var manual int
+	dest := []any{&log.ID, &log.MonitorID, &log.Status, &log.StatusCode, &log.ResponseTimeMs, &log.ErrorMessage, &details,
+		&dnsMs, &connectMs, &tlsMs, &ttfbMs, &transferMs, &log.Location, &manual, &log.CheckedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return models.MonitorLog{}, err
+	}
+	log.Manual = manual == 1
+	if ttfbMs.Valid {
+		log.Timings = &models.CheckTimings{
+			DNSMs:      dnsMs.Int64,
+			ConnectMs:  connectMs.Int64,
+			TLSMs:      tlsMs.Int64,
+			TTFBMs:     ttfbMs.Int64,
+			TransferMs: transferMs.Int64,
+		}
+	}
+	if details != "" {
+		if err := json.Unmarshal([]byte(details), &log.Details); err != nil {
+			return models.MonitorLog{}, err
+		}
+	}
+	return log, nil
+}
+
+func scanMonitorLogs(rows *sql.Rows) ([]models.MonitorLog, error) {
 	var logs []models.MonitorLog
 	for rows.Next() {
-		var log models.MonitorLog
-		if err := rows.Scan(&log.ID, &log.MonitorID, &log.Status, &log.StatusCode, &log.ResponseTimeMs, &log.ErrorMessage, &log.CheckedAt); err != nil {
+		log, err := scanMonitorLog(rows)
+		if err != nil {
 			return nil, err
 		}
 		logs = append(logs, log)
@@ -133,14 +551,13 @@ LIMIT ?
 
 func (r *SQLiteMonitorRepository) ListRecentLogs(ctx context.Context, userID string, limit int) ([]models.RecentMonitorLog, error) {
 	rows, err := r.db.QueryContext(ctx, `
-SELECT ml.id, ml.monitor_id, ml.status, ml.status_code, ml.response_time_ms,
-	COALESCE(ml.error_message, ''), ml.checked_at, m.name, m.url
+SELECT `+monitorLogColumns+`, m.name, m.url
 FROM monitor_logs ml
 JOIN monitors m ON ml.monitor_id = m.id
-WHERE m.user_id = ?
+WHERE `+monitorReadScope+`
 ORDER BY ml.checked_at DESC
 LIMIT ?
-`, userID, limit)
+`, userID, userID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -149,9 +566,11 @@ LIMIT ?
 	var logs []models.RecentMonitorLog
 	for rows.Next() {
 		var log models.RecentMonitorLog
-		if err := rows.Scan(&log.ID, &log.MonitorID, &log.Status, &log.StatusCode, &log.ResponseTimeMs, &log.ErrorMessage, &log.CheckedAt, &log.MonitorName, &log.MonitorURL); err != nil {
+		entry, err := scanMonitorLog(rows, &log.MonitorName, &log.MonitorURL)
+		if err != nil {
 			return nil, err
 		}
+		log.MonitorLog = entry
 		logs = append(logs, log)
 	}
 	if err := rows.Err(); err != nil {
@@ -164,50 +583,50 @@ LIMIT ?
 func (r *SQLiteMonitorRepository) CountStats(ctx context.Context, userID string) (models.MonitorStats, error) {
 	var stats models.MonitorStats
 
-	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM monitors WHERE user_id = ?", userID).Scan(&stats.Total); err != nil {
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM monitors WHERE "+monitorReadScope, userID, userID).Scan(&stats.Total); err != nil {
 		return models.MonitorStats{}, err
 	}
 
-	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM monitors WHERE user_id = ? AND is_active = 1", userID).Scan(&stats.Active); err != nil {
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM monitors WHERE is_active = 1 AND "+monitorReadScope, userID, userID).Scan(&stats.Active); err != nil {
 		return models.MonitorStats{}, err
 	}
 
 	if err := r.db.QueryRowContext(ctx, `
-SELECT COUNT(DISTINCT m.id) FROM monitors m
-JOIN monitor_logs ml ON m.id = ml.monitor_id
-WHERE m.user_id = ? AND ml.status = 'up'
-AND ml.checked_at = (SELECT MAX(checked_at) FROM monitor_logs WHERE monitor_id = m.id)
-`, userID).Scan(&stats.Up); err != nil {
+SELECT COUNT(*) FROM monitors WHERE status = 'up' AND `+monitorReadScope+`
+`, userID, userID).Scan(&stats.Up); err != nil {
 		return models.MonitorStats{}, err
 	}
 
 	if err := r.db.QueryRowContext(ctx, `
-SELECT COUNT(DISTINCT m.id) FROM monitors m
-JOIN monitor_logs ml ON m.id = ml.monitor_id
-WHERE m.user_id = ? AND ml.status = 'down'
-AND ml.checked_at = (SELECT MAX(checked_at) FROM monitor_logs WHERE monitor_id = m.id)
-`, userID).Scan(&stats.Down); err != nil {
+SELECT COUNT(*) FROM monitors WHERE status = 'down' AND `+monitorReadScope+`
+`, userID, userID).Scan(&stats.Down); err != nil {
+		return models.MonitorStats{}, err
+	}
+
+	if err := r.db.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM monitors WHERE status = 'degraded' AND `+monitorReadScope+`
+`, userID, userID).Scan(&stats.Degraded); err != nil {
 		return models.MonitorStats{}, err
 	}
 
 	return stats, nil
 }
 
-func (r *SQLiteMonitorRepository) ListActive(ctx context.Context) ([]models.MonitorCheck, error) {
+func (r *SQLiteMonitorRepository) ListActiveByLocation(ctx context.Context, location string) ([]models.Monitor, error) {
 	rows, err := r.db.QueryContext(ctx, `
-SELECT id, url, interval_seconds
-FROM monitors
-WHERE is_active = 1
-`)
+SELECT `+monitorColumns+`
+FROM monitors m
+WHERE m.is_active = 1 AND EXISTS (SELECT 1 FROM json_each(m.locations) WHERE json_each.value = ?)
+`, location)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var monitors []models.MonitorCheck
+	var monitors []models.Monitor
 	for rows.Next() {
-		var monitor models.MonitorCheck
-		if err := rows.Scan(&monitor.ID, &monitor.URL, &monitor.IntervalSeconds); err != nil {
+		monitor, err := r.scanMonitor(rows)
+		if err != nil {
 			return nil, err
 		}
 		monitors = append(monitors, monitor)
@@ -219,22 +638,93 @@ WHERE is_active = 1
 	return monitors, nil
 }
 
-func (r *SQLiteMonitorRepository) GetLastCheck(ctx context.Context, monitorID string) (time.Time, error) {
+// GetOldestCheck returns when the oldest log of any monitor was recorded.
+func (r *SQLiteMonitorRepository) GetOldestCheck(ctx context.Context) (time.Time, error) {
+	var checkedAt any
+	if err := r.db.QueryRowContext(ctx, `SELECT MIN(checked_at) FROM monitor_logs`).Scan(&checkedAt); err != nil {
+		return time.Time{}, err
+	}
+	parsed, ok := parseTimeValue(checkedAt)
+	if !ok {
+		return time.Time{}, sql.ErrNoRows
+	}
+	return parsed.UTC(), nil
+}
+
+// GetFirstCheck also looks at rollups, since the raw logs of the first checks
+// may have been compacted away.
+func (r *SQLiteMonitorRepository) GetFirstCheck(ctx context.Context, monitorID string) (time.Time, error) {
 	row := r.db.QueryRowContext(ctx, `
-SELECT checked_at FROM monitor_logs WHERE monitor_id = ? ORDER BY checked_at DESC LIMIT 1
-`, monitorID)
-	var checkedAt time.Time
-	if err := row.Scan(&checkedAt); err != nil {
+SELECT MIN(first_checked_at) FROM (
+	SELECT MIN(checked_at) AS first_checked_at FROM monitor_logs WHERE monitor_id = ?
+	UNION ALL SELECT MIN(first_checked_at) FROM monitor_rollups_hourly WHERE monitor_id = ?
+	UNION ALL SELECT MIN(first_checked_at) FROM monitor_rollups_daily WHERE monitor_id = ?
+)
+`, monitorID, monitorID, monitorID)
+	var firstCheckedAt any
+	if err := row.Scan(&firstCheckedAt); err != nil {
 		return time.Time{}, err
 	}
-	return checkedAt, nil
+	parsed, ok := parseTimeValue(firstCheckedAt)
+	if !ok {
+		return time.Time{}, sql.ErrNoRows
+	}
+	return parsed, nil
+}
+
+// ListLastChecks returns when each monitor that has logs was last checked.
+func (r *SQLiteMonitorRepository) ListLastChecks(ctx context.Context) (map[string]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT monitor_id, MAX(checked_at) FROM monitor_logs GROUP BY monitor_id
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lastChecks := make(map[string]time.Time)
+	for rows.Next() {
+		var monitorID string
+		var checkedAt any
+		if err := rows.Scan(&monitorID, &checkedAt); err != nil {
+			return nil, err
+		}
+		if parsed, ok := parseTimeValue(checkedAt); ok {
+			lastChecks[monitorID] = parsed.UTC()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return lastChecks, nil
 }
 
 func (r *SQLiteMonitorRepository) CreateLog(ctx context.Context, log models.MonitorLog) error {
+	var details sql.NullString
+	if len(log.Details) > 0 {
+		encoded, err := json.Marshal(log.Details)
+		if err != nil {
+			return err
+		}
+		details = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	var dnsMs, connectMs, tlsMs, ttfbMs, transferMs sql.NullInt64
+	if log.Timings != nil {
+		dnsMs = sql.NullInt64{Int64: log.Timings.DNSMs, Valid: true}
+		connectMs = sql.NullInt64{Int64: log.Timings.ConnectMs, Valid: true}
+		tlsMs = sql.NullInt64{Int64: log.Timings.TLSMs, Valid: true}
+		ttfbMs = sql.NullInt64{Int64: log.Timings.TTFBMs, Valid: true}
+		transferMs = sql.NullInt64{Int64: log.Timings.TransferMs, Valid: true}
+	}
+
 	_, err := r.db.ExecContext(ctx, `
-INSERT INTO monitor_logs (id, monitor_id, status, status_code, response_time_ms, error_message)
-VALUES (?, ?, ?, ?, ?, ?)
-`, log.ID, log.MonitorID, log.Status, log.StatusCode, log.ResponseTimeMs, log.ErrorMessage)
+INSERT INTO monitor_logs (id, monitor_id, status, status_code, response_time_ms, error_message, details,
+	dns_ms, connect_ms, tls_ms, ttfb_ms, transfer_ms, location, manual)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, log.ID, log.MonitorID, log.Status, log.StatusCode, log.ResponseTimeMs, log.ErrorMessage, details,
+		dnsMs, connectMs, tlsMs, ttfbMs, transferMs, cmp.Or(log.Location, models.LocationLocal), boolToInt(log.Manual))
 	return err
 }
 
@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"learn/internal/models"
+	"learn/pkg/secretbox"
+)
+
+const notificationChannelColumns = `c.id, c.user_id, c.name, c.type, c.config, c.template, c.created_at`
+
+const notificationDeliveryColumns = `d.id, d.channel_id, d.recipient, COALESCE(d.monitor_id, ''), COALESCE(d.incident_id, ''), d.event,
+	d.subject, d.message, d.payload, d.status, d.attempts, d.response_code, d.last_error, d.next_attempt_at, d.delivered_at, d.created_at`
+
+type SQLiteNotificationRepository struct {
+	db      *sql.DB
+	secrets *secretbox.Box
+}
+
+// channelConfig is stored encrypted since webhook URLs usually embed a token.
+type channelConfig struct {
+	URL    string `json:"url,omitempty"`
+	Email  string `json:"email,omitempty"`
+	Secret string `json:"secret,omitempty"`
+}
+
+func NewSQLiteNotificationRepository(db *sql.DB, secrets *secretbox.Box) *SQLiteNotificationRepository {
+	return &SQLiteNotificationRepository{db: db, secrets: secrets}
+}
+
+func (r *SQLiteNotificationRepository) CreateChannel(ctx context.Context, channel models.NotificationChannel) (models.NotificationChannel, error) {
+	encoded, err := json.Marshal(channelConfig{URL: channel.URL, Email: channel.Email, Secret: channel.Secret})
+	if err != nil {
+		return models.NotificationChannel{}, err
+	}
+	config, err := r.secrets.Seal(string(encoded))
+	if err != nil {
+		return models.NotificationChannel{}, err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+INSERT INTO notification_channels (id, user_id, name, type, config, template)
+VALUES (?, ?, ?, ?, ?, ?)
+`, channel.ID, channel.UserID, channel.Name, channel.Type, config, channel.Template)
+	if err != nil {
+		return models.NotificationChannel{}, err
+	}
+
+	return r.GetChannel(ctx, channel.ID)
+}
+
+func (r *SQLiteNotificationRepository) ListChannels(ctx context.Context, userID string) ([]models.NotificationChannel, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+notificationChannelColumns+`
+FROM notification_channels c
+WHERE c.user_id = ?
+ORDER BY c.created_at DESC
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanChannels(rows)
+}
+
+func (r *SQLiteNotificationRepository) GetChannel(ctx context.Context, id string) (models.NotificationChannel, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT `+notificationChannelColumns+`
+FROM notification_channels c
+WHERE c.id = ?
+`, id)
+
+	return r.scanChannel(row)
+}
+
+func (r *SQLiteNotificationRepository) DeleteChannel(ctx context.Context, userID, id string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM notification_channels WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil || rows == 0 {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM monitor_notification_channels WHERE channel_id = ?`, id); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+UPDATE notification_deliveries SET status = ?, last_error = 'channel deleted', next_attempt_at = NULL
+WHERE channel_id = ? AND status = ?
+`, models.DeliveryStatusFailed, id, models.DeliveryStatusPending); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *SQLiteNotificationRepository) SetMonitorChannels(ctx context.Context, monitorID string, channelIDs []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM monitor_notification_channels WHERE monitor_id = ?`, monitorID); err != nil {
+		return err
+	}
+	for _, channelID := range channelIDs {
+		if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO monitor_notification_channels (monitor_id, channel_id)
+VALUES (?, ?)
+`, monitorID, channelID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteNotificationRepository) ListMonitorChannels(ctx context.Context, monitorID string) ([]models.NotificationChannel, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+notificationChannelColumns+`
+FROM notification_channels c
+JOIN monitor_notification_channels mc ON mc.channel_id = c.id
+WHERE mc.monitor_id = ?
+ORDER BY c.name ASC
+`, monitorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanChannels(rows)
+}
+
+func (r *SQLiteNotificationRepository) CreateDelivery(ctx context.Context, delivery models.NotificationDelivery) error {
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO notification_deliveries (id, channel_id, recipient, monitor_id, incident_id, event, subject, message, payload, status,
+	next_attempt_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, delivery.ID, delivery.ChannelID, delivery.Recipient, nullString(delivery.MonitorID), nullString(delivery.IncidentID), delivery.Event,
+		delivery.Subject, delivery.Message, delivery.Payload, delivery.Status, nullTime(delivery.NextAttemptAt))
+	return err
+}
+
+func (r *SQLiteNotificationRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.NotificationDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+notificationDeliveryColumns+`
+FROM notification_deliveries d
+WHERE d.status = ? AND d.next_attempt_at <= ?
+ORDER BY d.next_attempt_at ASC
+LIMIT ?
+`, models.DeliveryStatusPending, formatTime(now), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+// ClaimDelivery pushes a due delivery's next attempt to until, so no other
+// process picks it up while it is being sent. It reports false when the
+// delivery was already claimed or finished.
+func (r *SQLiteNotificationRepository) ClaimDelivery(ctx context.Context, id string, now, until time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+UPDATE notification_deliveries SET next_attempt_at = ?
+WHERE id = ? AND status = ? AND next_attempt_at <= ?
+`, formatTime(until), id, models.DeliveryStatusPending, formatTime(now))
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *SQLiteNotificationRepository) UpdateDelivery(ctx context.Context, delivery models.NotificationDelivery) error {
+	_, err := r.db.ExecContext(ctx, `
+UPDATE notification_deliveries SET status = ?, attempts = ?, response_code = ?, last_error = ?, next_attempt_at = ?, delivered_at = ?
+WHERE id = ?
+`, delivery.Status, delivery.Attempts, delivery.ResponseCode, delivery.LastError, nullTime(delivery.NextAttemptAt),
+		nullTime(delivery.DeliveredAt), delivery.ID)
+	return err
+}
+
+func (r *SQLiteNotificationRepository) ListDeliveries(ctx context.Context, channelID string, limit int) ([]models.NotificationDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+notificationDeliveryColumns+`
+FROM notification_deliveries d
+WHERE d.channel_id = ?
+ORDER BY d.created_at DESC
+LIMIT ?
+`, channelID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+func (r *SQLiteNotificationRepository) ListIncidentDeliveries(ctx context.Context, incidentID string, limit int) ([]models.NotificationDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+notificationDeliveryColumns+`
+FROM notification_deliveries d
+WHERE d.incident_id = ?
+ORDER BY d.created_at DESC
+LIMIT ?
+`, incidentID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+func (r *SQLiteNotificationRepository) scanChannel(row rowScanner) (models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	var sealed string
+	if err := row.Scan(&channel.ID, &channel.UserID, &channel.Name, &channel.Type, &sealed, &channel.Template, &channel.CreatedAt); err != nil {
+		return models.NotificationChannel{}, err
+	}
+
+	opened, err := r.secrets.Open(sealed)
+	if err != nil {
+		return models.NotificationChannel{}, err
+	}
+	var config channelConfig
+	if err := json.Unmarshal([]byte(opened), &config); err != nil {
+		return models.NotificationChannel{}, err
+	}
+	channel.URL, channel.Email, channel.Secret = config.URL, config.Email, config.Secret
+	channel.HasSecret = config.Secret != ""
+	return channel, nil
+}
+
+func (r *SQLiteNotificationRepository) scanChannels(rows *sql.Rows) ([]models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	for rows.Next() {
+		channel, err := r.scanChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+func scanDeliveries(rows *sql.Rows) ([]models.NotificationDelivery, error) {
+	var deliveries []models.NotificationDelivery
+	for rows.Next() {
+		var delivery models.NotificationDelivery
+		var nextAttemptAt, deliveredAt any
+		if err := rows.Scan(&delivery.ID, &delivery.ChannelID, &delivery.Recipient, &delivery.MonitorID, &delivery.IncidentID, &delivery.Event,
+			&delivery.Subject, &delivery.Message, &delivery.Payload, &delivery.Status, &delivery.Attempts, &delivery.ResponseCode, &delivery.LastError, &nextAttemptAt,
+			&deliveredAt, &delivery.CreatedAt); err != nil {
+			return nil, err
+		}
+		if parsed, ok := parseTimeValue(nextAttemptAt); ok {
+			delivery.NextAttemptAt = &parsed
+		}
+		if parsed, ok := parseTimeValue(deliveredAt); ok {
+			delivery.DeliveredAt = &parsed
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"learn/internal/models"
+)
+
+const onCallScheduleColumns = `id, user_id, COALESCE(organization_id, ''), name, rotation_start, rotation_days, participants, created_at`
+
+const escalationPolicyColumns = `id, user_id, COALESCE(organization_id, ''), name, steps, created_at`
+
+type SQLiteOnCallRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteOnCallRepository(db *sql.DB) *SQLiteOnCallRepository {
+	return &SQLiteOnCallRepository{db: db}
+}
+
+func (r *SQLiteOnCallRepository) CreateSchedule(ctx context.Context, schedule models.OnCallSchedule) (models.OnCallSchedule, error) {
+	participants, err := json.Marshal(schedule.Participants)
+	if err != nil {
+		return models.OnCallSchedule{}, err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+INSERT INTO oncall_schedules (id, user_id, organization_id, name, rotation_start, rotation_days, participants)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`, schedule.ID, schedule.UserID, nullString(schedule.OrganizationID), schedule.Name, formatTime(schedule.RotationStart),
+		schedule.RotationDays, string(participants))
+	if err != nil {
+		return models.OnCallSchedule{}, err
+	}
+
+	return r.GetSchedule(ctx, schedule.ID)
+}
+
+func (r *SQLiteOnCallRepository) ListSchedules(ctx context.Context, userID string) ([]models.OnCallSchedule, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+onCallScheduleColumns+`
+FROM oncall_schedules
+WHERE `+monitorReadScope+`
+ORDER BY created_at DESC
+`, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []models.OnCallSchedule
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range schedules {
+		if schedules[i].Overrides, err = r.listOverrides(ctx, schedules[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return schedules, nil
+}
+
+func (r *SQLiteOnCallRepository) GetSchedule(ctx context.Context, id string) (models.OnCallSchedule, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT `+onCallScheduleColumns+`
+FROM oncall_schedules
+WHERE id = ?
+`, id)
+
+	schedule, err := scanSchedule(row)
+	if err != nil {
+		return models.OnCallSchedule{}, err
+	}
+	if schedule.Overrides, err = r.listOverrides(ctx, id); err != nil {
+		return models.OnCallSchedule{}, err
+	}
+	return schedule, nil
+}
+
+func (r *SQLiteOnCallRepository) DeleteSchedule(ctx context.Context, userID, id string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+DELETE FROM oncall_schedules WHERE id = ? AND `+monitorWriteScope+`
+`, id, userID, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil || rows == 0 {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM oncall_overrides WHERE schedule_id = ?`, id); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *SQLiteOnCallRepository) CreateOverride(ctx context.Context, override models.OnCallOverride) error {
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO oncall_overrides (id, schedule_id, user_id, starts_at, ends_at, created_at)
+VALUES (?, ?, ?, ?, ?, ?)
+`, override.ID, override.ScheduleID, override.UserID, formatTime(override.StartsAt), formatTime(override.EndsAt),
+		formatTime(override.CreatedAt))
+	return err
+}
+
+func (r *SQLiteOnCallRepository) DeleteOverride(ctx context.Context, scheduleID, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM oncall_overrides WHERE id = ? AND schedule_id = ?`, id, scheduleID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *SQLiteOnCallRepository) CreatePolicy(ctx context.Context, policy models.EscalationPolicy) (models.EscalationPolicy, error) {
+	steps, err := json.Marshal(policy.Steps)
+	if err != nil {
+		return models.EscalationPolicy{}, err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+INSERT INTO escalation_policies (id, user_id, organization_id, name, steps)
+VALUES (?, ?, ?, ?, ?)
+`, policy.ID, policy.UserID, nullString(policy.OrganizationID), policy.Name, string(steps))
+	if err != nil {
+		return models.EscalationPolicy{}, err
+	}
+
+	return r.GetPolicy(ctx, policy.ID)
+}
+
+func (r *SQLiteOnCallRepository) ListPolicies(ctx context.Context, userID string) ([]models.EscalationPolicy, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+escalationPolicyColumns+`
+FROM escalation_policies
+WHERE `+monitorReadScope+`
+ORDER BY created_at DESC
+`, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []models.EscalationPolicy
+	for rows.Next() {
+		policy, err := scanPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *SQLiteOnCallRepository) GetPolicy(ctx context.Context, id string) (models.EscalationPolicy, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT `+escalationPolicyColumns+`
+FROM escalation_policies
+WHERE id = ?
+`, id)
+
+	return scanPolicy(row)
+}
+
+// DeletePolicy also detaches the policy from monitors so new incidents stop
+// escalating; open incidents keep the steps they already reached.
+func (r *SQLiteOnCallRepository) DeletePolicy(ctx context.Context, userID, id string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+DELETE FROM escalation_policies WHERE id = ? AND `+monitorWriteScope+`
+`, id, userID, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil || rows == 0 {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE monitors SET escalation_policy_id = NULL WHERE escalation_policy_id = ?`, id); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+UPDATE incidents SET next_escalation_at = NULL WHERE escalation_policy_id = ? AND status = ?
+`, id, models.IncidentStatusOpen); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *SQLiteOnCallRepository) listOverrides(ctx context.Context, scheduleID string) ([]models.OnCallOverride, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT id, schedule_id, user_id, starts_at, ends_at, created_at
+FROM oncall_overrides
+WHERE schedule_id = ?
+ORDER BY starts_at ASC
+`, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := []models.OnCallOverride{}
+	for rows.Next() {
+		var override models.OnCallOverride
+		var startsAt, endsAt, createdAt any
+		if err := rows.Scan(&override.ID, &override.ScheduleID, &override.UserID, &startsAt, &endsAt, &createdAt); err != nil {
+			return nil, err
+		}
+		override.StartsAt, _ = parseTimeValue(startsAt)
+		override.EndsAt, _ = parseTimeValue(endsAt)
+		override.CreatedAt, _ = parseTimeValue(createdAt)
+		overrides = append(overrides, override)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+func scanSchedule(row rowScanner) (models.OnCallSchedule, error) {
+	var schedule models.OnCallSchedule
+	var rotationStart any
+	var participants string
+	if err := row.Scan(&schedule.ID, &schedule.UserID, &schedule.OrganizationID, &schedule.Name, &rotationStart, &schedule.RotationDays,
+		&participants, &schedule.CreatedAt); err != nil {
+		return models.OnCallSchedule{}, err
+	}
+	schedule.RotationStart, _ = parseTimeValue(rotationStart)
+	if err := json.Unmarshal([]byte(participants), &schedule.Participants); err != nil {
+		return models.OnCallSchedule{}, err
+	}
+	return schedule, nil
+}
+
+func scanPolicy(row rowScanner) (models.EscalationPolicy, error) {
+	var policy models.EscalationPolicy
+	var steps string
+	if err := row.Scan(&policy.ID, &policy.UserID, &policy.OrganizationID, &policy.Name, &steps, &policy.CreatedAt); err != nil {
+		return models.EscalationPolicy{}, err
+	}
+	if err := json.Unmarshal([]byte(steps), &policy.Steps); err != nil {
+		return models.EscalationPolicy{}, err
+	}
+	return policy, nil
+}
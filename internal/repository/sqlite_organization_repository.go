@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"learn/internal/models"
+)
+
+type SQLiteOrganizationRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteOrganizationRepository(db *sql.DB) *SQLiteOrganizationRepository {
+	return &SQLiteOrganizationRepository{db: db}
+}
+
+func (r *SQLiteOrganizationRepository) Create(ctx context.Context, organization models.Organization) (models.Organization, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Organization{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO organizations (id, name, created_by)
+VALUES (?, ?, ?)
+`, organization.ID, organization.Name, organization.CreatedBy); err != nil {
+		return models.Organization{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO organization_members (organization_id, user_id, role)
+VALUES (?, ?, ?)
+`, organization.ID, organization.CreatedBy, models.OrgRoleOwner); err != nil {
+		return models.Organization{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Organization{}, err
+	}
+
+	return r.GetForUser(ctx, organization.CreatedBy, organization.ID)
+}
+
+func (r *SQLiteOrganizationRepository) ListByUser(ctx context.Context, userID string) ([]models.Organization, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT o.id, o.name, o.created_by, o.created_at, om.role
+FROM organizations o
+JOIN organization_members om ON om.organization_id = o.id
+WHERE om.user_id = ?
+ORDER BY o.name ASC
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var organizations []models.Organization
+	for rows.Next() {
+		var organization models.Organization
+		if err := rows.Scan(&organization.ID, &organization.Name, &organization.CreatedBy, &organization.CreatedAt, &organization.Role); err != nil {
+			return nil, err
+		}
+		organizations = append(organizations, organization)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return organizations, nil
+}
+
+func (r *SQLiteOrganizationRepository) GetForUser(ctx context.Context, userID, id string) (models.Organization, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT o.id, o.name, o.created_by, o.created_at, om.role
+FROM organizations o
+JOIN organization_members om ON om.organization_id = o.id
+WHERE o.id = ? AND om.user_id = ?
+`, id, userID)
+
+	var organization models.Organization
+	if err := row.Scan(&organization.ID, &organization.Name, &organization.CreatedBy, &organization.CreatedAt, &organization.Role); err != nil {
+		return models.Organization{}, err
+	}
+	return organization, nil
+}
+
+func (r *SQLiteOrganizationRepository) GetMemberRole(ctx context.Context, organizationID, userID string) (string, error) {
+	var role string
+	err := r.db.QueryRowContext(ctx, `
+SELECT role FROM organization_members WHERE organization_id = ? AND user_id = ?
+`, organizationID, userID).Scan(&role)
+	return role, err
+}
+
+func (r *SQLiteOrganizationRepository) ListMembers(ctx context.Context, organizationID string) ([]models.OrganizationMember, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT om.organization_id, om.user_id, u.username, u.display_name, om.role, om.created_at
+FROM organization_members om
+JOIN users u ON u.id = om.user_id
+WHERE om.organization_id = ?
+ORDER BY om.created_at ASC
+`, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []models.OrganizationMember
+	for rows.Next() {
+		var member models.OrganizationMember
+		if err := rows.Scan(&member.OrganizationID, &member.UserID, &member.Username, &member.DisplayName, &member.Role, &member.JoinedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (r *SQLiteOrganizationRepository) UpdateMemberRole(ctx context.Context, organizationID, userID, role string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+UPDATE organization_members SET role = ? WHERE organization_id = ? AND user_id = ?
+`, role, organizationID, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *SQLiteOrganizationRepository) RemoveMember(ctx context.Context, organizationID, userID string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+DELETE FROM organization_members WHERE organization_id = ? AND user_id = ?
+`, organizationID, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *SQLiteOrganizationRepository) CountOwners(ctx context.Context, organizationID string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM organization_members WHERE organization_id = ? AND role = ?
+`, organizationID, models.OrgRoleOwner).Scan(&count)
+	return count, err
+}
+
+func (r *SQLiteOrganizationRepository) CreateInvite(ctx context.Context, invite models.OrganizationInvite) (models.OrganizationInvite, error) {
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO organization_invites (id, organization_id, email, role, token_hash, invited_by, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`, invite.ID, invite.OrganizationID, invite.Email, invite.Role, invite.TokenHash, invite.InvitedBy, formatTime(invite.ExpiresAt))
+	if err != nil {
+		return models.OrganizationInvite{}, err
+	}
+
+	return r.GetInviteByTokenHash(ctx, invite.TokenHash)
+}
+
+func (r *SQLiteOrganizationRepository) ListPendingInvites(ctx context.Context, organizationID string, now time.Time) ([]models.OrganizationInvite, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT id, organization_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at
+FROM organization_invites
+WHERE organization_id = ? AND accepted_at IS NULL AND expires_at > ?
+ORDER BY created_at DESC
+`, organizationID, formatTime(now))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invites []models.OrganizationInvite
+	for rows.Next() {
+		invite, err := scanInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, invite)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+func (r *SQLiteOrganizationRepository) DeleteInvite(ctx context.Context, organizationID, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+DELETE FROM organization_invites WHERE id = ? AND organization_id = ? AND accepted_at IS NULL
+`, id, organizationID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *SQLiteOrganizationRepository) GetInviteByTokenHash(ctx context.Context, tokenHash string) (models.OrganizationInvite, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT id, organization_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at
+FROM organization_invites
+WHERE token_hash = ?
+`, tokenHash)
+
+	return scanInvite(row)
+}
+
+func (r *SQLiteOrganizationRepository) AcceptInvite(ctx context.Context, invite models.OrganizationInvite, userID string, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+UPDATE organization_invites SET accepted_at = ?
+WHERE id = ? AND accepted_at IS NULL AND expires_at > ?
+`, formatTime(now), invite.ID, formatTime(now))
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+INSERT INTO organization_members (organization_id, user_id, role)
+VALUES (?, ?, ?)
+`, invite.OrganizationID, userID, invite.Role); err != nil {
+		if isSQLiteUniqueConstraint(err) {
+			return ErrAlreadyMember
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func scanInvite(row rowScanner) (models.OrganizationInvite, error) {
+	var invite models.OrganizationInvite
+	var expiresAtValue, acceptedAtValue, createdAtValue any
+	if err := row.Scan(&invite.ID, &invite.OrganizationID, &invite.Email, &invite.Role, &invite.TokenHash, &invite.InvitedBy, &expiresAtValue, &acceptedAtValue, &createdAtValue); err != nil {
+		return models.OrganizationInvite{}, err
+	}
+	if parsed, ok := parseTimeValue(expiresAtValue); ok {
+		invite.ExpiresAt = parsed
+	}
+	if parsed, ok := parseTimeValue(acceptedAtValue); ok {
+		invite.AcceptedAt = &parsed
+	}
+	if parsed, ok := parseTimeValue(createdAtValue); ok {
+		invite.CreatedAt = parsed
+	}
+	return invite, nil
+}
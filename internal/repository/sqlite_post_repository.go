@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"learn/internal/models"
+)
+
+type SQLitePostRepository struct {
+	db *sql.DB
+}
+
+func NewSQLitePostRepository(db *sql.DB) *SQLitePostRepository {
+	return &SQLitePostRepository{db: db}
+}
+
+func (r *SQLitePostRepository) ListByUser(ctx context.Context, userID string) ([]models.Post, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT id, title, slug, COALESCE(content, ''), user_id, created_at
+FROM posts
+WHERE user_id = ?
+ORDER BY created_at DESC
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []models.Post
+	for rows.Next() {
+		var post models.Post
+		if err := rows.Scan(&post.ID, &post.Title, &post.Slug, &post.Content, &post.UserID, &post.CreatedAt); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"learn/internal/models"
+)
+
+const probeColumns = `id, location, token_hash, last_seen_at, created_at`
+
+type SQLiteProbeRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteProbeRepository(db *sql.DB) *SQLiteProbeRepository {
+	return &SQLiteProbeRepository{db: db}
+}
+
+func (r *SQLiteProbeRepository) Create(ctx context.Context, probe models.Probe) (models.Probe, error) {
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO probes (id, location, token_hash)
+VALUES (?, ?, ?)
+`, probe.ID, probe.Location, probe.TokenHash)
+	if err != nil {
+		if isSQLiteUniqueConstraint(err) {
+			return models.Probe{}, ErrProbeExists
+		}
+		return models.Probe{}, err
+	}
+
+	return r.GetByID(ctx, probe.ID)
+}
+
+func (r *SQLiteProbeRepository) List(ctx context.Context) ([]models.Probe, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+probeColumns+`
+FROM probes
+ORDER BY location
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var probes []models.Probe
+	for rows.Next() {
+		probe, err := scanProbe(rows)
+		if err != nil {
+			return nil, err
+		}
+		probes = append(probes, probe)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return probes, nil
+}
+
+func (r *SQLiteProbeRepository) GetByID(ctx context.Context, id string) (models.Probe, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT `+probeColumns+`
+FROM probes
+WHERE id = ?
+`, id)
+
+	return scanProbe(row)
+}
+
+func (r *SQLiteProbeRepository) GetByTokenHash(ctx context.Context, tokenHash string) (models.Probe, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT `+probeColumns+`
+FROM probes
+WHERE token_hash = ?
+`, tokenHash)
+
+	return scanProbe(row)
+}
+
+func (r *SQLiteProbeRepository) Delete(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM probes WHERE id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *SQLiteProbeRepository) Touch(ctx context.Context, id string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE probes SET last_seen_at = ? WHERE id = ?", formatTime(at), id)
+	return err
+}
+
+func scanProbe(row rowScanner) (models.Probe, error) {
+	var probe models.Probe
+	var lastSeenAt, createdAt any
+	if err := row.Scan(&probe.ID, &probe.Location, &probe.TokenHash, &lastSeenAt, &createdAt); err != nil {
+		return models.Probe{}, err
+	}
+	if parsed, ok := parseTimeValue(lastSeenAt); ok {
+		probe.LastSeenAt = &parsed
+	}
+	if parsed, ok := parseTimeValue(createdAt); ok {
+		probe.CreatedAt = parsed
+	}
+	return probe, nil
+}
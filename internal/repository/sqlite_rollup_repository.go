@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"learn/internal/models"
+)
+
+var rollupTables = map[string]string{
+	models.RollupHour: "monitor_rollups_hourly",
+	models.RollupDay:  "monitor_rollups_daily",
+}
+
+const rollupColumns = `monitor_id, bucket_start, checks, up_checks, min_response_ms, avg_response_ms, max_response_ms, p95_response_ms,
+	first_checked_at`
+
+type SQLiteRollupRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteRollupRepository(db *sql.DB) *SQLiteRollupRepository {
+	return &SQLiteRollupRepository{db: db}
+}
+
+// RolledUntil returns the end of the last bucket that has been rolled up, or
+// sql.ErrNoRows before the first rollup.
+func (r *SQLiteRollupRepository) RolledUntil(ctx context.Context, granularity string) (time.Time, error) {
+	var rolledUntil any
+	if err := r.db.QueryRowContext(ctx, `
+SELECT rolled_until FROM rollup_watermarks WHERE granularity = ?
+`, granularity).Scan(&rolledUntil); err != nil {
+		return time.Time{}, err
+	}
+	parsed, _ := parseTimeValue(rolledUntil)
+	return parsed.UTC(), nil
+}
+
+// Save stores rollups, replacing any for the same buckets, and records that
+// everything before until has been rolled up.
+func (r *SQLiteRollupRepository) Save(ctx context.Context, granularity string, rollups []models.MonitorRollup, until time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, rollup := range rollups {
+		if _, err := tx.ExecContext(ctx, `
+INSERT OR REPLACE INTO `+rollupTables[granularity]+` (`+rollupColumns+`)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`, rollup.MonitorID, formatTime(rollup.BucketStart), rollup.Checks, rollup.UpChecks, rollup.MinResponseMs, rollup.AvgResponseMs,
+			rollup.MaxResponseMs, rollup.P95ResponseMs, formatTime(rollup.FirstCheckedAt)); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `
+INSERT OR REPLACE INTO rollup_watermarks (granularity, rolled_until) VALUES (?, ?)
+`, granularity, formatTime(until)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *SQLiteRollupRepository) List(ctx context.Context, monitorID, granularity string, from, to time.Time) ([]models.MonitorRollup, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+rollupColumns+`
+FROM `+rollupTables[granularity]+`
+WHERE monitor_id = ? AND bucket_start >= ? AND bucket_start < ?
+ORDER BY bucket_start ASC
+`, monitorID, formatTime(from), formatTime(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rollups []models.MonitorRollup
+	for rows.Next() {
+		var rollup models.MonitorRollup
+		var bucketStart, firstCheckedAt any
+		if err := rows.Scan(&rollup.MonitorID, &bucketStart, &rollup.Checks, &rollup.UpChecks, &rollup.MinResponseMs, &rollup.AvgResponseMs,
+			&rollup.MaxResponseMs, &rollup.P95ResponseMs, &firstCheckedAt); err != nil {
+			return nil, err
+		}
+		rollup.BucketStart, _ = parseTimeValue(bucketStart)
+		rollup.FirstCheckedAt, _ = parseTimeValue(firstCheckedAt)
+		rollups = append(rollups, rollup)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rollups, nil
+}
+
+func (r *SQLiteRollupRepository) DeleteBefore(ctx context.Context, granularity string, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+DELETE FROM `+rollupTables[granularity]+` WHERE bucket_start < ?
+`, formatTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -28,10 +28,15 @@ func (r *SQLiteSnippetRepository) Create(ctx context.Context, snippet models.Sni
 		expiresAt = sql.NullTime{Time: *snippet.ExpiresAt, Valid: true}
 	}
 
+	userID := sql.NullString{}
+	if snippet.UserID != nil {
+		userID = sql.NullString{String: *snippet.UserID, Valid: true}
+	}
+
 	_, err := r.db.ExecContext(ctx, `
-INSERT INTO snippets (id, hash, content, password, burn_after_read, expires_at)
-VALUES (?, ?, ?, ?, ?, ?)
-`, snippet.ID, snippet.Hash, snippet.Content, password, boolToInt(snippet.BurnAfterRead), expiresAt)
+INSERT INTO snippets (id, user_id, hash, content, password, burn_after_read, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`, snippet.ID, userID, snippet.Hash, snippet.Content, password, boolToInt(snippet.BurnAfterRead), expiresAt)
 	if err != nil {
 		return models.Snippet{}, err
 	}
@@ -41,7 +46,7 @@ VALUES (?, ?, ?, ?, ?, ?)
 
 func (r *SQLiteSnippetRepository) GetByHash(ctx context.Context, hash string) (models.Snippet, error) {
 	row := r.db.QueryRowContext(ctx, `
-SELECT id, hash, content, password, burn_after_read, expires_at, created_at
+SELECT id, user_id, hash, content, password, burn_after_read, expires_at, created_at
 FROM snippets
 WHERE hash = ?
 `, hash)
@@ -49,6 +54,33 @@ WHERE hash = ?
 	return scanSnippet(row)
 }
 
+func (r *SQLiteSnippetRepository) ListByUser(ctx context.Context, userID string) ([]models.Snippet, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT id, user_id, hash, content, password, burn_after_read, expires_at, created_at
+FROM snippets
+WHERE user_id = ?
+ORDER BY created_at DESC
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snippets []models.Snippet
+	for rows.Next() {
+		snippet, err := scanSnippet(rows)
+		if err != nil {
+			return nil, err
+		}
+		snippets = append(snippets, snippet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return snippets, nil
+}
+
 func (r *SQLiteSnippetRepository) DeleteByID(ctx context.Context, id string) error {
 	_, err := r.db.ExecContext(ctx, "DELETE FROM snippets WHERE id = ?", id)
 	return err
@@ -68,7 +100,7 @@ func (r *SQLiteSnippetRepository) DeleteExpired(ctx context.Context, now time.Ti
 
 func (r *SQLiteSnippetRepository) getByID(ctx context.Context, id string) (models.Snippet, error) {
 	row := r.db.QueryRowContext(ctx, `
-SELECT id, hash, content, password, burn_after_read, expires_at, created_at
+SELECT id, user_id, hash, content, password, burn_after_read, expires_at, created_at
 FROM snippets
 WHERE id = ?
 `, id)
@@ -76,15 +108,24 @@ WHERE id = ?
 	return scanSnippet(row)
 }
 
-func scanSnippet(row *sql.Row) (models.Snippet, error) {
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSnippet(row rowScanner) (models.Snippet, error) {
 	var snippet models.Snippet
+	var userID sql.NullString
 	var password sql.NullString
 	var burnAfterRead int
 	var expiresAtValue any
 	var createdAtValue any
-	if err := row.Scan(&snippet.ID, &snippet.Hash, &snippet.Content, &password, &burnAfterRead, &expiresAtValue, &createdAtValue); err != nil {
+	if err := row.Scan(&snippet.ID, &userID, &snippet.Hash, &snippet.Content, &password, &burnAfterRead, &expiresAtValue, &createdAtValue); err != nil {
 		return models.Snippet{}, err
 	}
+	if userID.Valid {
+		value := userID.String
+		snippet.UserID = &value
+	}
 	if password.Valid {
 		value := password.String
 		snippet.PasswordHash = &value
@@ -129,3 +170,14 @@ func parseTimeString(value string) (time.Time, bool) {
 	}
 	return time.Time{}, false
 }
+
+func formatTime(value time.Time) string {
+	return value.UTC().Format("2006-01-02 15:04:05")
+}
+
+func nullTime(value *time.Time) sql.NullString {
+	if value == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(*value), Valid: true}
+}
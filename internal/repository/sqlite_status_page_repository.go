@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"learn/internal/models"
+)
+
+const statusPageColumns = `id, user_id, COALESCE(organization_id, ''), slug, title, description, custom_css, sections, created_at, updated_at`
+
+type SQLiteStatusPageRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteStatusPageRepository(db *sql.DB) *SQLiteStatusPageRepository {
+	return &SQLiteStatusPageRepository{db: db}
+}
+
+func (r *SQLiteStatusPageRepository) Create(ctx context.Context, page models.StatusPage) (models.StatusPage, error) {
+	sections, err := json.Marshal(page.Sections)
+	if err != nil {
+		return models.StatusPage{}, err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+INSERT INTO status_pages (id, user_id, organization_id, slug, title, description, custom_css, sections)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`, page.ID, page.UserID, nullString(page.OrganizationID), page.Slug, page.Title, page.Description, page.CustomCSS, string(sections))
+	if err != nil {
+		if isSQLiteUniqueConstraint(err) {
+			return models.StatusPage{}, ErrStatusPageSlugTaken
+		}
+		return models.StatusPage{}, err
+	}
+
+	return r.GetByID(ctx, page.ID)
+}
+
+func (r *SQLiteStatusPageRepository) ListByUser(ctx context.Context, userID string) ([]models.StatusPage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+statusPageColumns+`
+FROM status_pages
+WHERE `+monitorReadScope+`
+ORDER BY created_at DESC
+`, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pages []models.StatusPage
+	for rows.Next() {
+		page, err := scanStatusPage(rows)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+func (r *SQLiteStatusPageRepository) GetByID(ctx context.Context, id string) (models.StatusPage, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT `+statusPageColumns+`
+FROM status_pages
+WHERE id = ?
+`, id)
+
+	return scanStatusPage(row)
+}
+
+func (r *SQLiteStatusPageRepository) GetBySlug(ctx context.Context, slug string) (models.StatusPage, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT `+statusPageColumns+`
+FROM status_pages
+WHERE slug = ?
+`, slug)
+
+	return scanStatusPage(row)
+}
+
+func (r *SQLiteStatusPageRepository) Update(ctx context.Context, userID string, page models.StatusPage) (bool, error) {
+	sections, err := json.Marshal(page.Sections)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+UPDATE status_pages
+SET slug = ?, title = ?, description = ?, custom_css = ?, sections = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ? AND `+monitorWriteScope+`
+`, page.Slug, page.Title, page.Description, page.CustomCSS, string(sections), page.ID, userID, userID)
+	if err != nil {
+		if isSQLiteUniqueConstraint(err) {
+			return false, ErrStatusPageSlugTaken
+		}
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *SQLiteStatusPageRepository) Delete(ctx context.Context, userID, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+DELETE FROM status_pages WHERE id = ? AND `+monitorWriteScope+`
+`, id, userID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func scanStatusPage(row rowScanner) (models.StatusPage, error) {
+	var page models.StatusPage
+	var sections string
+	if err := row.Scan(&page.ID, &page.UserID, &page.OrganizationID, &page.Slug, &page.Title, &page.Description, &page.CustomCSS,
+		&sections, &page.CreatedAt, &page.UpdatedAt); err != nil {
+		return models.StatusPage{}, err
+	}
+	if err := json.Unmarshal([]byte(sections), &page.Sections); err != nil {
+		return models.StatusPage{}, err
+	}
+	return page, nil
+}
@@ -35,58 +35,108 @@ VALUES (?, ?, ?, ?)
 
 func (r *SQLiteUserRepository) GetByEmail(ctx context.Context, email string) (models.User, error) {
 	row := r.db.QueryRowContext(ctx, `
-SELECT id, username, email, password, created_at
+SELECT id, username, email, password, display_name, bio, avatar_url, created_at
 FROM users
 WHERE email = ?
 `, email)
 
-	var user models.User
-	if err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.CreatedAt); err != nil {
-		return models.User{}, err
-	}
-
-	return user, nil
+	return scanUser(row)
 }
 
 func (r *SQLiteUserRepository) GetByID(ctx context.Context, id string) (models.User, error) {
 	row := r.db.QueryRowContext(ctx, `
-SELECT id, username, email, password, created_at
+SELECT id, username, email, password, display_name, bio, avatar_url, created_at
 FROM users
 WHERE id = ?
 `, id)
 
-	var user models.User
-	if err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.CreatedAt); err != nil {
-		return models.User{}, err
+	return scanUser(row)
+}
+
+func (r *SQLiteUserRepository) ListPublic(ctx context.Context, filter models.UserFilter) ([]models.PublicProfile, int, error) {
+	where := ""
+	var args []any
+	if filter.Search != "" {
+		where = "WHERE u.username LIKE ? ESCAPE '\\' OR u.display_name LIKE ? ESCAPE '\\'"
+		pattern := "%" + escapeLike(filter.Search) + "%"
+		args = append(args, pattern, pattern)
 	}
 
-	return user, nil
-}
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users u "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
 
-func (r *SQLiteUserRepository) List(ctx context.Context) ([]models.User, error) {
 	rows, err := r.db.QueryContext(ctx, `
-SELECT id, username, email, created_at
-FROM users
-ORDER BY created_at DESC
-`)
+SELECT u.id, u.username, u.display_name, u.bio, u.avatar_url, u.created_at,
+	(SELECT COUNT(*) FROM posts p WHERE p.user_id = u.id) as post_count
+FROM users u
+`+where+`
+ORDER BY u.created_at DESC, u.username ASC
+LIMIT ? OFFSET ?
+`, append(args, filter.Limit, filter.Offset)...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var users []models.User
+	var profiles []models.PublicProfile
 	for rows.Next() {
-		var user models.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.CreatedAt); err != nil {
-			return nil, err
+		profile, err := scanPublicProfile(rows)
+		if err != nil {
+			return nil, 0, err
 		}
-		users = append(users, user)
+		profiles = append(profiles, profile)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	return profiles, total, nil
+}
+
+func (r *SQLiteUserRepository) GetPublicByID(ctx context.Context, id string) (models.PublicProfile, error) {
+	row := r.db.QueryRowContext(ctx, `
+SELECT u.id, u.username, u.display_name, u.bio, u.avatar_url, u.created_at,
+	(SELECT COUNT(*) FROM posts p WHERE p.user_id = u.id) as post_count
+FROM users u
+WHERE u.id = ?
+`, id)
+
+	return scanPublicProfile(row)
+}
+
+func (r *SQLiteUserRepository) UpdatePassword(ctx context.Context, id, passwordHash string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE users SET password = ? WHERE id = ?", passwordHash, id)
+	return err
+}
+
+func (r *SQLiteUserRepository) UpdateProfile(ctx context.Context, id, displayName, bio, avatarURL string) error {
+	_, err := r.db.ExecContext(ctx, `
+UPDATE users SET display_name = ?, bio = ?, avatar_url = ? WHERE id = ?
+`, displayName, bio, avatarURL, id)
+	return err
+}
+
+func scanUser(row rowScanner) (models.User, error) {
+	var user models.User
+	if err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.DisplayName, &user.Bio, &user.AvatarURL, &user.CreatedAt); err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+func scanPublicProfile(row rowScanner) (models.PublicProfile, error) {
+	var profile models.PublicProfile
+	if err := row.Scan(&profile.ID, &profile.Username, &profile.DisplayName, &profile.Bio, &profile.AvatarURL, &profile.JoinedAt, &profile.PostCount); err != nil {
+		return models.PublicProfile{}, err
 	}
+	return profile, nil
+}
 
-	return users, nil
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return replacer.Replace(value)
 }
 
 func isSQLiteUniqueConstraint(err error) bool {
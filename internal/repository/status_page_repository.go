@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"learn/internal/models"
+)
+
+var ErrStatusPageSlugTaken = errors.New("status page slug already taken")
+
+type StatusPageRepository interface {
+	Create(ctx context.Context, page models.StatusPage) (models.StatusPage, error)
+	ListByUser(ctx context.Context, userID string) ([]models.StatusPage, error)
+	GetByID(ctx context.Context, id string) (models.StatusPage, error)
+	GetBySlug(ctx context.Context, slug string) (models.StatusPage, error)
+	Update(ctx context.Context, userID string, page models.StatusPage) (bool, error)
+	Delete(ctx context.Context, userID, id string) (bool, error)
+}
@@ -13,5 +13,8 @@ type UserRepository interface {
 	Create(ctx context.Context, user models.User) (models.User, error)
 	GetByEmail(ctx context.Context, email string) (models.User, error)
 	GetByID(ctx context.Context, id string) (models.User, error)
-	List(ctx context.Context) ([]models.User, error)
+	ListPublic(ctx context.Context, filter models.UserFilter) ([]models.PublicProfile, int, error)
+	GetPublicByID(ctx context.Context, id string) (models.PublicProfile, error)
+	UpdatePassword(ctx context.Context, id, passwordHash string) error
+	UpdateProfile(ctx context.Context, id, displayName, bio, avatarURL string) error
 }
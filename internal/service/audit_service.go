@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/google/uuid"
+	"learn/internal/models"
+	"learn/internal/repository"
+)
+
+type clientInfoKey struct{}
+
+type AuditService struct {
+	audits repository.AuditRepository
+}
+
+func NewAuditService(audits repository.AuditRepository) *AuditService {
+	return &AuditService{audits: audits}
+}
+
+func WithClientInfo(ctx context.Context, info models.ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+func ClientInfoFromContext(ctx context.Context) models.ClientInfo {
+	info, _ := ctx.Value(clientInfoKey{}).(models.ClientInfo)
+	return info
+}
+
+func (s *AuditService) Record(ctx context.Context, actorID, action, resourceType, resourceID string, before, after any) {
+	client := ClientInfoFromContext(ctx)
+	entry := models.AuditLog{
+		ID:           uuid.NewString(),
+		ActorID:      actorID,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		IPAddress:    client.IPAddress,
+		UserAgent:    client.UserAgent,
+		Before:       marshalAuditValue(before),
+		After:        marshalAuditValue(after),
+	}
+
+	if err := s.audits.Create(context.WithoutCancel(ctx), entry); err != nil {
+		log.Printf("Error recording audit event %s: %v", action, err)
+	}
+}
+
+func (s *AuditService) ListForUser(ctx context.Context, userID string, filter models.AuditFilter) ([]models.AuditLog, error) {
+	filter.ActorID = userID
+	return s.audits.List(ctx, filter)
+}
+
+func (s *AuditService) List(ctx context.Context, filter models.AuditFilter) ([]models.AuditLog, error) {
+	return s.audits.List(ctx, filter)
+}
+
+func marshalAuditValue(value any) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Error encoding audit value: %v", err)
+		return nil
+	}
+	return data
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,12 +18,13 @@ var ErrInvalidCredentials = errors.New("invalid credentials")
 
 type AuthService struct {
 	users     repository.UserRepository
+	audit     *AuditService
 	jwtSecret string
 	jwtExpiry time.Duration
 }
 
-func NewAuthService(users repository.UserRepository, jwtSecret string, jwtExpiry time.Duration) *AuthService {
-	return &AuthService{users: users, jwtSecret: jwtSecret, jwtExpiry: jwtExpiry}
+func NewAuthService(users repository.UserRepository, audit *AuditService, jwtSecret string, jwtExpiry time.Duration) *AuthService {
+	return &AuthService{users: users, audit: audit, jwtSecret: jwtSecret, jwtExpiry: jwtExpiry}
 }
 
 func (s *AuthService) Register(ctx context.Context, username, email, password string) (models.User, string, error) {
@@ -30,6 +32,7 @@ func (s *AuthService) Register(ctx context.Context, username, email, password st
 	if err != nil {
 		return models.User{}, "", err
 	}
+	email = normalizeEmail(email)
 
 	user, err := s.users.Create(ctx, models.User{
 		ID:           uuid.NewString(),
@@ -41,7 +44,7 @@ func (s *AuthService) Register(ctx context.Context, username, email, password st
 		return models.User{}, "", err
 	}
 
-	token, err := jwt.GenerateToken(s.jwtSecret, s.jwtExpiry, user.ID, user.Username, user.Email)
+	token, err := s.issueToken(ctx, user)
 	if err != nil {
 		return models.User{}, "", err
 	}
@@ -50,22 +53,63 @@ func (s *AuthService) Register(ctx context.Context, username, email, password st
 }
 
 func (s *AuthService) Login(ctx context.Context, email, password string) (models.User, string, error) {
+	email = normalizeEmail(email)
 	user, err := s.users.GetByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
+			s.audit.Record(ctx, "", models.AuditActionLoginFailed, "user", "", nil, map[string]string{"email": email, "reason": "unknown email"})
 			return models.User{}, "", ErrInvalidCredentials
 		}
 		return models.User{}, "", err
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		s.audit.Record(ctx, user.ID, models.AuditActionLoginFailed, "user", user.ID, nil, map[string]string{"email": email, "reason": "invalid password"})
 		return models.User{}, "", ErrInvalidCredentials
 	}
 
-	token, err := jwt.GenerateToken(s.jwtSecret, s.jwtExpiry, user.ID, user.Username, user.Email)
+	s.audit.Record(ctx, user.ID, models.AuditActionLoginSucceeded, "user", user.ID, nil, nil)
+
+	token, err := s.issueToken(ctx, user)
 	if err != nil {
 		return models.User{}, "", err
 	}
 
 	return user, token, nil
 }
+
+func (s *AuthService) ChangePassword(ctx context.Context, user models.User, currentPassword, newPassword string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := s.users.UpdatePassword(ctx, user.ID, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, user.ID, models.AuditActionPasswordChanged, "user", user.ID, nil, nil)
+	return nil
+}
+
+func (s *AuthService) issueToken(ctx context.Context, user models.User) (string, error) {
+	token, err := jwt.GenerateToken(s.jwtSecret, s.jwtExpiry, user.ID, user.Username, user.Email)
+	if err != nil {
+		return "", err
+	}
+
+	s.audit.Record(ctx, user.ID, models.AuditActionTokenIssued, "user", user.ID, nil, map[string]any{
+		"expires_at": time.Now().Add(s.jwtExpiry).UTC(),
+	})
+	return token, nil
+}
+
+// normalizeEmail makes addresses that differ only in case or surrounding
+// space the same account.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"learn/internal/models"
+	"learn/internal/repository"
+	"learn/internal/storage"
+)
+
+var (
+	ErrAvatarUnsupportedType = errors.New("unsupported avatar image type")
+	ErrAvatarInvalidImage    = errors.New("invalid avatar image")
+	ErrAvatarTooLarge        = errors.New("avatar image dimensions too large")
+	ErrAvatarNotFound        = errors.New("avatar not found")
+)
+
+const (
+	avatarURLPrefix    = "/avatars/"
+	avatarMaxDimension = 4096
+)
+
+var (
+	AvatarSizes       = []int{64, 256}
+	DefaultAvatarSize = 256
+)
+
+var allowedAvatarTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+type AvatarService struct {
+	blobs storage.BlobStore
+	users repository.UserRepository
+	audit *AuditService
+}
+
+func NewAvatarService(blobs storage.BlobStore, users repository.UserRepository, audit *AuditService) *AvatarService {
+	return &AvatarService{blobs: blobs, users: users, audit: audit}
+}
+
+func (s *AvatarService) Upload(ctx context.Context, user models.User, data []byte) (models.User, error) {
+	if !allowedAvatarTypes[http.DetectContentType(data)] {
+		return models.User{}, ErrAvatarUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return models.User{}, ErrAvatarInvalidImage
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return models.User{}, ErrAvatarInvalidImage
+	}
+	if config.Width > avatarMaxDimension || config.Height > avatarMaxDimension {
+		return models.User{}, ErrAvatarTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return models.User{}, ErrAvatarInvalidImage
+	}
+
+	avatarID := uuid.NewString()
+	for _, size := range AvatarSizes {
+		encoded, err := encodeAvatar(src, size)
+		if err != nil {
+			return models.User{}, err
+		}
+		if err := s.blobs.Put(ctx, avatarKey(avatarID, size), encoded); err != nil {
+			return models.User{}, err
+		}
+	}
+
+	previous := user.AvatarURL
+	before := user.Response()
+	user.AvatarURL = avatarURLPrefix + avatarID
+	if err := s.users.UpdateProfile(ctx, user.ID, user.DisplayName, user.Bio, user.AvatarURL); err != nil {
+		s.deleteVariants(ctx, avatarID)
+		return models.User{}, err
+	}
+
+	if oldID, ok := strings.CutPrefix(previous, avatarURLPrefix); ok && oldID != "" {
+		s.deleteVariants(ctx, oldID)
+	}
+
+	s.audit.Record(ctx, user.ID, models.AuditActionProfileUpdated, "user", user.ID, before, user.Response())
+	return user, nil
+}
+
+func (s *AvatarService) Get(ctx context.Context, avatarID string, size int) ([]byte, error) {
+	if _, err := uuid.Parse(avatarID); err != nil {
+		return nil, ErrAvatarNotFound
+	}
+
+	data, err := s.blobs.Get(ctx, avatarKey(avatarID, size))
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return nil, ErrAvatarNotFound
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+func (s *AvatarService) deleteVariants(ctx context.Context, avatarID string) {
+	if _, err := uuid.Parse(avatarID); err != nil {
+		return
+	}
+	for _, size := range AvatarSizes {
+		if err := s.blobs.Delete(ctx, avatarKey(avatarID, size)); err != nil {
+			log.Printf("Error deleting avatar %s: %v", avatarID, err)
+		}
+	}
+}
+
+func encodeAvatar(src image.Image, size int) ([]byte, error) {
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		bounds.Min.X+(bounds.Dx()-side)/2,
+		bounds.Min.Y+(bounds.Dy()-side)/2,
+	))
+
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func avatarKey(avatarID string, size int) string {
+	return "avatars/" + avatarID + "/" + strconv.Itoa(size) + ".png"
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"learn/internal/models"
+)
+
+var ErrInvalidMonitorTarget = errors.New("invalid monitor target")
+
+type CheckResult struct {
+	Status       string
+	StatusCode   int
+	ResponseTime time.Duration
+	Message      string
+	Details      map[string]string
+	Timings      *models.CheckTimings
+}
+
+type Checker interface {
+	Check(ctx context.Context, monitor models.Monitor) CheckResult
+}
+
+func newCheckers(egress *EgressPolicy) map[string]Checker {
+	return map[string]Checker{
+		models.MonitorTypeHTTP: NewHTTPChecker(checkTimeout, egress),
+		models.MonitorTypeTCP:  NewTCPChecker(checkTimeout, egress),
+		models.MonitorTypeDNS:  NewDNSChecker(checkTimeout, egress),
+		models.MonitorTypeTLS:  NewTLSChecker(checkTimeout, egress),
+	}
+}
+
+func downResult(start time.Time, format string, args ...any) CheckResult {
+	return CheckResult{
+		Status:       models.MonitorStatusDown,
+		ResponseTime: time.Since(start),
+		Message:      fmt.Sprintf(format, args...),
+	}
+}
+
+// monitorTarget returns the host and port a non-HTTP monitor connects to,
+// e.g. tcp://db.internal:5432, tls://example.com or dns://example.com.
+func monitorTarget(monitor models.Monitor) (string, string, error) {
+	if monitor.Type == models.MonitorTypeHeartbeat {
+		return "", "", nil
+	}
+
+	parsed, err := url.Parse(monitor.URL)
+	if err != nil || parsed.Hostname() == "" {
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidMonitorTarget, monitor.URL)
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	switch monitor.Type {
+	case models.MonitorTypeHTTP:
+		if scheme != "http" && scheme != "https" {
+			return "", "", fmt.Errorf("%w: http monitors need an http:// or https:// URL", ErrInvalidMonitorTarget)
+		}
+		return parsed.Hostname(), parsed.Port(), nil
+	case models.MonitorTypeTCP:
+		if scheme != "tcp" || parsed.Port() == "" {
+			return "", "", fmt.Errorf("%w: tcp monitors need a tcp://host:port URL", ErrInvalidMonitorTarget)
+		}
+		return parsed.Hostname(), parsed.Port(), nil
+	case models.MonitorTypeDNS:
+		if scheme != "dns" || parsed.Port() != "" {
+			return "", "", fmt.Errorf("%w: dns monitors need a dns://hostname URL", ErrInvalidMonitorTarget)
+		}
+		return parsed.Hostname(), "", nil
+	case models.MonitorTypeTLS:
+		if scheme != "tls" && scheme != "https" {
+			return "", "", fmt.Errorf("%w: tls monitors need a tls://host[:port] URL", ErrInvalidMonitorTarget)
+		}
+		port := parsed.Port()
+		if port == "" {
+			port = "443"
+		}
+		return parsed.Hostname(), port, nil
+	default:
+		return "", "", fmt.Errorf("%w: unknown monitor type %q", ErrInvalidMonitorTarget, monitor.Type)
+	}
+}
+
+func hostPort(monitor models.Monitor) (string, error) {
+	host, port, err := monitorTarget(monitor)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, port), nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"learn/internal/models"
+)
+
+const blockedDNSRecord = "(blocked address)"
+
+type DNSChecker struct {
+	timeout time.Duration
+	egress  *EgressPolicy
+}
+
+func NewDNSChecker(timeout time.Duration, egress *EgressPolicy) *DNSChecker {
+	return &DNSChecker{timeout: timeout, egress: egress}
+}
+
+func (c *DNSChecker) Check(ctx context.Context, monitor models.Monitor) CheckResult {
+	start := time.Now()
+
+	host, _, err := monitorTarget(monitor)
+	if err != nil {
+		return downResult(start, "%v", err)
+	}
+
+	config := models.DNSCheckConfig{RecordType: models.DefaultDNSRecordType}
+	if monitor.DNS != nil {
+		config = *monitor.DNS
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	records, err := lookupRecords(ctx, c.resolver(config.Resolver), config.RecordType, host)
+	responseTime := time.Since(start)
+	if err != nil {
+		return downResult(start, "%s lookup for %s failed: %v", config.RecordType, host, err)
+	}
+	if len(records) == 0 {
+		return downResult(start, "no %s records for %s", config.RecordType, host)
+	}
+	c.redactBlocked(config.RecordType, records)
+
+	result := CheckResult{
+		Status:       models.MonitorStatusUp,
+		ResponseTime: responseTime,
+		Details:      map[string]string{"records": strings.Join(records, ", ")},
+	}
+
+	if config.Expected != "" {
+		expected := normalizeDNSValue(config.RecordType, config.Expected)
+		if !slices.ContainsFunc(records, func(record string) bool { return normalizeDNSValue(config.RecordType, record) == expected }) {
+			result.Status = models.MonitorStatusDown
+			result.Message = "expected " + config.RecordType + " record " + config.Expected + " not found"
+		}
+	}
+
+	return result
+}
+
+// redactBlocked hides answers pointing at addresses checks may not connect
+// to, so a DNS monitor can't be used to map internal networks. Redacted
+// answers never match the expected value either.
+func (c *DNSChecker) redactBlocked(recordType string, records []string) {
+	switch recordType {
+	case "A", "AAAA", "CNAME":
+	default:
+		return
+	}
+	for i, record := range records {
+		if c.egress.CheckHost(record) != nil {
+			records[i] = blockedDNSRecord
+		}
+	}
+}
+
+func (c *DNSChecker) resolver(address string) *net.Resolver {
+	if address == "" {
+		return net.DefaultResolver
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "53")
+	}
+	dialer := &net.Dialer{Timeout: c.timeout, Control: c.egress.Control}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+}
+
+func lookupRecords(ctx context.Context, resolver *net.Resolver, recordType, host string) ([]string, error) {
+	var records []string
+	switch recordType {
+	case "A", "AAAA":
+		network := "ip4"
+		if recordType == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			records = append(records, ip.String())
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, cname)
+	case "MX":
+		mxs, err := resolver.LookupMX(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			records = append(records, mx.Host+" "+strconv.Itoa(int(mx.Pref)))
+		}
+	case "TXT":
+		txts, err := resolver.LookupTXT(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, txts...)
+	case "NS":
+		nss, err := resolver.LookupNS(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nss {
+			records = append(records, ns.Host)
+		}
+	}
+	return records, nil
+}
+
+// normalizeDNSValue makes "Mail.Example.com." and "mail.example.com" equal.
+// MX records compare on host only so the expected value may omit the
+// preference. TXT records are compared as they are, apart from surrounding
+// space.
+func normalizeDNSValue(recordType, value string) string {
+	value = strings.TrimSpace(value)
+	switch recordType {
+	case "TXT":
+		return value
+	case "MX":
+		value, _, _ = strings.Cut(value, " ")
+	}
+	return strings.TrimSuffix(strings.ToLower(value), ".")
+}
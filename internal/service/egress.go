@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+
+	"learn/internal/models"
+)
+
+var ErrEgressBlocked = errors.New("destination is not allowed by the egress policy")
+
+// blockedPrefixes are addresses checks can't reach unless allowlisted: the
+// host itself, private and shared networks, link-local ranges (which hold
+// cloud metadata services such as 169.254.169.254), and addresses that are
+// never a single public host.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// EgressPolicy decides which addresses monitor checks may connect to. It is
+// enforced when a connection is dialed, after DNS resolution, so a hostname
+// that resolves to a blocked address or a redirect to one fails the same way
+// as a literal address.
+type EgressPolicy struct {
+	allow []netip.Prefix
+}
+
+// NewEgressPolicy blocks internal addresses except those in allow.
+func NewEgressPolicy(allow []netip.Prefix) *EgressPolicy {
+	return &EgressPolicy{allow: allow}
+}
+
+func (p *EgressPolicy) Allowed(addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+	for _, prefix := range p.allow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Control is a net.Dialer Control function. It sees the resolved address of
+// every connection attempt, including each hop of a redirect chain and each
+// address tried for a hostname.
+func (p *EgressPolicy) Control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrEgressBlocked, address)
+	}
+	if !p.Allowed(addr) {
+		return fmt.Errorf("%w: %s", ErrEgressBlocked, addr.Unmap().WithZone(""))
+	}
+	return nil
+}
+
+// CheckHost rejects a literal address or localhost name the policy blocks,
+// so obviously internal targets are refused when a monitor is saved rather
+// than on every check. Other hostnames are checked when they are dialed.
+func (p *EgressPolicy) CheckHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		host = "127.0.0.1"
+	}
+	addr, err := netip.ParseAddr(strings.Trim(host, "[]"))
+	if err != nil || p.Allowed(addr) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrEgressBlocked, addr.Unmap().WithZone(""))
+}
+
+// Client returns an HTTP client for requests to user-supplied URLs, such as
+// webhook deliveries, that dials and follows redirects under the policy.
+func (p *EgressPolicy) Client(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: timeout, Control: p.Control}).DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return p.CheckHost(req.URL.Hostname())
+		},
+	}
+}
+
+// CheckMonitor applies CheckHost to the hosts a monitor connects to.
+func (p *EgressPolicy) CheckMonitor(monitor models.Monitor) error {
+	host, _, err := monitorTarget(monitor)
+	if err != nil {
+		return err
+	}
+	hosts := []string{host}
+	if monitor.DNS != nil && monitor.DNS.Resolver != "" {
+		resolver, _, err := net.SplitHostPort(monitor.DNS.Resolver)
+		if err != nil {
+			resolver = monitor.DNS.Resolver
+		}
+		hosts = append(hosts, resolver)
+	}
+
+	for _, host := range hosts {
+		if err := p.CheckHost(host); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidMonitorTarget, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"learn/internal/models"
+	"learn/internal/repository"
+)
+
+var ErrExportNotFound = errors.New("export not found")
+
+// exportTimeout is how long an export may stay pending. Archives are built in
+// memory by the process that accepted the request, so one still pending after
+// this was lost when that process stopped.
+const exportTimeout = 30 * time.Minute
+
+type ExportService struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	exports  repository.ExportRepository
+	users    repository.UserRepository
+	monitors repository.MonitorRepository
+	posts    repository.PostRepository
+	snippets repository.SnippetRepository
+	linkTTL  time.Duration
+}
+
+type exportMonitor struct {
+	models.Monitor
+	Logs []models.MonitorLog `json:"logs"`
+}
+
+func NewExportService(exports repository.ExportRepository, users repository.UserRepository, monitors repository.MonitorRepository, posts repository.PostRepository, snippets repository.SnippetRepository, linkTTL time.Duration) *ExportService {
+	ctx, cancel := context.WithCancel(context.Background())
+	if linkTTL <= 0 {
+		linkTTL = 24 * time.Hour
+	}
+	return &ExportService{
+		ctx:      ctx,
+		cancel:   cancel,
+		exports:  exports,
+		users:    users,
+		monitors: monitors,
+		posts:    posts,
+		snippets: snippets,
+		linkTTL:  linkTTL,
+	}
+}
+
+func (s *ExportService) Request(ctx context.Context, userID string) (models.DataExport, error) {
+	pending, err := s.exports.GetPendingByUser(ctx, userID)
+	if err == nil {
+		return pending, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return models.DataExport{}, err
+	}
+
+	export, err := s.exports.Create(ctx, models.DataExport{
+		ID:     uuid.NewString(),
+		UserID: userID,
+		Status: models.ExportStatusPending,
+	})
+	if err != nil {
+		return models.DataExport{}, err
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.generate(export)
+	}()
+
+	return export, nil
+}
+
+func (s *ExportService) Get(ctx context.Context, userID, id string) (models.DataExport, error) {
+	export, err := s.exports.GetByID(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.DataExport{}, ErrExportNotFound
+		}
+		return models.DataExport{}, err
+	}
+	return export, nil
+}
+
+func (s *ExportService) Download(ctx context.Context, token string) ([]byte, error) {
+	archive, err := s.exports.ConsumeArchive(ctx, token, time.Now())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrExportNotFound
+		}
+		return nil, err
+	}
+	return archive, nil
+}
+
+func (s *ExportService) DeleteExpired(ctx context.Context) (int64, error) {
+	return s.exports.DeleteExpired(ctx, time.Now())
+}
+
+// FailStale marks exports that have been pending longer than exportTimeout
+// as failed, so their users can request a new one.
+func (s *ExportService) FailStale(ctx context.Context) (int64, error) {
+	now := time.Now()
+	return s.exports.FailPendingBefore(ctx, now.Add(-exportTimeout), "export was interrupted", now)
+}
+
+func (s *ExportService) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *ExportService) generate(export models.DataExport) {
+	archive, err := s.buildArchive(s.ctx, export.UserID)
+	if err != nil {
+		log.Printf("Error generating export %s: %v", export.ID, err)
+		s.markFailed(export.ID)
+		return
+	}
+
+	token, err := generateToken(32)
+	if err != nil {
+		log.Printf("Error generating export token: %v", err)
+		s.markFailed(export.ID)
+		return
+	}
+
+	now := time.Now()
+	if err := s.exports.MarkReady(context.Background(), export.ID, token, archive, now, now.Add(s.linkTTL)); err != nil {
+		log.Printf("Error storing export %s: %v", export.ID, err)
+		s.markFailed(export.ID)
+	}
+}
+
+func (s *ExportService) markFailed(id string) {
+	if err := s.exports.MarkFailed(context.Background(), id, "failed to assemble export", time.Now()); err != nil {
+		log.Printf("Error marking export %s as failed: %v", id, err)
+	}
+}
+
+func (s *ExportService) buildArchive(ctx context.Context, userID string) ([]byte, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	monitors, err := s.monitors.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	exportMonitors := make([]exportMonitor, 0, len(monitors))
+	for _, monitor := range monitors {
+		if monitor.OrganizationID != "" {
+			continue
+		}
+		logs, err := s.monitors.ListAllLogs(ctx, monitor.ID)
+		if err != nil {
+			return nil, err
+		}
+		if logs == nil {
+			logs = []models.MonitorLog{}
+		}
+		exportMonitors = append(exportMonitors, exportMonitor{Monitor: monitor, Logs: logs})
+	}
+
+	posts, err := s.posts.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if posts == nil {
+		posts = []models.Post{}
+	}
+
+	snippets, err := s.snippets.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	snippetResponses := make([]models.SnippetResponse, 0, len(snippets))
+	for _, snippet := range snippets {
+		snippetResponses = append(snippetResponses, snippet.Response())
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	profile := user.Response()
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", profile},
+		{"monitors.json", exportMonitors},
+		{"posts.json", posts},
+		{"snippets.json", snippetResponses},
+	}
+	for _, file := range files {
+		if err := writeJSONFile(zw, file.name, file.data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeCSVFile(zw, "profile.csv", []string{"id", "username", "email", "display_name", "bio", "avatar_url", "created_at"}, [][]string{
+		{profile.ID, profile.Username, profile.Email, profile.DisplayName, profile.Bio, profile.AvatarURL, formatExportTime(profile.CreatedAt)},
+	}); err != nil {
+		return nil, err
+	}
+
+	monitorRows := make([][]string, 0, len(exportMonitors))
+	var logRows [][]string
+	for _, monitor := range exportMonitors {
+		monitorRows = append(monitorRows, []string{
+			monitor.ID,
+			monitor.Name,
+			monitor.URL,
+			strconv.Itoa(monitor.IntervalSeconds),
+			strconv.FormatBool(monitor.IsActive),
+			formatExportTime(monitor.CreatedAt),
+		})
+		for _, entry := range monitor.Logs {
+			logRows = append(logRows, []string{
+				entry.ID,
+				entry.MonitorID,
+				entry.Status,
+				strconv.Itoa(entry.StatusCode),
+				strconv.FormatInt(entry.ResponseTimeMs, 10),
+				entry.ErrorMessage,
+				formatExportTime(entry.CheckedAt),
+			})
+		}
+	}
+	if err := writeCSVFile(zw, "monitors.csv", []string{"id", "name", "url", "interval_seconds", "is_active", "created_at"}, monitorRows); err != nil {
+		return nil, err
+	}
+	if err := writeCSVFile(zw, "monitor_logs.csv", []string{"id", "monitor_id", "status", "status_code", "response_time_ms", "error_message", "checked_at"}, logRows); err != nil {
+		return nil, err
+	}
+
+	postRows := make([][]string, 0, len(posts))
+	for _, post := range posts {
+		postRows = append(postRows, []string{post.ID, post.Title, post.Slug, post.Content, formatExportTime(post.CreatedAt)})
+	}
+	if err := writeCSVFile(zw, "posts.csv", []string{"id", "title", "slug", "content", "created_at"}, postRows); err != nil {
+		return nil, err
+	}
+
+	snippetRows := make([][]string, 0, len(snippetResponses))
+	for _, snippet := range snippetResponses {
+		expiresAt := ""
+		if snippet.ExpiresAt != nil {
+			expiresAt = formatExportTime(*snippet.ExpiresAt)
+		}
+		snippetRows = append(snippetRows, []string{
+			snippet.ID,
+			snippet.Hash,
+			snippet.Content,
+			strconv.FormatBool(snippet.HasPassword),
+			strconv.FormatBool(snippet.BurnAfterRead),
+			expiresAt,
+			formatExportTime(snippet.CreatedAt),
+		})
+	}
+	if err := writeCSVFile(zw, "snippets.csv", []string{"id", "hash", "content", "has_password", "burn_after_read", "expires_at", "created_at"}, snippetRows); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeJSONFile(zw *zip.Writer, name string, data any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+func writeCSVFile(zw *zip.Writer, name string, header []string, rows [][]string) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func formatExportTime(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"learn/internal/models"
+	"learn/internal/repository"
+)
+
+var (
+	ErrHeartbeatNotFound = errors.New("heartbeat monitor not found")
+	ErrHeartbeatPaused   = errors.New("heartbeat monitor is paused")
+)
+
+const MaxPingOutputBytes = 10 << 10
+
+type HeartbeatService struct {
+	monitors repository.MonitorRepository
+	recorder *CheckRecorder
+}
+
+func NewHeartbeatService(monitors repository.MonitorRepository, recorder *CheckRecorder) *HeartbeatService {
+	return &HeartbeatService{monitors: monitors, recorder: recorder}
+}
+
+func (s *HeartbeatService) Ping(ctx context.Context, token, event string, exitCode *int, output string) error {
+	monitor, err := s.monitors.GetByPingToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrHeartbeatNotFound
+		}
+		return err
+	}
+	if monitor.Type != models.MonitorTypeHeartbeat {
+		return ErrHeartbeatNotFound
+	}
+	if !monitor.IsActive {
+		return ErrHeartbeatPaused
+	}
+
+	now := time.Now()
+	if event == models.PingEventStart {
+		return s.monitors.RecordPingStart(ctx, monitor.ID, now)
+	}
+
+	details := map[string]string{}
+	entry := models.MonitorLog{
+		ID:        uuid.NewString(),
+		MonitorID: monitor.ID,
+		Status:    models.MonitorStatusUp,
+		Details:   details,
+	}
+
+	if exitCode != nil {
+		details["exit_code"] = strconv.Itoa(*exitCode)
+		if *exitCode != 0 {
+			event = models.PingEventFail
+			entry.ErrorMessage = fmt.Sprintf("job exited with code %d", *exitCode)
+		}
+	}
+	if event == models.PingEventFail {
+		entry.Status = models.MonitorStatusDown
+		if entry.ErrorMessage == "" {
+			entry.ErrorMessage = "job reported failure"
+		}
+	}
+	details["event"] = event
+	if output != "" {
+		details["output"] = truncateOutput(output)
+	}
+	if monitor.PingStartedAt != nil {
+		entry.ResponseTimeMs = now.Sub(*monitor.PingStartedAt).Milliseconds()
+	}
+
+	if err := s.monitors.RecordPing(ctx, monitor.ID, now); err != nil {
+		return err
+	}
+	_, _, err = s.recorder.Record(ctx, monitor, entry)
+	return err
+}
+
+func truncateOutput(output string) string {
+	if len(output) <= MaxPingOutputBytes {
+		return output
+	}
+	return strings.ToValidUTF8(output[:MaxPingOutputBytes], "") + "\n[truncated]"
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+
+	"learn/internal/models"
+)
+
+const maxRedirects = 10
+
+type HTTPChecker struct {
+	client           *http.Client
+	noRedirectClient *http.Client
+}
+
+func NewHTTPChecker(timeout time.Duration, egress *EgressPolicy) *HTTPChecker {
+	// Fresh connections keep DNS, connect and TLS timings comparable between
+	// checks, and mean every redirect hop is dialed and checked against the
+	// egress policy. A proxy would connect on the check's behalf, so none is used.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: timeout, Control: egress.Control}).DialContext
+
+	return &HTTPChecker{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxRedirects)
+				}
+				return egress.CheckHost(req.URL.Hostname())
+			},
+		},
+		noRedirectClient: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (c *HTTPChecker) Check(ctx context.Context, monitor models.Monitor) CheckResult {
+	start := time.Now()
+
+	var body io.Reader
+	if monitor.Body != "" {
+		body = strings.NewReader(monitor.Body)
+	}
+
+	trace := &phaseTrace{}
+	ctx = httptrace.WithClientTrace(ctx, trace.clientTrace())
+
+	req, err := http.NewRequestWithContext(ctx, monitor.Method, monitor.URL, body)
+	if err != nil {
+		return CheckResult{Status: models.MonitorStatusDown, Message: err.Error()}
+	}
+
+	req.Header.Set("User-Agent", "UptimeNinja/1.0")
+	for _, header := range monitor.Headers {
+		if header.Unreadable {
+			continue
+		}
+		if strings.EqualFold(header.Name, "Host") {
+			req.Host = header.Value
+			continue
+		}
+		req.Header.Set(header.Name, header.Value)
+	}
+
+	client := c.client
+	if !monitor.FollowRedirects {
+		client = c.noRedirectClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return CheckResult{Status: models.MonitorStatusDown, ResponseTime: time.Since(start), Message: err.Error(), Timings: trace.timings(time.Now())}
+	}
+	defer resp.Body.Close()
+
+	maxBytes := monitor.MaxResponseBytes
+	if maxBytes <= 0 {
+		maxBytes = models.DefaultMonitorMaxResponseBytes
+	}
+	responseBody, size, err := readBody(resp.Body, maxBytes, bodySizeLimit(monitor.Assertions))
+	finished := time.Now()
+
+	result := CheckResult{
+		Status:       models.MonitorStatusDown,
+		StatusCode:   resp.StatusCode,
+		ResponseTime: finished.Sub(start),
+		Timings:      trace.timings(finished),
+	}
+
+	if !models.StatusAccepted(monitor.AcceptedStatuses, resp.StatusCode) {
+		result.Message = fmt.Sprintf("unexpected status code %d (accepted: %s)", resp.StatusCode, monitor.AcceptedStatuses)
+		return result
+	}
+	if err != nil {
+		result.Message = "reading response body: " + err.Error()
+		return result
+	}
+
+	if failure := evaluateAssertions(monitor.Assertions, resp.Header, responseBody, size); failure != "" {
+		result.Message = failure
+		return result
+	}
+
+	result.Status = models.MonitorStatusUp
+	return result
+}
+
+// readBody keeps the first maxBytes of the body for assertions. When a size
+// limit is asserted it goes on counting, up to just past that limit, so the
+// size can be compared without holding the whole body.
+func readBody(body io.Reader, maxBytes, sizeLimit int64) ([]byte, int64, error) {
+	kept, err := io.ReadAll(io.LimitReader(body, maxBytes))
+	size := int64(len(kept))
+	if err != nil || sizeLimit == 0 || size > sizeLimit {
+		return kept, size, err
+	}
+	rest, err := io.Copy(io.Discard, io.LimitReader(body, sizeLimit-size+1))
+	return kept, size + rest, err
+}
+
+// phaseTrace collects httptrace callbacks for one check. Durations are summed
+// so that every hop of a redirect chain is accounted for, while time to first
+// byte and transfer are measured for the final response.
+type phaseTrace struct {
+	mu           sync.Mutex
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	dns          time.Duration
+	connect      time.Duration
+	tls          time.Duration
+	ttfb         time.Duration
+}
+
+func (t *phaseTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dns += time.Since(t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.connectStart = time.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.connect += time.Since(t.connectStart)
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tls += time.Since(t.tlsStart)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.wroteRequest = time.Now()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.firstByte = time.Now()
+			t.ttfb = t.firstByte.Sub(t.wroteRequest)
+		},
+	}
+}
+
+func (t *phaseTrace) timings(finished time.Time) *models.CheckTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timings := &models.CheckTimings{
+		DNSMs:     t.dns.Milliseconds(),
+		ConnectMs: t.connect.Milliseconds(),
+		TLSMs:     t.tls.Milliseconds(),
+		TTFBMs:    t.ttfb.Milliseconds(),
+	}
+	if !t.firstByte.IsZero() {
+		timings.TransferMs = finished.Sub(t.firstByte).Milliseconds()
+	}
+	return timings
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"learn/internal/models"
+	"learn/internal/repository"
+)
+
+var ErrIncidentNotFound = errors.New("incident not found")
+
+const maxIncidentList = 100
+
+type IncidentService struct {
+	incidents     repository.IncidentRepository
+	monitors      repository.MonitorRepository
+	oncall        repository.OnCallRepository
+	notifications repository.NotificationRepository
+	events        *MonitorEvents
+	audit         *AuditService
+}
+
+func NewIncidentService(incidents repository.IncidentRepository, monitors repository.MonitorRepository, oncall repository.OnCallRepository, notifications repository.NotificationRepository, events *MonitorEvents, audit *AuditService) *IncidentService {
+	return &IncidentService{incidents: incidents, monitors: monitors, oncall: oncall, notifications: notifications, events: events, audit: audit}
+}
+
+// Observe opens an incident when a monitor's confirmed status turns down,
+// counts checks while it stays down and resolves it once the monitor recovers.
+func (s *IncidentService) Observe(ctx context.Context, monitor models.Monitor, state models.MonitorState, entry models.MonitorLog) error {
+	wasDown := monitor.Status == models.MonitorStatusDown
+	isDown := state.Status == models.MonitorStatusDown
+	if !wasDown && !isDown {
+		return nil
+	}
+
+	if isDown && !wasDown {
+		incident := models.Incident{
+			ID:         uuid.NewString(),
+			MonitorID:  monitor.ID,
+			Status:     models.IncidentStatusOpen,
+			StartedAt:  *state.StatusChangedAt,
+			FirstError: entry.ErrorMessage,
+			CheckCount: state.ConsecutiveFailures,
+		}
+		if err := s.startEscalation(ctx, monitor, &incident); err != nil {
+			return err
+		}
+		if err := s.incidents.Create(ctx, incident); err != nil {
+			if errors.Is(err, repository.ErrIncidentOpen) {
+				return nil
+			}
+			return err
+		}
+		incident.MonitorName = monitor.Name
+		s.events.Publish(ctx, monitorEvent(monitor, models.MonitorEventIncident, incident))
+		return nil
+	}
+
+	open, err := s.incidents.GetOpenByMonitor(ctx, monitor.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if isDown {
+		return s.incidents.RecordCheck(ctx, open.ID)
+	}
+	if err := s.incidents.Resolve(ctx, open.ID, *state.StatusChangedAt); err != nil {
+		return err
+	}
+
+	open.Status = models.IncidentStatusResolved
+	open.ResolvedAt = state.StatusChangedAt
+	open.DurationSeconds = int64(state.StatusChangedAt.Sub(open.StartedAt).Seconds())
+	open.CheckCount++
+	open.NextEscalationAt = nil
+	s.events.Publish(ctx, monitorEvent(monitor, models.MonitorEventIncident, open))
+	return nil
+}
+
+// startEscalation schedules the first step of the monitor's escalation
+// policy, if it has one.
+func (s *IncidentService) startEscalation(ctx context.Context, monitor models.Monitor, incident *models.Incident) error {
+	if monitor.EscalationPolicyID == "" {
+		return nil
+	}
+	policy, err := s.oncall.GetPolicy(ctx, monitor.EscalationPolicyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if len(policy.Steps) == 0 {
+		return nil
+	}
+
+	next := incident.StartedAt.Add(time.Duration(policy.Steps[0].DelayMinutes) * time.Minute)
+	incident.EscalationPolicyID = policy.ID
+	incident.NextEscalationAt = &next
+	return nil
+}
+
+func (s *IncidentService) List(ctx context.Context, userID, status string) ([]models.Incident, error) {
+	return s.incidents.ListByUser(ctx, userID, status, maxIncidentList)
+}
+
+func (s *IncidentService) ListByMonitor(ctx context.Context, userID, monitorID string) ([]models.Incident, error) {
+	if _, err := s.monitors.GetByID(ctx, userID, monitorID); err != nil {
+		return nil, err
+	}
+	return s.incidents.ListByMonitor(ctx, monitorID, maxIncidentList)
+}
+
+// ListDeliveries returns the escalation alerts sent for an incident.
+func (s *IncidentService) ListDeliveries(ctx context.Context, userID, id string) ([]models.NotificationDelivery, error) {
+	if _, err := s.incidents.GetByID(ctx, userID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrIncidentNotFound
+		}
+		return nil, err
+	}
+	return s.notifications.ListIncidentDeliveries(ctx, id, maxDeliveryList)
+}
+
+func (s *IncidentService) Acknowledge(ctx context.Context, userID, id, notes string) (models.Incident, error) {
+	before, err := s.incidents.GetByID(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Incident{}, ErrIncidentNotFound
+		}
+		return models.Incident{}, err
+	}
+
+	updated, err := s.incidents.Acknowledge(ctx, userID, id, notes, time.Now())
+	if err != nil {
+		return models.Incident{}, err
+	}
+	if !updated {
+		return models.Incident{}, ErrMonitorForbidden
+	}
+
+	incident, err := s.incidents.GetByID(ctx, userID, id)
+	if err != nil {
+		return models.Incident{}, err
+	}
+
+	if monitor, err := s.monitors.GetByIDUnscoped(ctx, incident.MonitorID); err == nil {
+		s.events.Publish(ctx, monitorEvent(monitor, models.MonitorEventIncident, incident))
+	} else {
+		log.Printf("Error fetching monitor %s for incident event: %v", incident.MonitorID, err)
+	}
+
+	s.audit.Record(ctx, userID, models.AuditActionIncidentAcknowledged, "incident", id,
+		map[string]any{"acknowledged_by": before.AcknowledgedBy, "notes": before.Notes},
+		map[string]any{"acknowledged_by": incident.AcknowledgedBy, "notes": incident.Notes})
+	return incident, nil
+}
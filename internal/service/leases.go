@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"learn/internal/models"
+	"learn/internal/repository"
+)
+
+const jobLeaseTTL = time.Minute
+
+// Leases coordinate processes sharing one database. Each monitor check is
+// claimed by a single worker, and background jobs that must not run twice,
+// like alert delivery, only run in the process holding the job's lease.
+type Leases struct {
+	leases   repository.LeaseRepository
+	workerID string
+}
+
+func NewLeases(leases repository.LeaseRepository, workerID string) *Leases {
+	return &Leases{leases: leases, workerID: workerID}
+}
+
+// Hold claims the job's lease for ttl, or renews it if this process already
+// holds it. A holder that stops renewing loses the lease once ttl has passed.
+func (l *Leases) Hold(ctx context.Context, job string, ttl time.Duration) bool {
+	now := time.Now()
+	held, err := l.leases.ClaimJob(ctx, job, l.workerID, now.Add(ttl), now)
+	if err != nil {
+		log.Printf("Error claiming %s lease: %v", job, err)
+		return false
+	}
+	return held
+}
+
+// Release gives up this process's job leases so another can take over without
+// waiting for them to expire.
+func (l *Leases) Release() {
+	if err := l.leases.ReleaseJobs(context.Background(), l.workerID); err != nil {
+		log.Printf("Error releasing leases: %v", err)
+	}
+}
+
+func (l *Leases) claimCheck(ctx context.Context, monitor models.Monitor, nextCheckAt, leasedUntil time.Time) (bool, error) {
+	return l.leases.ClaimCheck(ctx, monitor, l.workerID, nextCheckAt, leasedUntil, time.Now())
+}
+
+func (l *Leases) releaseCheck(monitorID string) {
+	if err := l.leases.ReleaseCheck(context.Background(), monitorID, l.workerID); err != nil {
+		log.Printf("Error releasing lease on monitor %s: %v", monitorID, err)
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"learn/internal/models"
+	"learn/internal/repository"
+)
+
+// minLogRetention keeps raw logs around long enough for the latency stats and
+// recent log lists, which always read them.
+const minLogRetention = 48 * time.Hour
+
+// LogCompactor rolls raw check logs up into hourly and daily buckets and then
+// deletes logs older than the retention. Logs are only deleted once both
+// rollups cover them.
+type LogCompactor struct {
+	ctx             context.Context
+	cancel          context.CancelFunc
+	wg              sync.WaitGroup
+	monitors        repository.MonitorRepository
+	rollups         repository.RollupRepository
+	leases          *Leases
+	interval        time.Duration
+	logRetention    time.Duration
+	hourlyRetention time.Duration
+}
+
+func NewLogCompactor(monitors repository.MonitorRepository, rollups repository.RollupRepository, leases *Leases, interval, logRetention, hourlyRetention time.Duration) *LogCompactor {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &LogCompactor{
+		ctx:             ctx,
+		cancel:          cancel,
+		monitors:        monitors,
+		rollups:         rollups,
+		leases:          leases,
+		interval:        interval,
+		logRetention:    max(logRetention, minLogRetention),
+		hourlyRetention: hourlyRetention,
+	}
+}
+
+func (c *LogCompactor) Start() {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			if c.leases.Hold(c.ctx, "log_compaction", c.interval+jobLeaseTTL) {
+				if err := c.Compact(c.ctx, time.Now()); err != nil && !errors.Is(err, context.Canceled) {
+					log.Printf("Error compacting monitor logs: %v", err)
+				}
+			}
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (c *LogCompactor) Stop() {
+	c.cancel()
+	c.wg.Wait()
+}
+
+// Compact rolls up every bucket that ended before now and applies the
+// retention policies.
+func (c *LogCompactor) Compact(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+	deleteBefore := now.Add(-c.logRetention)
+	for _, granularity := range []string{models.RollupHour, models.RollupDay} {
+		rolledUntil, err := c.rollUp(ctx, granularity, now.Truncate(models.RollupSize(granularity)))
+		if err != nil {
+			return err
+		}
+		deleteBefore = earlier(deleteBefore, rolledUntil)
+	}
+
+	deleted, err := c.monitors.DeleteLogsBefore(ctx, deleteBefore)
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Printf("Compacted %d monitor logs older than %s", deleted, deleteBefore.Format(time.RFC3339))
+	}
+
+	if c.hourlyRetention > 0 {
+		if _, err := c.rollups.DeleteBefore(ctx, models.RollupHour, now.Add(-c.hourlyRetention)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollUp builds the buckets between the last rollup and end a day at a time
+// and returns how far it got.
+func (c *LogCompactor) rollUp(ctx context.Context, granularity string, end time.Time) (time.Time, error) {
+	start, err := c.rollups.RolledUntil(ctx, granularity)
+	if errors.Is(err, sql.ErrNoRows) {
+		start, err = c.monitors.GetOldestCheck(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return end, c.rollups.Save(ctx, granularity, nil, end)
+		}
+		start = start.Truncate(models.RollupSize(granularity))
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for start.Before(end) {
+		chunkEnd := earlier(start.Add(24*time.Hour), end)
+		monitorIDs, err := c.monitors.ListLoggedMonitorIDs(ctx, start, chunkEnd)
+		if err != nil {
+			return start, err
+		}
+
+		var rollups []models.MonitorRollup
+		for _, monitorID := range monitorIDs {
+			logs, err := c.monitors.ListLogsBetween(ctx, monitorID, start, chunkEnd)
+			if err != nil {
+				return start, err
+			}
+			rollups = append(rollups, rollupLogs(logs, granularity)...)
+		}
+		if err := c.rollups.Save(ctx, granularity, rollups, chunkEnd); err != nil {
+			return start, err
+		}
+		start = chunkEnd
+	}
+	return start, nil
+}
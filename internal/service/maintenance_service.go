@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"learn/internal/models"
+	"learn/internal/repository"
+)
+
+var (
+	ErrMaintenanceNotFound        = errors.New("maintenance window not found")
+	ErrInvalidMaintenanceWindow   = errors.New("invalid maintenance window")
+	ErrMaintenanceMonitorMismatch = errors.New("monitors must belong to the maintenance window's organization")
+)
+
+type MaintenanceService struct {
+	maintenance repository.MaintenanceRepository
+	monitors    *MonitorService
+	audit       *AuditService
+}
+
+func NewMaintenanceService(maintenance repository.MaintenanceRepository, monitors *MonitorService, audit *AuditService) *MaintenanceService {
+	return &MaintenanceService{maintenance: maintenance, monitors: monitors, audit: audit}
+}
+
+func (s *MaintenanceService) Create(ctx context.Context, userID string, window models.MaintenanceWindow) (models.MaintenanceWindow, error) {
+	if window.Timezone == "" {
+		window.Timezone = "UTC"
+	}
+	if window.Mode == "" {
+		window.Mode = models.MaintenanceModePause
+	}
+	if err := window.Validate(); err != nil {
+		return models.MaintenanceWindow{}, fmt.Errorf("%w: %v", ErrInvalidMaintenanceWindow, err)
+	}
+
+	if window.OrganizationID != "" {
+		if err := s.monitors.requireOrgWriter(ctx, userID, window.OrganizationID); err != nil {
+			return models.MaintenanceWindow{}, err
+		}
+	}
+	for _, monitorID := range window.MonitorIDs {
+		monitor, err := s.monitors.monitors.GetByID(ctx, userID, monitorID)
+		if err != nil {
+			return models.MaintenanceWindow{}, err
+		}
+		if monitor.OrganizationID != window.OrganizationID {
+			return models.MaintenanceWindow{}, ErrMaintenanceMonitorMismatch
+		}
+		if err := s.monitors.authorizeWrite(ctx, userID, monitor); err != nil {
+			return models.MaintenanceWindow{}, err
+		}
+	}
+
+	window.ID = uuid.NewString()
+	window.UserID = userID
+	created, err := s.maintenance.Create(ctx, window)
+	if err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+
+	s.audit.Record(ctx, userID, models.AuditActionMaintenanceCreated, "maintenance_window", created.ID, nil, created)
+	return created, nil
+}
+
+func (s *MaintenanceService) List(ctx context.Context, userID string) ([]models.MaintenanceWindow, error) {
+	return s.maintenance.ListByUser(ctx, userID)
+}
+
+func (s *MaintenanceService) Get(ctx context.Context, userID, id string) (models.MaintenanceWindow, error) {
+	window, err := s.maintenance.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.MaintenanceWindow{}, ErrMaintenanceNotFound
+		}
+		return models.MaintenanceWindow{}, err
+	}
+	if !s.monitors.canView(ctx, userID, window.UserID, window.OrganizationID) {
+		return models.MaintenanceWindow{}, ErrMaintenanceNotFound
+	}
+	return window, nil
+}
+
+func (s *MaintenanceService) Delete(ctx context.Context, userID, id string) error {
+	window, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if window.OrganizationID != "" {
+		if err := s.monitors.requireOrgWriter(ctx, userID, window.OrganizationID); err != nil {
+			return err
+		}
+	}
+
+	deleted, err := s.maintenance.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrMaintenanceNotFound
+	}
+
+	s.audit.Record(ctx, userID, models.AuditActionMaintenanceDeleted, "maintenance_window", id, window, nil)
+	return nil
+}
+
+// Active reports whether a monitor is in maintenance at t. When several
+// windows overlap, pausing wins over recording and the returned end is the
+// latest of their ends.
+func (s *MaintenanceService) Active(ctx context.Context, monitorID string, at time.Time) (mode string, end time.Time, active bool, err error) {
+	windows, err := s.maintenance.ListByMonitor(ctx, monitorID)
+	if err != nil {
+		return "", time.Time{}, false, err
+	}
+
+	for _, window := range windows {
+		_, windowEnd, ok := window.OccurrenceAt(at)
+		if !ok {
+			continue
+		}
+		if !active || window.Mode == models.MaintenanceModePause {
+			mode = window.Mode
+		}
+		if windowEnd.After(end) {
+			end = windowEnd
+		}
+		active = true
+	}
+	return mode, end, active, nil
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"learn/internal/models"
+)
+
+var ErrInvalidMonitorAssertion = errors.New("invalid monitor assertion")
+
+// maxBodySizeLimit caps body_max_bytes, since a check reads up to that much
+// of the body to measure it.
+const maxBodySizeLimit = 10 << 20
+
+func validateAssertions(assertions []models.MonitorAssertion) error {
+	for _, assertion := range assertions {
+		switch assertion.Type {
+		case models.AssertionBodyContains, models.AssertionBodyNotContains:
+			if assertion.Value == "" {
+				return fmt.Errorf("%w: %s needs a value", ErrInvalidMonitorAssertion, assertion.Type)
+			}
+		case models.AssertionBodyRegex:
+			if _, err := regexp.Compile(assertion.Value); err != nil {
+				return fmt.Errorf("%w: invalid regex %q", ErrInvalidMonitorAssertion, assertion.Value)
+			}
+		case models.AssertionJSONPathEquals, models.AssertionJSONPathExists:
+			if _, err := parseJSONPath(assertion.Target); err != nil {
+				return fmt.Errorf("%w: invalid JSON path %q", ErrInvalidMonitorAssertion, assertion.Target)
+			}
+		case models.AssertionHeaderMatches:
+			if assertion.Target == "" {
+				return fmt.Errorf("%w: header_matches needs a header name", ErrInvalidMonitorAssertion)
+			}
+			if _, err := regexp.Compile(assertion.Value); err != nil {
+				return fmt.Errorf("%w: invalid regex %q", ErrInvalidMonitorAssertion, assertion.Value)
+			}
+		case models.AssertionBodyMaxBytes:
+			if limit, err := strconv.ParseInt(assertion.Value, 10, 64); err != nil || limit < 1 {
+				return fmt.Errorf("%w: body_max_bytes needs a positive number of bytes", ErrInvalidMonitorAssertion)
+			} else if limit > maxBodySizeLimit {
+				return fmt.Errorf("%w: body_max_bytes can be at most %d", ErrInvalidMonitorAssertion, maxBodySizeLimit)
+			}
+		default:
+			return fmt.Errorf("%w: unknown type %q", ErrInvalidMonitorAssertion, assertion.Type)
+		}
+	}
+	return nil
+}
+
+// bodySizeLimit is the smallest body_max_bytes assertion, or 0 without one.
+// Monitors saved before the cap was enforced are held to it as well.
+func bodySizeLimit(assertions []models.MonitorAssertion) int64 {
+	var smallest int64
+	for _, assertion := range assertions {
+		if assertion.Type != models.AssertionBodyMaxBytes {
+			continue
+		}
+		if limit, err := strconv.ParseInt(assertion.Value, 10, 64); err == nil && (smallest == 0 || limit < smallest) {
+			smallest = limit
+		}
+	}
+	return min(smallest, maxBodySizeLimit)
+}
+
+// evaluateAssertions returns a description of the first assertion that does
+// not hold, or an empty string when all of them pass. body may be cut short;
+// size is the full length, as far as it was counted.
+func evaluateAssertions(assertions []models.MonitorAssertion, header http.Header, body []byte, size int64) string {
+	var document any
+	var documentErr error
+	decoded := false
+
+	for _, assertion := range assertions {
+		switch assertion.Type {
+		case models.AssertionBodyContains:
+			if !bytes.Contains(body, []byte(assertion.Value)) {
+				return fmt.Sprintf("assertion failed: body does not contain %q", assertion.Value)
+			}
+		case models.AssertionBodyNotContains:
+			if bytes.Contains(body, []byte(assertion.Value)) {
+				return fmt.Sprintf("assertion failed: body contains %q", assertion.Value)
+			}
+		case models.AssertionBodyRegex:
+			pattern, err := regexp.Compile(assertion.Value)
+			if err != nil || !pattern.Match(body) {
+				return fmt.Sprintf("assertion failed: body does not match /%s/", assertion.Value)
+			}
+		case models.AssertionJSONPathEquals, models.AssertionJSONPathExists:
+			if !decoded {
+				decoder := json.NewDecoder(bytes.NewReader(body))
+				decoder.UseNumber()
+				documentErr = decoder.Decode(&document)
+				decoded = true
+			}
+			if documentErr != nil {
+				return "assertion failed: body is not valid JSON"
+			}
+			value, found := lookupJSONPath(document, assertion.Target)
+			if !found {
+				return fmt.Sprintf("assertion failed: %s does not exist", assertion.Target)
+			}
+			if assertion.Type == models.AssertionJSONPathEquals && !jsonValueEquals(value, assertion.Value) {
+				return fmt.Sprintf("assertion failed: %s is %s, expected %s", assertion.Target, jsonValueString(value), assertion.Value)
+			}
+		case models.AssertionHeaderMatches:
+			pattern, err := regexp.Compile(assertion.Value)
+			actual := header.Get(assertion.Target)
+			if err != nil || !pattern.MatchString(actual) {
+				return fmt.Sprintf("assertion failed: header %s %q does not match /%s/", assertion.Target, actual, assertion.Value)
+			}
+		case models.AssertionBodyMaxBytes:
+			limit, err := strconv.ParseInt(assertion.Value, 10, 64)
+			if err != nil || size > limit {
+				return fmt.Sprintf("assertion failed: body is larger than %s bytes", assertion.Value)
+			}
+		}
+	}
+	return ""
+}
+
+type jsonPathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath supports the dot and bracket subset of JSONPath, e.g.
+// $.data.items[0].status or $["content-type"].
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(path), "$")
+	if !ok {
+		return nil, errors.New("path must start with $")
+	}
+
+	var steps []jsonPathStep
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, errors.New("empty key")
+			}
+			steps = append(steps, jsonPathStep{key: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, errors.New("unterminated bracket")
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if unquoted, err := strconv.Unquote(inner); err == nil {
+				steps = append(steps, jsonPathStep{key: unquoted})
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil || index < 0 {
+				return nil, errors.New("invalid index")
+			}
+			steps = append(steps, jsonPathStep{index: index, isIndex: true})
+		default:
+			return nil, errors.New("unexpected character")
+		}
+	}
+	return steps, nil
+}
+
+func lookupJSONPath(document any, path string) (any, bool) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false
+	}
+
+	current := document
+	for _, step := range steps {
+		if step.isIndex {
+			list, ok := current.([]any)
+			if !ok || step.index >= len(list) {
+				return nil, false
+			}
+			current = list[step.index]
+			continue
+		}
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[step.key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func jsonValueEquals(value any, expected string) bool {
+	if number, ok := value.(json.Number); ok {
+		actual, err1 := number.Float64()
+		want, err2 := strconv.ParseFloat(expected, 64)
+		if err1 == nil && err2 == nil {
+			return actual == want
+		}
+	}
+	return jsonValueString(value) == expected
+}
+
+func jsonValueString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return "null"
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	}
+}
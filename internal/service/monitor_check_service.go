@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"learn/internal/models"
+	"learn/internal/repository"
+)
+
+var (
+	ErrMonitorNotCheckable = errors.New("heartbeat monitors are checked by their pings")
+	ErrMonitorPaused       = errors.New("monitor is paused")
+)
+
+// MonitorCheckService runs checks on demand, outside the schedule.
+type MonitorCheckService struct {
+	monitors repository.MonitorRepository
+	service  *MonitorService
+	recorder *CheckRecorder
+	checkers map[string]Checker
+}
+
+func NewMonitorCheckService(monitors repository.MonitorRepository, service *MonitorService, recorder *CheckRecorder) *MonitorCheckService {
+	return &MonitorCheckService{monitors: monitors, service: service, recorder: recorder, checkers: newCheckers(service.egress)}
+}
+
+// Check runs a saved monitor's check once, without retries, and records it
+// as a manual check from this server. The result counts towards the monitor's
+// state like a scheduled check does, so paused monitors can't be checked.
+func (s *MonitorCheckService) Check(ctx context.Context, userID, id string) (models.MonitorLog, models.MonitorState, error) {
+	monitor, err := s.monitors.GetByID(ctx, userID, id)
+	if err != nil {
+		return models.MonitorLog{}, models.MonitorState{}, err
+	}
+	if err := s.service.authorizeWrite(ctx, userID, monitor); err != nil {
+		return models.MonitorLog{}, models.MonitorState{}, err
+	}
+	if monitor.Type == models.MonitorTypeHeartbeat {
+		return models.MonitorLog{}, models.MonitorState{}, ErrMonitorNotCheckable
+	}
+	if !monitor.IsActive {
+		return models.MonitorLog{}, models.MonitorState{}, ErrMonitorPaused
+	}
+
+	entry := s.run(ctx, monitor)
+	if err := ctx.Err(); err != nil {
+		return models.MonitorLog{}, models.MonitorState{}, err
+	}
+	entry.ID = uuid.NewString()
+	entry.MonitorID = monitor.ID
+	entry.Manual = true
+
+	state, _, err := s.recorder.Record(ctx, monitor, entry)
+	if err != nil {
+		return models.MonitorLog{}, models.MonitorState{}, err
+	}
+	return entry, state, nil
+}
+
+// Test runs a check for a monitor that hasn't been saved, with the same
+// defaults and validation as creating one. Nothing is stored.
+func (s *MonitorCheckService) Test(ctx context.Context, monitor models.Monitor) (models.MonitorLog, error) {
+	applyMonitorDefaults(&monitor)
+	if monitor.Type == models.MonitorTypeHeartbeat {
+		return models.MonitorLog{}, ErrMonitorNotCheckable
+	}
+	if err := prepareMonitorType(&monitor); err != nil {
+		return models.MonitorLog{}, err
+	}
+	if err := s.service.egress.CheckMonitor(monitor); err != nil {
+		return models.MonitorLog{}, err
+	}
+	if err := validateAssertions(monitor.Assertions); err != nil {
+		return models.MonitorLog{}, err
+	}
+	headers, err := mergeSecretHeaders(monitor.Headers, nil)
+	if err != nil {
+		return models.MonitorLog{}, err
+	}
+	monitor.Headers = headers
+
+	entry := s.run(ctx, monitor)
+	if err := ctx.Err(); err != nil {
+		return models.MonitorLog{}, err
+	}
+	return entry, nil
+}
+
+func (s *MonitorCheckService) run(ctx context.Context, monitor models.Monitor) models.MonitorLog {
+	result := CheckResult{Status: models.MonitorStatusDown, Message: "unsupported monitor type " + monitor.Type}
+	if checker, ok := s.checkers[monitor.Type]; ok {
+		result = checker.Check(ctx, monitor)
+	}
+
+	return models.MonitorLog{
+		Status:         result.Status,
+		StatusCode:     result.StatusCode,
+		ResponseTimeMs: result.ResponseTime.Milliseconds(),
+		ErrorMessage:   result.Message,
+		Details:        result.Details,
+		Timings:        result.Timings,
+		Location:       models.LocationLocal,
+		CheckedAt:      time.Now().UTC().Truncate(time.Second),
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"learn/internal/models"
+	"learn/internal/repository"
+)
+
+const (
+	monitorEventReplayLimit      = 1000
+	monitorEventSubscriberBuffer = 64
+	monitorEventBatchSize        = 500
+	monitorEventPollInterval     = time.Second
+	monitorEventRetention        = time.Hour
+)
+
+// MonitorEvents carries monitor events from the process that produced them,
+// often a worker, to the processes serving event streams. Events are stored
+// in the database, and every process that has started the hub polls for new
+// ones and fans them out to its subscribers. The last hour of events is kept
+// so a reconnecting client can catch up on what it missed.
+type MonitorEvents struct {
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	wake        chan struct{}
+	events      repository.MonitorEventRepository
+	mu          sync.Mutex
+	lastID      uint64
+	subscribers map[*MonitorSubscription]struct{}
+}
+
+type MonitorSubscription struct {
+	hub    *MonitorEvents
+	events chan models.MonitorEvent
+	filter func(models.MonitorEvent) bool
+	since  uint64
+}
+
+func NewMonitorEvents(events repository.MonitorEventRepository) *MonitorEvents {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &MonitorEvents{
+		ctx:         ctx,
+		cancel:      cancel,
+		wake:        make(chan struct{}, 1),
+		events:      events,
+		subscribers: make(map[*MonitorSubscription]struct{}),
+	}
+}
+
+// Start delivers events published from now on, by any process, to this
+// process's subscribers. Processes that don't serve streams only publish and
+// needn't start it.
+func (e *MonitorEvents) Start() error {
+	_, latest, err := e.events.Bounds(e.ctx)
+	if err != nil {
+		return err
+	}
+	e.lastID = latest
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(monitorEventPollInterval)
+		defer ticker.Stop()
+		lastPrune := time.Time{}
+
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case <-ticker.C:
+			case <-e.wake:
+			}
+			e.poll()
+
+			if time.Since(lastPrune) >= monitorEventRetention/4 {
+				lastPrune = time.Now()
+				if _, err := e.events.DeleteBefore(e.ctx, lastPrune.Add(-monitorEventRetention)); err != nil {
+					log.Printf("Error cleaning old monitor events: %v", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (e *MonitorEvents) Stop() {
+	e.cancel()
+	e.wg.Wait()
+}
+
+// Publish stores the event for every process's subscribers. A failure is only
+// logged: the change the event describes has already been saved, and stream
+// clients pick it up when they reload.
+func (e *MonitorEvents) Publish(ctx context.Context, event models.MonitorEvent) {
+	if err := e.events.Append(ctx, event, time.Now()); err != nil {
+		log.Printf("Error storing %s event for monitor %s: %v", event.Type, event.MonitorID, err)
+		return
+	}
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+// poll hands events stored since the last poll to matching subscribers. A
+// subscriber too far behind to take one is dropped rather than holding up
+// everyone else; its client can resume from the stored events.
+func (e *MonitorEvents) poll() {
+	for {
+		events, err := e.events.ListAfter(e.ctx, e.lastID, monitorEventBatchSize)
+		if err != nil {
+			if e.ctx.Err() == nil {
+				log.Printf("Error fetching monitor events: %v", err)
+			}
+			return
+		}
+
+		e.mu.Lock()
+		for _, event := range events {
+			for sub := range e.subscribers {
+				if !sub.filter(event) {
+					continue
+				}
+				select {
+				case sub.events <- event:
+				default:
+					delete(e.subscribers, sub)
+					close(sub.events)
+				}
+			}
+			e.lastID = event.ID
+		}
+		e.mu.Unlock()
+
+		if len(events) < monitorEventBatchSize {
+			return
+		}
+	}
+}
+
+// Subscribe starts receiving events that pass filter. With resume set, the
+// stored events after lastID are returned for replay; complete is false when
+// some of the events since lastID are no longer stored.
+func (e *MonitorEvents) Subscribe(ctx context.Context, filter func(models.MonitorEvent) bool, lastID uint64, resume bool) (sub *MonitorSubscription, replay []models.MonitorEvent, complete bool, err error) {
+	e.mu.Lock()
+	sub = &MonitorSubscription{hub: e, events: make(chan models.MonitorEvent, monitorEventSubscriberBuffer), filter: filter, since: e.lastID}
+	e.subscribers[sub] = struct{}{}
+	e.mu.Unlock()
+	if !resume {
+		return sub, nil, true, nil
+	}
+
+	oldest, _, err := e.events.Bounds(ctx)
+	if err != nil {
+		sub.Close()
+		return nil, nil, false, err
+	}
+	if lastID > sub.since || lastID+1 < oldest {
+		return sub, nil, false, nil
+	}
+
+	events, err := e.events.ListAfter(ctx, lastID, monitorEventReplayLimit)
+	if err != nil {
+		sub.Close()
+		return nil, nil, false, err
+	}
+	complete = len(events) < monitorEventReplayLimit || events[len(events)-1].ID >= sub.since
+	for _, event := range events {
+		if event.ID > sub.since {
+			break
+		}
+		if filter(event) {
+			replay = append(replay, event)
+		}
+	}
+	return sub, replay, complete, nil
+}
+
+// Since is the ID of the last event delivered before the subscription
+// started; everything after it arrives on Events.
+func (s *MonitorSubscription) Since() uint64 {
+	return s.since
+}
+
+// Events is closed when the subscriber falls too far behind.
+func (s *MonitorSubscription) Events() <-chan models.MonitorEvent {
+	return s.events
+}
+
+func (s *MonitorSubscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if _, ok := s.hub.subscribers[s]; ok {
+		delete(s.hub.subscribers, s)
+		close(s.events)
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"learn/internal/models"
+)
+
+const maxHourlyHistoryRange = 31 * 24 * time.Hour
+
+// History returns one bucket per hour or UTC day in [from, to) along with the
+// granularity used. Without one, ranges of up to 7 days are reported hourly and
+// longer ones daily.
+func (s *MonitorService) History(ctx context.Context, userID, id string, from, to time.Time, granularity string) ([]models.MonitorRollup, string, error) {
+	if _, err := s.monitors.GetByID(ctx, userID, id); err != nil {
+		return nil, "", err
+	}
+
+	if !from.Before(to) {
+		return nil, "", fmt.Errorf("%w: from must be before to", ErrInvalidUptimeRange)
+	}
+	if granularity == "" {
+		granularity = models.RollupDay
+		if to.Sub(from) <= 7*24*time.Hour {
+			granularity = models.RollupHour
+		}
+	}
+	if granularity == models.RollupHour && to.Sub(from) > maxHourlyHistoryRange {
+		return nil, "", fmt.Errorf("%w: hourly history may span at most 31 days", ErrInvalidUptimeRange)
+	}
+	if to.Sub(from) > maxUptimeRange {
+		return nil, "", fmt.Errorf("%w: the range may span at most 366 days", ErrInvalidUptimeRange)
+	}
+
+	buckets, err := s.history(ctx, id, granularity, from, to)
+	if err != nil {
+		return nil, "", err
+	}
+	if buckets == nil {
+		buckets = []models.MonitorRollup{}
+	}
+	return buckets, granularity, nil
+}
+
+// history reads buckets that have been compacted from the rollup tables and
+// aggregates the rest from the raw logs, so callers never need to know how far
+// compaction has got. Buckets without checks are omitted.
+func (s *MonitorService) history(ctx context.Context, monitorID, granularity string, from, to time.Time) ([]models.MonitorRollup, error) {
+	size := models.RollupSize(granularity)
+	from = from.UTC().Truncate(size)
+	to = to.UTC()
+
+	rolledUntil, err := s.rollups.RolledUntil(ctx, granularity)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	var buckets []models.MonitorRollup
+	if rolledUntil.After(from) {
+		rolled, err := s.rollups.List(ctx, monitorID, granularity, from, earlier(rolledUntil, to))
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, rolled...)
+	}
+	if rawFrom := later(from, rolledUntil); rawFrom.Before(to) {
+		logs, err := s.monitors.ListLogsBetween(ctx, monitorID, rawFrom, to)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, rollupLogs(logs, granularity)...)
+	}
+
+	for i := range buckets {
+		if buckets[i].Checks > 0 {
+			uptime := float64(buckets[i].UpChecks) / float64(buckets[i].Checks) * 100
+			buckets[i].UptimePercentage = &uptime
+		}
+	}
+	return buckets, nil
+}
+
+// rollupLogs groups logs by monitor and bucket, in order. Checks made during
+// maintenance are skipped.
+func rollupLogs(logs []models.MonitorLog, granularity string) []models.MonitorRollup {
+	size := models.RollupSize(granularity)
+	type key struct {
+		monitorID string
+		bucket    time.Time
+	}
+	byBucket := make(map[key]*models.MonitorRollup)
+	latencies := make(map[key][]int64)
+	var keys []key
+
+	for _, log := range logs {
+		if log.Status == models.MonitorStatusMaintenance {
+			continue
+		}
+		checkedAt := log.CheckedAt.UTC()
+		k := key{log.MonitorID, checkedAt.Truncate(size)}
+		rollup, ok := byBucket[k]
+		if !ok {
+			rollup = &models.MonitorRollup{MonitorID: log.MonitorID, BucketStart: k.bucket, FirstCheckedAt: checkedAt}
+			byBucket[k] = rollup
+			keys = append(keys, k)
+		}
+		rollup.Checks++
+		if checkedAt.Before(rollup.FirstCheckedAt) {
+			rollup.FirstCheckedAt = checkedAt
+		}
+		if log.Status == models.MonitorStatusUp || log.Status == models.MonitorStatusDegraded {
+			rollup.UpChecks++
+			latencies[k] = append(latencies[k], log.ResponseTimeMs)
+		}
+	}
+
+	slices.SortFunc(keys, func(a, b key) int {
+		return cmp.Or(strings.Compare(a.monitorID, b.monitorID), a.bucket.Compare(b.bucket))
+	})
+
+	rollups := make([]models.MonitorRollup, 0, len(keys))
+	for _, k := range keys {
+		rollup := byBucket[k]
+		if values := latencies[k]; len(values) > 0 {
+			var sum int64
+			for _, value := range values {
+				sum += value
+			}
+			rollup.MinResponseMs = slices.Min(values)
+			rollup.MaxResponseMs = slices.Max(values)
+			rollup.AvgResponseMs = sum / int64(len(values))
+			rollup.P95ResponseMs = percentiles(values).P95
+		}
+		rollups = append(rollups, *rollup)
+	}
+	return rollups
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"container/heap"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const maxScheduleJitter = 10 * time.Second
+
+// MonitorSchedule holds active monitors ordered by when their next check is
+// due. MonitorService marks monitors as changed so the worker picks up new
+// monitors, interval changes, pauses and deletions straight away.
+type MonitorSchedule struct {
+	mu      sync.Mutex
+	entries scheduleHeap
+	byID    map[string]*scheduleEntry
+	changed map[string]bool
+	wake    chan struct{}
+}
+
+type scheduleEntry struct {
+	monitorID string
+	interval  time.Duration
+	due       time.Time
+	index     int
+}
+
+func NewMonitorSchedule() *MonitorSchedule {
+	return &MonitorSchedule{
+		byID:    make(map[string]*scheduleEntry),
+		changed: make(map[string]bool),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Changed tells the worker to reload the monitor before scheduling it again.
+func (s *MonitorSchedule) Changed(monitorID string) {
+	s.mu.Lock()
+	s.changed[monitorID] = true
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *MonitorSchedule) takeChanged() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.changed))
+	for id := range s.changed {
+		ids = append(ids, id)
+	}
+	clear(s.changed)
+	return ids
+}
+
+func (s *MonitorSchedule) get(monitorID string) (scheduleEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.byID[monitorID]
+	if !ok {
+		return scheduleEntry{}, false
+	}
+	return *entry, true
+}
+
+// set schedules the monitor's next check, replacing any earlier one.
+func (s *MonitorSchedule) set(monitorID string, interval time.Duration, due time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.byID[monitorID]; ok {
+		entry.interval, entry.due = interval, due
+		heap.Fix(&s.entries, entry.index)
+		return
+	}
+	entry := &scheduleEntry{monitorID: monitorID, interval: interval, due: due}
+	heap.Push(&s.entries, entry)
+	s.byID[monitorID] = entry
+}
+
+func (s *MonitorSchedule) remove(monitorID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.byID[monitorID]; ok {
+		heap.Remove(&s.entries, entry.index)
+		delete(s.byID, monitorID)
+	}
+}
+
+func (s *MonitorSchedule) ids() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]string, 0, len(s.byID))
+	for id := range s.byID {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// next returns when the earliest check is due.
+func (s *MonitorSchedule) next() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.entries) == 0 {
+		return time.Time{}, false
+	}
+	return s.entries[0].due, true
+}
+
+// popDue removes and returns every entry due at or before now, earliest first.
+func (s *MonitorSchedule) popDue(now time.Time) []scheduleEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []scheduleEntry
+	for len(s.entries) > 0 && !s.entries[0].due.After(now) {
+		entry := heap.Pop(&s.entries).(*scheduleEntry)
+		delete(s.byID, entry.monitorID)
+		due = append(due, *entry)
+	}
+	return due
+}
+
+// scheduleJitter spreads monitors with the same interval over up to a tenth of
+// it, so they don't all fire in the same instant.
+func scheduleJitter(interval time.Duration) time.Duration {
+	spread := min(interval/10, maxScheduleJitter)
+	if spread <= 0 {
+		return 0
+	}
+	return rand.N(spread)
+}
+
+type scheduleHeap []*scheduleEntry
+
+func (h scheduleHeap) Len() int           { return len(h) }
+func (h scheduleHeap) Less(i, j int) bool { return h[i].due.Before(h[j].due) }
+
+func (h scheduleHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *scheduleHeap) Push(x any) {
+	entry := x.(*scheduleEntry)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *scheduleHeap) Pop() any {
+	old := *h
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return entry
+}
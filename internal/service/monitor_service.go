@@ -2,39 +2,114 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 	"learn/internal/models"
 	"learn/internal/repository"
 )
 
+const (
+	latencyWindow             = 24 * time.Hour
+	membershipRefreshInterval = 30 * time.Second
+)
+
+var (
+	ErrMonitorForbidden        = errors.New("insufficient role to modify monitor")
+	ErrMonitorSecretMissing    = errors.New("secret header has no value")
+	ErrInvalidMonitorLocations = errors.New("invalid monitor locations")
+)
+
 type MonitorService struct {
-	monitors repository.MonitorRepository
+	monitors      repository.MonitorRepository
+	incidents     repository.IncidentRepository
+	organizations repository.OrganizationRepository
+	maintenance   repository.MaintenanceRepository
+	rollups       repository.RollupRepository
+	probes        repository.ProbeRepository
+	egress        *EgressPolicy
+	schedule      *MonitorSchedule
+	events        *MonitorEvents
+	audit         *AuditService
 }
 
-func NewMonitorService(monitors repository.MonitorRepository) *MonitorService {
-	return &MonitorService{monitors: monitors}
+func NewMonitorService(monitors repository.MonitorRepository, incidents repository.IncidentRepository, organizations repository.OrganizationRepository, maintenance repository.MaintenanceRepository, rollups repository.RollupRepository, probes repository.ProbeRepository, egress *EgressPolicy, schedule *MonitorSchedule, events *MonitorEvents, audit *AuditService) *MonitorService {
+	return &MonitorService{monitors: monitors, incidents: incidents, organizations: organizations, maintenance: maintenance, rollups: rollups, probes: probes, egress: egress, schedule: schedule, events: events, audit: audit}
 }
 
-func (s *MonitorService) Create(ctx context.Context, userID string, name, url string, intervalSeconds int) (models.Monitor, error) {
-	if intervalSeconds == 0 {
-		intervalSeconds = 300
+func (s *MonitorService) Create(ctx context.Context, userID string, monitor models.Monitor) (models.Monitor, error) {
+	applyMonitorDefaults(&monitor)
+	if err := prepareMonitorType(&monitor); err != nil {
+		return models.Monitor{}, err
+	}
+	if err := s.egress.CheckMonitor(monitor); err != nil {
+		return models.Monitor{}, err
+	}
+	if err := validateAssertions(monitor.Assertions); err != nil {
+		return models.Monitor{}, err
+	}
+	if err := s.validateLocations(ctx, monitor); err != nil {
+		return models.Monitor{}, err
+	}
+
+	headers, err := mergeSecretHeaders(monitor.Headers, nil)
+	if err != nil {
+		return models.Monitor{}, err
+	}
+	monitor.Headers = headers
+
+	if monitor.OrganizationID != "" {
+		if err := s.requireOrgWriter(ctx, userID, monitor.OrganizationID); err != nil {
+			return models.Monitor{}, err
+		}
 	}
 
-	monitor := models.Monitor{
-		ID:              uuid.NewString(),
-		UserID:          userID,
-		Name:            name,
-		URL:             url,
-		IntervalSeconds: intervalSeconds,
-		IsActive:        true,
+	monitor.ID = uuid.NewString()
+	monitor.UserID = userID
+	monitor.IsActive = true
+	monitor.Status = models.MonitorStatusPending
+
+	created, err := s.monitors.Create(ctx, monitor)
+	if err != nil {
+		return models.Monitor{}, err
 	}
 
-	return s.monitors.Create(ctx, monitor)
+	s.schedule.Changed(created.ID)
+	s.audit.Record(ctx, userID, models.AuditActionMonitorCreated, "monitor", created.ID, nil, created)
+	return created, nil
 }
 
 func (s *MonitorService) ListByUser(ctx context.Context, userID string) ([]models.Monitor, error) {
-	return s.monitors.ListByUser(ctx, userID)
+	monitors, err := s.monitors.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	writable := make(map[string]bool)
+	for i := range monitors {
+		organizationID := monitors[i].OrganizationID
+		if organizationID == "" {
+			continue
+		}
+		allowed, ok := writable[organizationID]
+		if !ok {
+			if allowed, err = s.canWrite(ctx, userID, monitors[i]); err != nil {
+				return nil, err
+			}
+			writable[organizationID] = allowed
+		}
+		if !allowed {
+			monitors[i].PingToken = ""
+		}
+	}
+	return monitors, nil
 }
 
 func (s *MonitorService) GetWithLogs(ctx context.Context, userID, id string) (models.MonitorWithLogs, error) {
@@ -42,6 +117,15 @@ func (s *MonitorService) GetWithLogs(ctx context.Context, userID, id string) (mo
 	if err != nil {
 		return models.MonitorWithLogs{}, err
 	}
+	// The ping token lets its holder report the job's health, so viewers
+	// who can't change the monitor don't get it.
+	allowed, err := s.canWrite(ctx, userID, monitor)
+	if err != nil {
+		return models.MonitorWithLogs{}, err
+	}
+	if !allowed {
+		monitor.PingToken = ""
+	}
 
 	logs, err := s.monitors.ListLogs(ctx, id, 50)
 	if err != nil {
@@ -51,43 +135,474 @@ func (s *MonitorService) GetWithLogs(ctx context.Context, userID, id string) (mo
 		logs = []models.MonitorLog{}
 	}
 
-	var upCount int
-	for _, log := range logs {
-		if log.Status == "up" {
-			upCount++
-		}
+	now := time.Now()
+	since := now.Add(-latencyWindow)
+	report, err := s.uptime(ctx, monitor, since, now)
+	if err != nil {
+		return models.MonitorWithLogs{}, err
 	}
-
 	uptime := float64(0)
-	if len(logs) > 0 {
-		uptime = float64(upCount) / float64(len(logs)) * 100
+	if report.UptimePercentage != nil {
+		uptime = *report.UptimePercentage
+	}
+
+	recent, err := s.monitors.ListLogsSince(ctx, id, since)
+	if err != nil {
+		return models.MonitorWithLogs{}, err
 	}
 
 	return models.MonitorWithLogs{
 		Monitor: monitor,
 		Logs:    logs,
 		Uptime:  uptime,
+		Latency: latencyStats(recent, since),
 	}, nil
 }
 
+// latencyStats summarises the timed checks in logs. Failed checks without a
+// response and checks made during maintenance are left out.
+func latencyStats(logs []models.MonitorLog, since time.Time) models.LatencyStats {
+	var total, dns, connect, tlsHandshake, ttfb, transfer []int64
+	for _, log := range logs {
+		if log.Timings == nil || log.StatusCode == 0 || log.Status == models.MonitorStatusMaintenance {
+			continue
+		}
+		total = append(total, log.ResponseTimeMs)
+		dns = append(dns, log.Timings.DNSMs)
+		connect = append(connect, log.Timings.ConnectMs)
+		tlsHandshake = append(tlsHandshake, log.Timings.TLSMs)
+		ttfb = append(ttfb, log.Timings.TTFBMs)
+		transfer = append(transfer, log.Timings.TransferMs)
+	}
+
+	return models.LatencyStats{
+		Since:    since.UTC(),
+		Samples:  len(total),
+		Total:    percentiles(total),
+		DNS:      percentiles(dns),
+		Connect:  percentiles(connect),
+		TLS:      percentiles(tlsHandshake),
+		TTFB:     percentiles(ttfb),
+		Transfer: percentiles(transfer),
+	}
+}
+
+func percentiles(values []int64) models.LatencyPercentiles {
+	if len(values) == 0 {
+		return models.LatencyPercentiles{}
+	}
+	slices.Sort(values)
+	rank := func(p float64) int64 {
+		index := int(math.Ceil(p/100*float64(len(values)))) - 1
+		return values[max(index, 0)]
+	}
+	return models.LatencyPercentiles{P50: rank(50), P95: rank(95), P99: rank(99)}
+}
+
+func (s *MonitorService) Update(ctx context.Context, userID, id string, update models.MonitorUpdate) (models.Monitor, error) {
+	before, err := s.monitors.GetByID(ctx, userID, id)
+	if err != nil {
+		return models.Monitor{}, err
+	}
+
+	if err := s.authorizeWrite(ctx, userID, before); err != nil {
+		return models.Monitor{}, err
+	}
+
+	monitor := before
+	changedFrom := map[string]any{}
+	changedTo := map[string]any{}
+	applyChange(changedFrom, changedTo, "name", &monitor.Name, update.Name)
+	applyChange(changedFrom, changedTo, "type", &monitor.Type, update.Type)
+	applyChange(changedFrom, changedTo, "url", &monitor.URL, update.URL)
+	applyChange(changedFrom, changedTo, "interval_seconds", &monitor.IntervalSeconds, update.IntervalSeconds)
+	applyChange(changedFrom, changedTo, "method", &monitor.Method, update.Method)
+	applyChange(changedFrom, changedTo, "body", &monitor.Body, update.Body)
+	applyChange(changedFrom, changedTo, "accepted_statuses", &monitor.AcceptedStatuses, update.AcceptedStatuses)
+	applyChange(changedFrom, changedTo, "follow_redirects", &monitor.FollowRedirects, update.FollowRedirects)
+	applyChange(changedFrom, changedTo, "max_response_bytes", &monitor.MaxResponseBytes, update.MaxResponseBytes)
+	applyChange(changedFrom, changedTo, "grace_seconds", &monitor.GraceSeconds, update.GraceSeconds)
+	applyChange(changedFrom, changedTo, "retries", &monitor.Retries, update.Retries)
+	applyChange(changedFrom, changedTo, "retry_delay_ms", &monitor.RetryDelayMs, update.RetryDelayMs)
+	applyChange(changedFrom, changedTo, "failure_threshold", &monitor.FailureThreshold, update.FailureThreshold)
+	applyChange(changedFrom, changedTo, "recovery_threshold", &monitor.RecoveryThreshold, update.RecoveryThreshold)
+	applyChange(changedFrom, changedTo, "sla_target", &monitor.SLATarget, update.SLATarget)
+	applyConfigChange(changedFrom, changedTo, "dns", &monitor.DNS, update.DNS)
+	applyConfigChange(changedFrom, changedTo, "tls", &monitor.TLS, update.TLS)
+	if err := prepareMonitorType(&monitor); err != nil {
+		return models.Monitor{}, err
+	}
+	if err := s.egress.CheckMonitor(monitor); err != nil {
+		return models.Monitor{}, err
+	}
+	if update.Assertions != nil {
+		if err := validateAssertions(update.Assertions); err != nil {
+			return models.Monitor{}, err
+		}
+		if !slices.Equal(update.Assertions, before.Assertions) {
+			changedFrom["assertions"], changedTo["assertions"] = before.Assertions, update.Assertions
+			monitor.Assertions = update.Assertions
+		}
+	}
+	if len(update.Locations) > 0 && !slices.Equal(update.Locations, before.Locations) {
+		changedFrom["locations"], changedTo["locations"] = before.Locations, update.Locations
+		monitor.Locations = update.Locations
+		if update.Quorum == nil {
+			quorum := majority(len(monitor.Locations))
+			update.Quorum = &quorum
+		}
+	}
+	applyChange(changedFrom, changedTo, "quorum", &monitor.Quorum, update.Quorum)
+	if err := s.validateLocations(ctx, monitor); err != nil {
+		return models.Monitor{}, err
+	}
+	if update.Headers != nil {
+		headers, err := mergeSecretHeaders(update.Headers, before.Headers)
+		if err != nil {
+			return models.Monitor{}, err
+		}
+		if !slices.Equal(headers, before.Headers) {
+			changedFrom["headers"], changedTo["headers"] = before.Headers, headers
+			monitor.Headers = headers
+		}
+	}
+
+	if len(changedTo) == 0 {
+		return before, nil
+	}
+
+	updated, err := s.monitors.Update(ctx, userID, monitor)
+	if err != nil {
+		return models.Monitor{}, err
+	}
+	if !updated {
+		return models.Monitor{}, sql.ErrNoRows
+	}
+
+	s.schedule.Changed(id)
+	s.audit.Record(ctx, userID, models.AuditActionMonitorUpdated, "monitor", id, changedFrom, changedTo)
+	return monitor, nil
+}
+
 func (s *MonitorService) Delete(ctx context.Context, userID, id string) (bool, error) {
-	return s.monitors.Delete(ctx, userID, id)
+	before, err := s.monitors.GetByID(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err := s.authorizeWrite(ctx, userID, before); err != nil {
+		return false, err
+	}
+
+	deleted, err := s.monitors.Delete(ctx, userID, id)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+
+	s.schedule.Changed(id)
+	s.audit.Record(ctx, userID, models.AuditActionMonitorDeleted, "monitor", id, before, nil)
+	return true, nil
 }
 
 func (s *MonitorService) Toggle(ctx context.Context, userID, id string) (bool, error) {
-	return s.monitors.Toggle(ctx, userID, id)
+	before, err := s.monitors.GetByID(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err := s.authorizeWrite(ctx, userID, before); err != nil {
+		return false, err
+	}
+
+	updated, err := s.monitors.Toggle(ctx, userID, id)
+	if err != nil || !updated {
+		return updated, err
+	}
+
+	s.schedule.Changed(id)
+	s.audit.Record(ctx, userID, models.AuditActionMonitorToggled, "monitor", id,
+		map[string]bool{"is_active": before.IsActive},
+		map[string]bool{"is_active": !before.IsActive})
+	return true, nil
 }
 
-func (s *MonitorService) Dashboard(ctx context.Context, userID string) (models.MonitorStats, []models.RecentMonitorLog, error) {
+func (s *MonitorService) Dashboard(ctx context.Context, userID string) (models.MonitorStats, []models.Incident, []models.RecentMonitorLog, error) {
 	stats, err := s.monitors.CountStats(ctx, userID)
 	if err != nil {
-		return models.MonitorStats{}, nil, err
+		return models.MonitorStats{}, nil, nil, err
+	}
+
+	incidents, err := s.incidents.ListByUser(ctx, userID, models.IncidentStatusOpen, maxIncidentList)
+	if err != nil {
+		return models.MonitorStats{}, nil, nil, err
 	}
 
 	logs, err := s.monitors.ListRecentLogs(ctx, userID, 20)
 	if err != nil {
-		return models.MonitorStats{}, nil, err
+		return models.MonitorStats{}, nil, nil, err
+	}
+
+	return stats, incidents, logs, nil
+}
+
+// Subscribe streams events for the monitors the user can see: their personal
+// ones and those of organizations they belong to. Membership is read again
+// every membershipRefreshInterval until ctx ends, so a member who leaves or is
+// removed stops receiving the organization's events.
+func (s *MonitorService) Subscribe(ctx context.Context, userID string, lastEventID uint64, resume bool) (*MonitorSubscription, []models.MonitorEvent, bool, error) {
+	memberOf, err := s.memberOrganizations(ctx, userID)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	var current atomic.Pointer[map[string]bool]
+	current.Store(&memberOf)
+	visible := func(event models.MonitorEvent) bool {
+		if event.OrganizationID == "" {
+			return event.UserID == userID
+		}
+		return (*current.Load())[event.OrganizationID]
+	}
+
+	sub, replay, complete, err := s.events.Subscribe(ctx, visible, lastEventID, resume)
+	if err != nil {
+		return nil, nil, false, err
 	}
+	go func() {
+		ticker := time.NewTicker(membershipRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			memberOf, err := s.memberOrganizations(ctx, userID)
+			if err != nil {
+				// Without current membership the stream can't be filtered, so
+				// it ends and the client's reconnect starts it afresh.
+				if ctx.Err() == nil {
+					log.Printf("Error refreshing organizations of user %s: %v", userID, err)
+					sub.Close()
+				}
+				return
+			}
+			current.Store(&memberOf)
+		}
+	}()
+	return sub, replay, complete, nil
+}
 
-	return stats, logs, nil
+func (s *MonitorService) memberOrganizations(ctx context.Context, userID string) (map[string]bool, error) {
+	organizations, err := s.organizations.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	memberOf := make(map[string]bool, len(organizations))
+	for _, organization := range organizations {
+		memberOf[organization.ID] = true
+	}
+	return memberOf, nil
+}
+
+func applyChange[T comparable](from, to map[string]any, field string, current *T, next *T) {
+	if next == nil || *next == *current {
+		return
+	}
+	from[field], to[field] = *current, *next
+	*current = *next
+}
+
+func applyConfigChange[T comparable](from, to map[string]any, field string, current **T, next *T) {
+	if next == nil || (*current != nil && **current == *next) {
+		return
+	}
+	from[field], to[field] = *current, next
+	*current = next
+}
+
+// applyMonitorDefaults fills in the settings a new monitor was created without.
+func applyMonitorDefaults(monitor *models.Monitor) {
+	if monitor.Type == "" {
+		monitor.Type = models.MonitorTypeHTTP
+	}
+	if monitor.IntervalSeconds == 0 {
+		monitor.IntervalSeconds = 300
+	}
+	if monitor.Method == "" {
+		monitor.Method = models.DefaultMonitorMethod
+	}
+	if monitor.AcceptedStatuses == "" {
+		monitor.AcceptedStatuses = models.DefaultMonitorAcceptedStatuses
+	}
+	if monitor.Headers == nil {
+		monitor.Headers = []models.MonitorHeader{}
+	}
+	if monitor.Assertions == nil {
+		monitor.Assertions = []models.MonitorAssertion{}
+	}
+	if monitor.MaxResponseBytes == 0 {
+		monitor.MaxResponseBytes = models.DefaultMonitorMaxResponseBytes
+	}
+	if monitor.RetryDelayMs == 0 {
+		monitor.RetryDelayMs = models.DefaultMonitorRetryDelayMs
+	}
+	if monitor.FailureThreshold == 0 {
+		monitor.FailureThreshold = 1
+	}
+	if monitor.RecoveryThreshold == 0 {
+		monitor.RecoveryThreshold = 1
+	}
+	if monitor.SLATarget == 0 {
+		monitor.SLATarget = models.DefaultMonitorSLATarget
+	}
+	if len(monitor.Locations) == 0 {
+		monitor.Locations = []string{models.LocationLocal}
+	}
+	if monitor.Quorum == 0 {
+		monitor.Quorum = majority(len(monitor.Locations))
+	}
+}
+
+// prepareMonitorType checks the target suits the monitor type, fills in the
+// type's default settings and drops settings belonging to other types.
+func prepareMonitorType(monitor *models.Monitor) error {
+	if _, _, err := monitorTarget(*monitor); err != nil {
+		return err
+	}
+
+	if monitor.Type != models.MonitorTypeDNS {
+		monitor.DNS = nil
+	} else {
+		if monitor.DNS == nil {
+			monitor.DNS = &models.DNSCheckConfig{}
+		}
+		if monitor.DNS.RecordType == "" {
+			monitor.DNS.RecordType = models.DefaultDNSRecordType
+		}
+	}
+
+	if monitor.Type != models.MonitorTypeHeartbeat {
+		monitor.PingToken = ""
+	} else if monitor.PingToken == "" {
+		token, err := generateToken(16)
+		if err != nil {
+			return err
+		}
+		monitor.PingToken = token
+	}
+
+	if monitor.Type != models.MonitorTypeTLS {
+		monitor.TLS = nil
+	} else {
+		if monitor.TLS == nil {
+			monitor.TLS = &models.TLSCheckConfig{}
+		}
+		if monitor.TLS.ExpiryThresholdDays == 0 {
+			monitor.TLS.ExpiryThresholdDays = models.DefaultTLSExpiryThresholdDays
+		}
+	}
+	return nil
+}
+
+// mergeSecretHeaders lets clients resubmit a secret header without its value
+// to keep the one already stored.
+func mergeSecretHeaders(headers, previous []models.MonitorHeader) ([]models.MonitorHeader, error) {
+	merged := make([]models.MonitorHeader, 0, len(headers))
+	for _, header := range headers {
+		if header.Secret && header.Value == "" {
+			index := slices.IndexFunc(previous, func(p models.MonitorHeader) bool {
+				return p.Secret && !p.Unreadable && strings.EqualFold(p.Name, header.Name)
+			})
+			if index < 0 {
+				return nil, ErrMonitorSecretMissing
+			}
+			header.Value = previous[index].Value
+		}
+		merged = append(merged, header)
+	}
+	return merged, nil
+}
+
+// validateLocations checks the monitor is only checked from places that
+// exist, and that its quorum can be reached. Heartbeats are received here, so
+// they can only be checked locally.
+func (s *MonitorService) validateLocations(ctx context.Context, monitor models.Monitor) error {
+	if monitor.Type == models.MonitorTypeHeartbeat && !slices.Equal(monitor.Locations, []string{models.LocationLocal}) {
+		return fmt.Errorf("%w: heartbeat monitors can only be checked from %s", ErrInvalidMonitorLocations, models.LocationLocal)
+	}
+	if monitor.Quorum < 1 || monitor.Quorum > len(monitor.Locations) {
+		return fmt.Errorf("%w: quorum must be between 1 and the number of locations", ErrInvalidMonitorLocations)
+	}
+
+	var probes []models.Probe
+	for i, location := range monitor.Locations {
+		if slices.Contains(monitor.Locations[:i], location) {
+			return fmt.Errorf("%w: %s is listed twice", ErrInvalidMonitorLocations, location)
+		}
+		if location == models.LocationLocal {
+			continue
+		}
+		if probes == nil {
+			var err error
+			if probes, err = s.probes.List(ctx); err != nil {
+				return err
+			}
+		}
+		if !slices.ContainsFunc(probes, func(p models.Probe) bool { return p.Location == location }) {
+			return fmt.Errorf("%w: no probe is registered for %s", ErrInvalidMonitorLocations, location)
+		}
+	}
+	return nil
+}
+
+// majority is the default quorum: more than half of the locations.
+func majority(locations int) int {
+	return locations/2 + 1
+}
+
+func (s *MonitorService) authorizeWrite(ctx context.Context, userID string, monitor models.Monitor) error {
+	if monitor.OrganizationID == "" {
+		return nil
+	}
+	return s.requireOrgWriter(ctx, userID, monitor.OrganizationID)
+}
+
+// canWrite is authorizeWrite for deciding what to show rather than refusing.
+func (s *MonitorService) canWrite(ctx context.Context, userID string, monitor models.Monitor) (bool, error) {
+	err := s.authorizeWrite(ctx, userID, monitor)
+	if errors.Is(err, ErrMonitorForbidden) || errors.Is(err, ErrOrganizationNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// canView reports whether userID can see a resource that is either personal
+// to ownerID or shared with an organization.
+func (s *MonitorService) canView(ctx context.Context, userID, ownerID, organizationID string) bool {
+	if organizationID == "" {
+		return userID == ownerID
+	}
+	_, err := s.organizations.GetMemberRole(ctx, organizationID, userID)
+	return err == nil
+}
+
+func (s *MonitorService) requireOrgWriter(ctx context.Context, userID, organizationID string) error {
+	role, err := s.organizations.GetMemberRole(ctx, organizationID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrOrganizationNotFound
+		}
+		return err
+	}
+	if !models.OrgRoleAtLeast(role, models.OrgRoleMember) {
+		return ErrMonitorForbidden
+	}
+	return nil
 }
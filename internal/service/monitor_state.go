@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"learn/internal/models"
+	"learn/internal/repository"
+)
+
+const maxStateAttempts = 5
+
+var errStateContention = errors.New("monitor state kept changing during update")
+
+// CheckRecorder stores check results and reacts when they change a monitor's
+// confirmed state.
+type CheckRecorder struct {
+	monitors      repository.MonitorRepository
+	incidents     *IncidentService
+	notifications *NotificationService
+	maintenance   *MaintenanceService
+	events        *MonitorEvents
+}
+
+func NewCheckRecorder(monitors repository.MonitorRepository, incidents *IncidentService, notifications *NotificationService, maintenance *MaintenanceService, events *MonitorEvents) *CheckRecorder {
+	return &CheckRecorder{monitors: monitors, incidents: incidents, notifications: notifications, maintenance: maintenance, events: events}
+}
+
+// Record stores a check result, advances the monitor's confirmed state, keeps
+// its incidents in step and queues alerts. It reports whether the confirmed
+// status changed. During maintenance the result is only logged, with the
+// maintenance status, and the monitor's state is left alone. For monitors
+// checked from several locations the state follows the quorum of their latest
+// results rather than this one alone.
+func (r *CheckRecorder) Record(ctx context.Context, monitor models.Monitor, entry models.MonitorLog) (models.MonitorState, bool, error) {
+	_, _, inMaintenance, err := r.maintenance.Active(ctx, monitor.ID, time.Now())
+	if err != nil {
+		return models.MonitorState{}, false, err
+	}
+	if inMaintenance {
+		if entry.Details == nil {
+			entry.Details = map[string]string{}
+		}
+		entry.Details["check_status"] = entry.Status
+		entry.Status = models.MonitorStatusMaintenance
+	}
+
+	if err := r.monitors.CreateLog(ctx, entry); err != nil {
+		return models.MonitorState{}, false, err
+	}
+	if entry.CheckedAt.IsZero() {
+		entry.CheckedAt = time.Now().UTC().Truncate(time.Second)
+	}
+	r.events.Publish(ctx, monitorEvent(monitor, models.MonitorEventCheck, entry))
+	if inMaintenance {
+		return monitor.MonitorState, false, nil
+	}
+
+	monitor, state, err := r.advanceState(ctx, monitor, entry)
+	if err != nil {
+		return models.MonitorState{}, false, err
+	}
+	changed := state.Status != monitor.Status
+	if changed {
+		r.events.Publish(ctx, monitorEvent(monitor, models.MonitorEventState, models.MonitorStateChange{
+			MonitorID:      monitor.ID,
+			PreviousStatus: monitor.Status,
+			MonitorState:   state,
+		}))
+	}
+	if err := r.incidents.Observe(ctx, monitor, state, entry); err != nil {
+		return models.MonitorState{}, false, err
+	}
+
+	if changed && shouldNotify(monitor.Status, state.Status) {
+		if err := r.notifications.Notify(ctx, monitor, state, entry); err != nil {
+			log.Printf("Error queueing notifications for monitor %s: %v", monitor.ID, err)
+		}
+	}
+	return state, changed, nil
+}
+
+// advanceState applies the result to the monitor's state. Checks from
+// several places can land at once, so the update only succeeds if nobody else
+// moved the state first; otherwise it starts again from the stored state. It
+// returns the monitor as it was just before the update.
+//
+// A monitor checked from several locations gets one report per location each
+// interval, so only the first report of a round is counted, as the quorum of
+// every location's latest result. The rest are logged and count next round.
+func (r *CheckRecorder) advanceState(ctx context.Context, monitor models.Monitor, entry models.MonitorLog) (models.Monitor, models.MonitorState, error) {
+	for attempt := 1; ; attempt++ {
+		now := time.Now()
+		status := entry.Status
+		if len(monitor.Locations) > 1 {
+			if monitor.CountedAt != nil && now.Sub(*monitor.CountedAt) < quorumRound(monitor) {
+				return monitor, monitor.MonitorState, nil
+			}
+			var err error
+			if status, err = r.quorumStatus(ctx, monitor, now); err != nil {
+				return models.Monitor{}, models.MonitorState{}, err
+			}
+			if status == "" {
+				return monitor, monitor.MonitorState, nil
+			}
+		}
+
+		state := nextMonitorState(monitor, status, now)
+		state.CountedAt = &now
+		updated, err := r.monitors.UpdateState(ctx, monitor.ID, monitor.MonitorState, state)
+		if err != nil {
+			return models.Monitor{}, models.MonitorState{}, err
+		}
+		if updated {
+			return monitor, state, nil
+		}
+		if attempt == maxStateAttempts {
+			return models.Monitor{}, models.MonitorState{}, errStateContention
+		}
+
+		if monitor, err = r.monitors.GetByIDUnscoped(ctx, monitor.ID); err != nil {
+			return models.Monitor{}, models.MonitorState{}, err
+		}
+	}
+}
+
+// quorumRound is how long after counting a quorum result the next one is
+// counted. It is a little under the interval so that the jitter between
+// rounds doesn't skip one.
+func quorumRound(monitor models.Monitor) time.Duration {
+	interval := time.Duration(monitor.IntervalSeconds) * time.Second
+	return interval - interval/5
+}
+
+// quorumStatus combines the latest result from each of the monitor's
+// locations. It is down when at least Quorum locations are down; otherwise a
+// location that is down or degraded makes it degraded. Locations that have not
+// reported within two intervals don't count towards either, and the quorum
+// shrinks to the number that have, so a monitor can still go down while
+// probes are offline. With no recent reports at all it returns "".
+func (r *CheckRecorder) quorumStatus(ctx context.Context, monitor models.Monitor, now time.Time) (string, error) {
+	window := 2*time.Duration(monitor.IntervalSeconds)*time.Second + time.Minute
+	logs, err := r.monitors.ListLogsSince(ctx, monitor.ID, now.Add(-window))
+	if err != nil {
+		return "", err
+	}
+
+	latest := map[string]string{}
+	for _, entry := range logs {
+		if entry.Status != models.MonitorStatusMaintenance && monitor.ChecksFrom(entry.Location) {
+			latest[entry.Location] = entry.Status
+		}
+	}
+
+	down, degraded := 0, false
+	for _, status := range latest {
+		switch status {
+		case models.MonitorStatusDown:
+			down++
+			degraded = true
+		case models.MonitorStatusDegraded:
+			degraded = true
+		}
+	}
+	if len(latest) == 0 {
+		return "", nil
+	}
+	switch {
+	case down >= min(monitor.Quorum, len(latest)):
+		return models.MonitorStatusDown, nil
+	case degraded:
+		return models.MonitorStatusDegraded, nil
+	default:
+		return models.MonitorStatusUp, nil
+	}
+}
+
+func monitorEvent(monitor models.Monitor, eventType string, data any) models.MonitorEvent {
+	return models.MonitorEvent{
+		Type:           eventType,
+		MonitorID:      monitor.ID,
+		UserID:         monitor.UserID,
+		OrganizationID: monitor.OrganizationID,
+		Data:           data,
+	}
+}
+
+// A new monitor coming up for the first time is not worth an alert.
+func shouldNotify(from, to string) bool {
+	return !(from == models.MonitorStatusPending && to != models.MonitorStatusDown)
+}
+
+// nextMonitorState counts consecutive results. A monitor only goes down after
+// FailureThreshold failures in a row and only recovers from down after
+// RecoveryThreshold successes; moving between up and degraded is immediate.
+func nextMonitorState(monitor models.Monitor, status string, at time.Time) models.MonitorState {
+	state := monitor.MonitorState
+	if state.Status == "" {
+		state.Status = models.MonitorStatusPending
+	}
+
+	next := state.Status
+	if status == models.MonitorStatusDown {
+		state.ConsecutiveFailures++
+		state.ConsecutiveSuccesses = 0
+		if state.ConsecutiveFailures >= max(monitor.FailureThreshold, 1) {
+			next = models.MonitorStatusDown
+		}
+	} else {
+		state.ConsecutiveSuccesses++
+		state.ConsecutiveFailures = 0
+		if state.Status != models.MonitorStatusDown || state.ConsecutiveSuccesses >= max(monitor.RecoveryThreshold, 1) {
+			next = status
+		}
+	}
+
+	if next != state.Status {
+		state.Status = next
+		state.StatusChangedAt = &at
+	}
+	return state
+}
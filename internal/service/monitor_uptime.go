@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"learn/internal/models"
+)
+
+const maxUptimeRange = 366 * 24 * time.Hour
+
+var ErrInvalidUptimeRange = errors.New("invalid uptime range")
+
+var uptimePresets = []struct {
+	label  string
+	window time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// Uptime reports availability over [from, to), or over the last 24 hours, 7
+// days and 30 days when from is zero. A zero to means now. target overrides
+// the monitor's SLA target when set.
+func (s *MonitorService) Uptime(ctx context.Context, userID, id string, from, to time.Time, target float64) ([]models.UptimeReport, error) {
+	monitor, err := s.monitors.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if target == 0 {
+		target = monitor.SLATarget
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	if to.IsZero() {
+		to = now
+	}
+
+	var reports []models.UptimeReport
+	if from.IsZero() {
+		for _, preset := range uptimePresets {
+			report, err := s.uptime(ctx, monitor, to.Add(-preset.window), to)
+			if err != nil {
+				return nil, err
+			}
+			report.Label = preset.label
+			reports = append(reports, withSLA(report, target))
+		}
+		return reports, nil
+	}
+
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidUptimeRange)
+	}
+	if to.Sub(from) > maxUptimeRange {
+		return nil, fmt.Errorf("%w: the range may span at most 366 days", ErrInvalidUptimeRange)
+	}
+	report, err := s.uptime(ctx, monitor, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report.Label = "custom"
+	return append(reports, withSLA(report, target)), nil
+}
+
+// uptime measures the time-weighted availability of monitor over [from, to).
+// Time in a confirmed down state (an incident) counts against it; maintenance,
+// time the monitor was paused and the time before the first check or after
+// now are left out.
+func (s *MonitorService) uptime(ctx context.Context, monitor models.Monitor, from, to time.Time) (models.UptimeReport, error) {
+	from, to = from.UTC(), to.UTC()
+	report := models.UptimeReport{From: from, To: to}
+
+	now := time.Now().UTC()
+	firstCheck, err := s.monitors.GetFirstCheck(ctx, monitor.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return report, nil
+		}
+		return models.UptimeReport{}, err
+	}
+	start := later(from, firstCheck.UTC())
+	end := earlier(to, now)
+	if !start.Before(end) {
+		return report, nil
+	}
+
+	windows, err := s.maintenance.ListByMonitor(ctx, monitor.ID)
+	if err != nil {
+		return models.UptimeReport{}, err
+	}
+	var maintenance []models.TimeRange
+	for _, window := range windows {
+		maintenance = append(maintenance, window.Occurrences(start, end)...)
+	}
+	maintenance = mergeRanges(maintenance)
+
+	pauses, err := s.monitors.ListPauses(ctx, monitor.ID, start, end)
+	if err != nil {
+		return models.UptimeReport{}, err
+	}
+	var paused []models.TimeRange
+	for _, pause := range pauses {
+		pauseStart, pauseEnd := later(pause.Start, start), earlier(pause.End, end)
+		if pauseStart.Before(pauseEnd) {
+			paused = append(paused, models.TimeRange{Start: pauseStart, End: pauseEnd})
+		}
+	}
+	paused = mergeRanges(paused)
+	excluded := mergeRanges(append(slices.Clone(maintenance), paused...))
+
+	incidents, err := s.incidents.ListOverlapping(ctx, monitor.ID, start, end)
+	if err != nil {
+		return models.UptimeReport{}, err
+	}
+	var down []models.TimeRange
+	for _, incident := range incidents {
+		resolvedAt := now
+		if incident.ResolvedAt != nil {
+			resolvedAt = *incident.ResolvedAt
+		}
+		incidentStart, incidentEnd := later(incident.StartedAt, start), earlier(resolvedAt, end)
+		if incidentStart.Before(incidentEnd) {
+			down = append(down, models.TimeRange{Start: incidentStart, End: incidentEnd})
+		}
+	}
+	down = mergeRanges(down)
+
+	downtime := totalDuration(down) - overlapDuration(down, excluded)
+	monitored := end.Sub(start) - totalDuration(excluded)
+
+	report.Incidents = len(incidents)
+	report.MaintenanceSeconds = int64(totalDuration(maintenance).Seconds())
+	report.PausedSeconds = int64(totalDuration(paused).Seconds())
+	report.MonitoredSeconds = int64(monitored.Seconds())
+	report.DowntimeSeconds = int64(downtime.Seconds())
+	if monitored > 0 {
+		uptime := float64(monitored-downtime) / float64(monitored) * 100
+		report.UptimePercentage = &uptime
+	}
+	return report, nil
+}
+
+func withSLA(report models.UptimeReport, target float64) models.UptimeReport {
+	if report.UptimePercentage == nil {
+		return report
+	}
+
+	budget := float64(report.MonitoredSeconds) * (100 - target) / 100
+	remaining := budget - float64(report.DowntimeSeconds)
+	sla := &models.SLAReport{
+		TargetPercentage:            target,
+		Met:                         *report.UptimePercentage >= target,
+		ErrorBudgetSeconds:          int64(budget),
+		ErrorBudgetRemainingSeconds: int64(remaining),
+	}
+	if budget > 0 {
+		sla.ErrorBudgetRemainingPercentage = remaining / budget * 100
+	}
+	report.SLA = sla
+	return report
+}
+
+// mergeRanges sorts ranges and joins the ones that overlap or touch.
+func mergeRanges(ranges []models.TimeRange) []models.TimeRange {
+	slices.SortFunc(ranges, func(a, b models.TimeRange) int { return a.Start.Compare(b.Start) })
+	var merged []models.TimeRange
+	for _, r := range ranges {
+		if last := len(merged) - 1; last >= 0 && !r.Start.After(merged[last].End) {
+			merged[last].End = later(merged[last].End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+func totalDuration(ranges []models.TimeRange) time.Duration {
+	var total time.Duration
+	for _, r := range ranges {
+		total += r.End.Sub(r.Start)
+	}
+	return total
+}
+
+// overlapDuration is how much of a falls within b; both must be merged.
+func overlapDuration(a, b []models.TimeRange) time.Duration {
+	var total time.Duration
+	for _, x := range a {
+		for _, y := range b {
+			start, end := later(x.Start, y.Start), earlier(x.End, y.End)
+			if start.Before(end) {
+				total += end.Sub(start)
+			}
+		}
+	}
+	return total
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earlier(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -5,7 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"log"
-	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -14,25 +14,45 @@ import (
 	"learn/internal/repository"
 )
 
+const (
+	checkTimeout           = 10 * time.Second
+	checkLeaseMargin       = 30 * time.Second
+	maxConcurrentChecks    = 10
+	scheduleResyncInterval = 15 * time.Second
+)
+
 type MonitorWorker struct {
-	ctx        context.Context
-	cancel     context.CancelFunc
-	wg         sync.WaitGroup
-	httpClient *http.Client
-	monitors   repository.MonitorRepository
-	snippets   *SnippetService
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	checkers    map[string]Checker
+	monitors    repository.MonitorRepository
+	schedule    *MonitorSchedule
+	leases      *Leases
+	semaphore   chan struct{}
+	runningMu   sync.Mutex
+	running     map[string]bool
+	recorder    *CheckRecorder
+	maintenance *MaintenanceService
+	snippets    *SnippetService
+	exports     *ExportService
 }
 
-func NewMonitorWorker(monitors repository.MonitorRepository, snippets *SnippetService) *MonitorWorker {
+func NewMonitorWorker(monitors repository.MonitorRepository, schedule *MonitorSchedule, leases *Leases, recorder *CheckRecorder, maintenance *MaintenanceService, snippets *SnippetService, exports *ExportService, egress *EgressPolicy) *MonitorWorker {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &MonitorWorker{
-		ctx:    ctx,
-		cancel: cancel,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		monitors: monitors,
-		snippets: snippets,
+		ctx:         ctx,
+		cancel:      cancel,
+		checkers:    newCheckers(egress),
+		monitors:    monitors,
+		schedule:    schedule,
+		leases:      leases,
+		semaphore:   make(chan struct{}, maxConcurrentChecks),
+		running:     make(map[string]bool),
+		recorder:    recorder,
+		maintenance: maintenance,
+		snippets:    snippets,
+		exports:     exports,
 	}
 }
 
@@ -42,20 +62,7 @@ func (w *MonitorWorker) Start() {
 	w.wg.Add(1)
 	go func() {
 		defer w.wg.Done()
-		ticker := time.NewTicker(1 * time.Minute)
-		defer ticker.Stop()
-
-		w.checkAllMonitors()
-
-		for {
-			select {
-			case <-w.ctx.Done():
-				log.Println("Monitor worker stopping...")
-				return
-			case <-ticker.C:
-				w.checkAllMonitors()
-			}
-		}
+		w.run()
 	}()
 
 	w.wg.Add(1)
@@ -72,11 +79,23 @@ func (w *MonitorWorker) Start() {
 				deleted, err := w.snippets.DeleteExpired(w.ctx)
 				if err != nil {
 					log.Printf("Error cleaning expired snippets: %v", err)
-					continue
-				}
-				if deleted > 0 {
+				} else if deleted > 0 {
 					log.Printf("Cleaned up %d expired snippets", deleted)
 				}
+
+				deleted, err = w.exports.DeleteExpired(w.ctx)
+				if err != nil {
+					log.Printf("Error cleaning expired exports: %v", err)
+				} else if deleted > 0 {
+					log.Printf("Cleaned up %d expired exports", deleted)
+				}
+
+				failed, err := w.exports.FailStale(w.ctx)
+				if err != nil {
+					log.Printf("Error failing stale exports: %v", err)
+				} else if failed > 0 {
+					log.Printf("Marked %d stale exports as failed", failed)
+				}
 			}
 		}
 	}()
@@ -88,84 +107,296 @@ func (w *MonitorWorker) Stop() {
 	log.Println("Monitor worker stopped")
 }
 
-func (w *MonitorWorker) checkAllMonitors() {
-	monitors, err := w.monitors.ListActive(w.ctx)
+// run sleeps until the earliest check is due, a monitor changes or it is time
+// to resync the schedule with the database.
+func (w *MonitorWorker) run() {
+	w.resync()
+
+	resync := time.NewTicker(scheduleResyncInterval)
+	defer resync.Stop()
+	timer := time.NewTimer(scheduleResyncInterval)
+	defer timer.Stop()
+
+	for {
+		w.dispatchDue(time.Now())
+
+		wait := scheduleResyncInterval
+		if due, ok := w.schedule.next(); ok {
+			wait = time.Until(due)
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-w.ctx.Done():
+			log.Println("Monitor worker stopping...")
+			return
+		case <-timer.C:
+		case <-resync.C:
+			w.resync()
+		case <-w.schedule.wake:
+			w.applyChanges()
+		}
+	}
+}
+
+// resync schedules active monitors checked from here that the worker has not
+// heard about and drops ones that are gone, which picks up changes made
+// through other processes. A monitor that has never been claimed is first due
+// one interval after its last logged check.
+func (w *MonitorWorker) resync() {
+	monitors, err := w.monitors.ListActiveByLocation(w.ctx, models.LocationLocal)
 	if err != nil {
 		log.Printf("Error fetching monitors: %v", err)
 		return
 	}
 
-	if len(monitors) == 0 {
-		return
-	}
+	var lastChecks map[string]time.Time
+	now := time.Now()
+	active := make(map[string]bool, len(monitors))
+	for _, monitor := range monitors {
+		active[monitor.ID] = true
+		interval := time.Duration(monitor.IntervalSeconds) * time.Second
+		if entry, ok := w.schedule.get(monitor.ID); ok {
+			if entry.interval != interval {
+				w.schedule.set(monitor.ID, interval, later(entry.due.Add(interval-entry.interval), now))
+			}
+			continue
+		}
 
-	log.Printf("Checking %d monitors...", len(monitors))
+		due := now
+		if monitor.NextCheckAt != nil {
+			due = later(*monitor.NextCheckAt, now)
+		} else {
+			if lastChecks == nil {
+				if lastChecks, err = w.monitors.ListLastChecks(w.ctx); err != nil {
+					log.Printf("Error fetching last checks: %v", err)
+					return
+				}
+			}
+			if lastCheck, ok := lastChecks[monitor.ID]; ok {
+				due = later(lastCheck.Add(interval), now)
+			}
+		}
+		w.schedule.set(monitor.ID, interval, due.Add(scheduleJitter(interval)))
+	}
 
-	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, 10)
+	for _, id := range w.schedule.ids() {
+		if !active[id] {
+			w.schedule.remove(id)
+		}
+	}
+}
 
-	for _, monitor := range monitors {
-		lastCheck, err := w.monitors.GetLastCheck(w.ctx, monitor.ID)
-		if err == nil && time.Since(lastCheck) < time.Duration(monitor.IntervalSeconds)*time.Second {
+// applyChanges reschedules monitors that were created, updated, paused,
+// resumed or deleted. New and resumed monitors are checked right away.
+func (w *MonitorWorker) applyChanges() {
+	now := time.Now()
+	for _, id := range w.schedule.takeChanged() {
+		monitor, err := w.monitors.GetByIDUnscoped(w.ctx, id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Error fetching monitor %s: %v", id, err)
 			continue
 		}
-		if err != nil && !errors.Is(err, sql.ErrNoRows) {
-			log.Printf("Error fetching last check for monitor %d: %v", monitor.ID, err)
+		if err != nil || !monitor.IsActive || !monitor.ChecksFrom(models.LocationLocal) {
+			w.schedule.remove(id)
 			continue
 		}
 
-		wg.Add(1)
-		go func(m models.MonitorCheck) {
-			defer wg.Done()
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			w.checkMonitor(m.ID, m.URL)
-		}(monitor)
+		interval := time.Duration(monitor.IntervalSeconds) * time.Second
+		entry, ok := w.schedule.get(id)
+		switch {
+		case !ok:
+			w.schedule.set(id, interval, now)
+		case entry.interval != interval:
+			w.schedule.set(id, interval, later(entry.due.Add(interval-entry.interval), now))
+		}
 	}
-
-	wg.Wait()
 }
 
-func (w *MonitorWorker) checkMonitor(monitorID string, url string) {
-	start := time.Now()
+// dispatchDue starts the checks that are due. Each one schedules the
+// monitor's next check once it knows when that is; a monitor whose previous
+// check is still running here is pushed back by an interval.
+func (w *MonitorWorker) dispatchDue(now time.Time) {
+	for _, entry := range w.schedule.popDue(now) {
+		w.runningMu.Lock()
+		running := w.running[entry.monitorID]
+		w.running[entry.monitorID] = true
+		w.runningMu.Unlock()
+		if running {
+			w.schedule.set(entry.monitorID, entry.interval, now.Add(entry.interval))
+			continue
+		}
+
+		w.wg.Add(1)
+		go func(entry scheduleEntry) {
+			defer w.wg.Done()
+			defer func() {
+				w.runningMu.Lock()
+				delete(w.running, entry.monitorID)
+				w.runningMu.Unlock()
+			}()
 
-	req, err := http.NewRequestWithContext(w.ctx, "GET", url, nil)
+			select {
+			case w.semaphore <- struct{}{}:
+			case <-w.ctx.Done():
+				return
+			}
+			defer func() { <-w.semaphore }()
+
+			w.check(entry)
+		}(entry)
+	}
+}
+
+// check claims the monitor's due check and runs it. When several workers
+// share the database they all try; the first to claim it runs the check and
+// the rest only schedule the next one. The next check is due one interval
+// after this one was, so checks don't drift by however long they take, and
+// every worker adds its own jitter so the work is spread between them.
+func (w *MonitorWorker) check(entry scheduleEntry) {
+	monitor, err := w.monitors.GetByIDUnscoped(w.ctx, entry.monitorID)
 	if err != nil {
-		w.logResult(monitorID, "down", 0, 0, err.Error())
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Error fetching monitor %s: %v", entry.monitorID, err)
+			w.schedule.set(entry.monitorID, entry.interval, time.Now().Add(entry.interval))
+		}
+		return
+	}
+	if !monitor.IsActive || !monitor.ChecksFrom(models.LocationLocal) {
 		return
 	}
 
-	req.Header.Set("User-Agent", "UptimeNinja/1.0")
+	now := time.Now()
+	interval := time.Duration(monitor.IntervalSeconds) * time.Second
+	if monitor.NextCheckAt != nil && monitor.NextCheckAt.After(now) {
+		w.schedule.set(monitor.ID, interval, monitor.NextCheckAt.Add(scheduleJitter(interval)))
+		return
+	}
 
-	resp, err := w.httpClient.Do(req)
-	responseTime := time.Since(start).Milliseconds()
+	next := now.Add(interval)
+	if monitor.NextCheckAt != nil && monitor.NextCheckAt.Add(interval).After(now) {
+		next = monitor.NextCheckAt.Add(interval)
+	}
+	claimed, err := w.leases.claimCheck(w.ctx, monitor, next, now.Add(checkLeaseDuration(monitor)))
+	if err != nil {
+		log.Printf("Error claiming monitor %s: %v", monitor.ID, err)
+	}
+	w.schedule.set(monitor.ID, interval, next.Add(scheduleJitter(interval)))
+	if !claimed {
+		return
+	}
+	defer w.leases.releaseCheck(monitor.ID)
 
+	mode, _, inMaintenance, err := w.maintenance.Active(w.ctx, monitor.ID, now)
 	if err != nil {
-		w.logResult(monitorID, "down", 0, responseTime, err.Error())
+		log.Printf("Error fetching maintenance windows for monitor %s: %v", monitor.ID, err)
+		return
+	}
+	if inMaintenance && mode == models.MaintenanceModePause {
+		return
+	}
+
+	if monitor.Type == models.MonitorTypeHeartbeat {
+		w.checkHeartbeat(monitor)
+		return
+	}
+	w.checkMonitor(monitor)
+}
+
+// checkLeaseDuration is how long a check can take with all of its retries,
+// plus a margin. A worker that dies mid-check holds the monitor until then.
+func checkLeaseDuration(monitor models.Monitor) time.Duration {
+	attempts := time.Duration(monitor.Retries + 1)
+	delays := time.Duration(monitor.RetryDelayMs) * time.Millisecond * time.Duration(1<<monitor.Retries-1)
+	return attempts*checkTimeout + delays + checkLeaseMargin
+}
+
+func (w *MonitorWorker) checkMonitor(monitor models.Monitor) {
+	checker, ok := w.checkers[monitor.Type]
+	if !ok {
+		w.logResult(monitor, CheckResult{Status: models.MonitorStatusDown, Message: "unsupported monitor type " + monitor.Type})
 		return
 	}
-	defer resp.Body.Close()
 
-	status := "up"
-	if resp.StatusCode >= 400 {
-		status = "down"
+	w.logResult(monitor, checkWithRetries(w.ctx, checker, monitor))
+}
+
+// checkWithRetries repeats a failed check up to monitor.Retries times,
+// doubling the delay between attempts.
+func checkWithRetries(ctx context.Context, checker Checker, monitor models.Monitor) CheckResult {
+	delay := time.Duration(monitor.RetryDelayMs) * time.Millisecond
+	result := checker.Check(ctx, monitor)
+	attempts := 1
+	for ; result.Status == models.MonitorStatusDown && attempts <= monitor.Retries; attempts++ {
+		select {
+		case <-ctx.Done():
+			return result
+		case <-time.After(delay):
+		}
+		delay *= 2
+		result = checker.Check(ctx, monitor)
 	}
 
-	w.logResult(monitorID, status, resp.StatusCode, responseTime, "")
+	if attempts > 1 {
+		if result.Details == nil {
+			result.Details = map[string]string{}
+		}
+		result.Details["attempts"] = strconv.Itoa(attempts)
+	}
+	return result
 }
 
-func (w *MonitorWorker) logResult(monitorID string, status string, statusCode int, responseTimeMs int64, errorMsg string) {
+// checkHeartbeat records a down result when no ping has arrived within the
+// interval plus grace period. Pings themselves are logged as they arrive.
+// Pings sent while the monitor was paused were ignored, so a resumed monitor
+// gets a full period from when it was resumed.
+func (w *MonitorWorker) checkHeartbeat(monitor models.Monitor) {
+	lastPing := monitor.CreatedAt
+	if monitor.LastPingAt != nil {
+		lastPing = *monitor.LastPingAt
+	}
+
+	period := time.Duration(monitor.IntervalSeconds+monitor.GraceSeconds) * time.Second
+	now := time.Now()
+	pauses, err := w.monitors.ListPauses(w.ctx, monitor.ID, now.Add(-period), now)
+	if err != nil {
+		log.Printf("Error fetching pauses of monitor %s: %v", monitor.ID, err)
+		return
+	}
+	resumed := lastPing
+	for _, pause := range pauses {
+		resumed = later(resumed, pause.End)
+	}
+	if now.Before(resumed.Add(period)) {
+		return
+	}
+
+	message := "no ping received since " + lastPing.UTC().Format(time.RFC3339)
+	if monitor.PingStartedAt != nil && monitor.PingStartedAt.After(lastPing) {
+		message = "job started at " + monitor.PingStartedAt.UTC().Format(time.RFC3339) + " but never finished"
+	}
+	w.logResult(monitor, CheckResult{Status: models.MonitorStatusDown, Message: message})
+}
+
+func (w *MonitorWorker) logResult(monitor models.Monitor, result CheckResult) {
 	logEntry := models.MonitorLog{
 		ID:             uuid.NewString(),
-		MonitorID:      monitorID,
-		Status:         status,
-		StatusCode:     statusCode,
-		ResponseTimeMs: responseTimeMs,
-		ErrorMessage:   errorMsg,
+		MonitorID:      monitor.ID,
+		Status:         result.Status,
+		StatusCode:     result.StatusCode,
+		ResponseTimeMs: result.ResponseTime.Milliseconds(),
+		ErrorMessage:   result.Message,
+		Details:        result.Details,
+		Timings:        result.Timings,
+		Location:       models.LocationLocal,
 	}
 
-	if err := w.monitors.CreateLog(w.ctx, logEntry); err != nil {
+	state, changed, err := w.recorder.Record(w.ctx, monitor, logEntry)
+	if err != nil {
 		log.Printf("Error logging monitor result: %v", err)
+		return
+	}
+	if changed {
+		log.Printf("Monitor %s is now %s", monitor.ID, state.Status)
 	}
 }
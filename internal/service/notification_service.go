@@ -0,0 +1,450 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"learn/internal/models"
+	"learn/internal/repository"
+)
+
+var (
+	ErrNotificationChannelNotFound = errors.New("notification channel not found")
+	ErrInvalidNotificationTemplate = errors.New("invalid notification template")
+	ErrInvalidNotificationURL      = errors.New("notification url must use http or https")
+)
+
+const (
+	maxDeliveryAttempts  = 5
+	maxDeliveryList      = 100
+	deliveryBatchSize    = 20
+	deliveryPollInterval = 5 * time.Second
+	deliveryTimeout      = 10 * time.Second
+)
+
+const (
+	defaultSubjectTemplate    = `[{{upper .Status}}] {{.MonitorName}}`
+	defaultMessageTemplate    = `{{.MonitorName}} is {{.Status}}{{if .Error}}: {{.Error}}{{end}} ({{.MonitorURL}})`
+	escalationSubjectTemplate = `[ESCALATION {{.EscalationStep}}] {{.MonitorName}}`
+	escalationMessageTemplate = `{{.MonitorName}} is still down and the incident has not been acknowledged` +
+		`{{if .Error}}: {{.Error}}{{end}} ({{.MonitorURL}})`
+)
+
+var templateFuncs = template.FuncMap{"upper": strings.ToUpper}
+
+type NotificationService struct {
+	ctx           context.Context
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+	wake          chan struct{}
+	notifications repository.NotificationRepository
+	monitors      *MonitorService
+	notifiers     map[string]Notifier
+	leases        *Leases
+	audit         *AuditService
+	egress        *EgressPolicy
+	retryDelay    time.Duration
+}
+
+func NewNotificationService(notifications repository.NotificationRepository, monitors *MonitorService, mailer Mailer, leases *Leases, audit *AuditService, egress *EgressPolicy, retryDelay time.Duration) *NotificationService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &NotificationService{
+		ctx:           ctx,
+		cancel:        cancel,
+		wake:          make(chan struct{}, 1),
+		notifications: notifications,
+		monitors:      monitors,
+		notifiers: map[string]Notifier{
+			models.NotificationChannelWebhook: NewWebhookNotifier(deliveryTimeout, egress),
+			models.NotificationChannelSlack:   NewSlackNotifier(deliveryTimeout, egress),
+			models.NotificationChannelDiscord: NewDiscordNotifier(deliveryTimeout, egress),
+			models.NotificationChannelEmail:   NewEmailNotifier(mailer),
+		},
+		leases:     leases,
+		audit:      audit,
+		egress:     egress,
+		retryDelay: retryDelay,
+	}
+}
+
+func (s *NotificationService) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(deliveryPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+			case <-s.wake:
+			}
+			s.deliverDue()
+		}
+	}()
+}
+
+func (s *NotificationService) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *NotificationService) CreateChannel(ctx context.Context, userID string, channel models.NotificationChannel) (models.NotificationChannel, error) {
+	if channel.Type == models.NotificationChannelEmail {
+		channel.URL = ""
+	} else {
+		channel.Email = ""
+		parsed, err := url.Parse(channel.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return models.NotificationChannel{}, ErrInvalidNotificationURL
+		}
+		if err := s.egress.CheckHost(parsed.Hostname()); err != nil {
+			return models.NotificationChannel{}, err
+		}
+	}
+	if channel.Template != "" {
+		if _, err := renderTemplate(channel.Template, sampleNotificationEvent()); err != nil {
+			return models.NotificationChannel{}, fmt.Errorf("%w: %v", ErrInvalidNotificationTemplate, err)
+		}
+	}
+
+	channel.ID = uuid.NewString()
+	channel.UserID = userID
+	created, err := s.notifications.CreateChannel(ctx, channel)
+	if err != nil {
+		return models.NotificationChannel{}, err
+	}
+
+	s.audit.Record(ctx, userID, models.AuditActionChannelCreated, "notification_channel", created.ID, nil, created)
+	return created, nil
+}
+
+func (s *NotificationService) ListChannels(ctx context.Context, userID string) ([]models.NotificationChannel, error) {
+	return s.notifications.ListChannels(ctx, userID)
+}
+
+func (s *NotificationService) DeleteChannel(ctx context.Context, userID, id string) (bool, error) {
+	channel, err := s.getOwnChannel(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, ErrNotificationChannelNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	deleted, err := s.notifications.DeleteChannel(ctx, userID, id)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+
+	s.audit.Record(ctx, userID, models.AuditActionChannelDeleted, "notification_channel", id, channel, nil)
+	return true, nil
+}
+
+// TestChannel queues a sample alert so the receiving side can be checked
+// without waiting for a monitor to fail.
+func (s *NotificationService) TestChannel(ctx context.Context, userID, id string) (models.NotificationDelivery, error) {
+	channel, err := s.getOwnChannel(ctx, userID, id)
+	if err != nil {
+		return models.NotificationDelivery{}, err
+	}
+
+	event := sampleNotificationEvent()
+	event.OccurredAt = time.Now().UTC()
+	delivery, err := s.enqueue(ctx, channel, event)
+	if err != nil {
+		return models.NotificationDelivery{}, err
+	}
+	s.signal()
+	return delivery, nil
+}
+
+func (s *NotificationService) ListDeliveries(ctx context.Context, userID, channelID string) ([]models.NotificationDelivery, error) {
+	if _, err := s.getOwnChannel(ctx, userID, channelID); err != nil {
+		return nil, err
+	}
+	return s.notifications.ListDeliveries(ctx, channelID, maxDeliveryList)
+}
+
+func (s *NotificationService) ListMonitorChannels(ctx context.Context, userID, monitorID string) ([]models.NotificationChannel, error) {
+	if _, err := s.monitors.monitors.GetByID(ctx, userID, monitorID); err != nil {
+		return nil, err
+	}
+	return s.notifications.ListMonitorChannels(ctx, monitorID)
+}
+
+// SetMonitorChannels replaces the channels alerted for a monitor. Callers may
+// add their own channels and keep or drop channels other members linked.
+func (s *NotificationService) SetMonitorChannels(ctx context.Context, userID, monitorID string, channelIDs []string) ([]models.NotificationChannel, error) {
+	monitor, err := s.monitors.monitors.GetByID(ctx, userID, monitorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.monitors.authorizeWrite(ctx, userID, monitor); err != nil {
+		return nil, err
+	}
+
+	current, err := s.notifications.ListMonitorChannels(ctx, monitorID)
+	if err != nil {
+		return nil, err
+	}
+	linked := map[string]bool{}
+	for _, channel := range current {
+		linked[channel.ID] = true
+	}
+	for _, id := range channelIDs {
+		if linked[id] {
+			continue
+		}
+		if _, err := s.getOwnChannel(ctx, userID, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.notifications.SetMonitorChannels(ctx, monitorID, channelIDs); err != nil {
+		return nil, err
+	}
+	return s.notifications.ListMonitorChannels(ctx, monitorID)
+}
+
+// Notify queues an alert on every channel linked to the monitor.
+func (s *NotificationService) Notify(ctx context.Context, monitor models.Monitor, state models.MonitorState, entry models.MonitorLog) error {
+	channels, err := s.notifications.ListMonitorChannels(ctx, monitor.ID)
+	if err != nil || len(channels) == 0 {
+		return err
+	}
+
+	event := models.NotificationEvent{
+		Event:          "monitor." + state.Status,
+		MonitorID:      monitor.ID,
+		MonitorName:    monitor.Name,
+		MonitorURL:     monitor.URL,
+		Status:         state.Status,
+		PreviousStatus: monitor.Status,
+		StatusCode:     entry.StatusCode,
+		Error:          entry.ErrorMessage,
+		OccurredAt:     time.Now().UTC(),
+	}
+	if state.StatusChangedAt != nil {
+		event.OccurredAt = state.StatusChangedAt.UTC()
+	}
+
+	for _, channel := range channels {
+		if _, err := s.enqueue(ctx, channel, event); err != nil {
+			return err
+		}
+	}
+	s.signal()
+	return nil
+}
+
+// Escalate queues an escalation alert for an unacknowledged incident on the
+// given channels and to the given email addresses.
+func (s *NotificationService) Escalate(ctx context.Context, incident models.Incident, monitor models.Monitor, step int, channels []models.NotificationChannel, recipients []string) error {
+	event := models.NotificationEvent{
+		Event:          models.NotificationEventEscalated,
+		MonitorID:      monitor.ID,
+		MonitorName:    monitor.Name,
+		MonitorURL:     monitor.URL,
+		Status:         models.MonitorStatusDown,
+		PreviousStatus: models.MonitorStatusDown,
+		Error:          incident.FirstError,
+		IncidentID:     incident.ID,
+		EscalationStep: step,
+		OccurredAt:     time.Now().UTC(),
+	}
+
+	for _, recipient := range recipients {
+		channels = append(channels, models.NotificationChannel{Type: models.NotificationChannelEmail, Email: recipient})
+	}
+	for _, channel := range channels {
+		if _, err := s.enqueue(ctx, channel, event); err != nil {
+			return err
+		}
+	}
+	s.signal()
+	return nil
+}
+
+// enqueue stores a pending delivery. A channel without an ID is an ad-hoc
+// email to channel.Email, which is kept on the delivery as its recipient.
+func (s *NotificationService) enqueue(ctx context.Context, channel models.NotificationChannel, event models.NotificationEvent) (models.NotificationDelivery, error) {
+	subjectTemplate, fallbackTemplate := defaultSubjectTemplate, defaultMessageTemplate
+	if event.Event == models.NotificationEventEscalated {
+		subjectTemplate, fallbackTemplate = escalationSubjectTemplate, escalationMessageTemplate
+	}
+	subject, err := renderTemplate(subjectTemplate, event)
+	if err != nil {
+		return models.NotificationDelivery{}, err
+	}
+	messageTemplate := channel.Template
+	if messageTemplate == "" {
+		messageTemplate = fallbackTemplate
+	}
+	message, err := renderTemplate(messageTemplate, event)
+	if err != nil {
+		message, _ = renderTemplate(fallbackTemplate, event)
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return models.NotificationDelivery{}, err
+	}
+
+	now := time.Now()
+	delivery := models.NotificationDelivery{
+		ID:            uuid.NewString(),
+		ChannelID:     channel.ID,
+		MonitorID:     event.MonitorID,
+		IncidentID:    event.IncidentID,
+		Event:         event.Event,
+		Subject:       subject,
+		Message:       message,
+		Payload:       string(payload),
+		Status:        models.DeliveryStatusPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+	}
+	if channel.ID == "" {
+		delivery.Recipient = channel.Email
+	}
+	if err := s.notifications.CreateDelivery(ctx, delivery); err != nil {
+		return models.NotificationDelivery{}, err
+	}
+	return delivery, nil
+}
+
+func (s *NotificationService) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *NotificationService) deliverDue() {
+	if !s.leases.Hold(s.ctx, "notifications", jobLeaseTTL) {
+		return
+	}
+
+	deliveries, err := s.notifications.ListDueDeliveries(s.ctx, time.Now(), deliveryBatchSize)
+	if err != nil {
+		log.Printf("Error fetching due notifications: %v", err)
+		return
+	}
+
+	// The lease only keeps processes from polling at the same time; a batch
+	// can outlast it, so each delivery is also claimed before it is sent. A
+	// claim that is never settled lapses and the delivery is retried.
+	for _, delivery := range deliveries {
+		if s.ctx.Err() != nil {
+			return
+		}
+		now := time.Now()
+		claimed, err := s.notifications.ClaimDelivery(s.ctx, delivery.ID, now, now.Add(2*deliveryTimeout))
+		if err != nil {
+			log.Printf("Error claiming notification delivery %s: %v", delivery.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		s.deliver(delivery)
+	}
+}
+
+// deliver makes one attempt and schedules the next with exponential backoff
+// until maxDeliveryAttempts is reached.
+func (s *NotificationService) deliver(delivery models.NotificationDelivery) {
+	delivery.Attempts++
+
+	channel := models.NotificationChannel{Type: models.NotificationChannelEmail, Email: delivery.Recipient}
+	var err error
+	if delivery.ChannelID != "" {
+		channel, err = s.notifications.GetChannel(s.ctx, delivery.ChannelID)
+	}
+	var code int
+	if err == nil {
+		notifier, ok := s.notifiers[channel.Type]
+		if !ok {
+			err = fmt.Errorf("unsupported channel type %q", channel.Type)
+		} else {
+			ctx, cancel := context.WithTimeout(s.ctx, deliveryTimeout)
+			code, err = notifier.Send(ctx, channel, delivery)
+			cancel()
+		}
+	}
+
+	now := time.Now()
+	delivery.ResponseCode = code
+	delivery.NextAttemptAt = nil
+	switch {
+	case err == nil:
+		delivery.Status = models.DeliveryStatusDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case errors.Is(err, sql.ErrNoRows):
+		delivery.Status = models.DeliveryStatusFailed
+		delivery.LastError = "channel deleted"
+	case delivery.Attempts >= maxDeliveryAttempts:
+		delivery.Status = models.DeliveryStatusFailed
+		delivery.LastError = err.Error()
+	default:
+		next := now.Add(s.retryDelay << (delivery.Attempts - 1))
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = &next
+	}
+
+	if err := s.notifications.UpdateDelivery(s.ctx, delivery); err != nil {
+		log.Printf("Error updating notification delivery %s: %v", delivery.ID, err)
+	}
+}
+
+func (s *NotificationService) getOwnChannel(ctx context.Context, userID, id string) (models.NotificationChannel, error) {
+	channel, err := s.notifications.GetChannel(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.NotificationChannel{}, ErrNotificationChannelNotFound
+		}
+		return models.NotificationChannel{}, err
+	}
+	if channel.UserID != userID {
+		return models.NotificationChannel{}, ErrNotificationChannelNotFound
+	}
+	return channel, nil
+}
+
+func renderTemplate(text string, event models.NotificationEvent) (string, error) {
+	parsed, err := template.New("notification").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := parsed.Execute(&out, event); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func sampleNotificationEvent() models.NotificationEvent {
+	return models.NotificationEvent{
+		Event:          models.NotificationEventTest,
+		MonitorName:    "Example monitor",
+		MonitorURL:     "https://example.com",
+		Status:         models.MonitorStatusDown,
+		PreviousStatus: models.MonitorStatusUp,
+		StatusCode:     503,
+		Error:          "this is a test notification",
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"learn/internal/models"
+)
+
+// Notifier delivers one message to a channel. It returns the HTTP status the
+// receiver answered with, or 0 when the transport has none.
+type Notifier interface {
+	Send(ctx context.Context, channel models.NotificationChannel, delivery models.NotificationDelivery) (int, error)
+}
+
+// WebhookNotifier posts the event as JSON. With a secret set, the body is
+// signed as HMAC-SHA256 over "<timestamp>.<body>".
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+func NewWebhookNotifier(timeout time.Duration, egress *EgressPolicy) *WebhookNotifier {
+	return &WebhookNotifier{client: egress.Client(timeout)}
+}
+
+func (n *WebhookNotifier) Send(ctx context.Context, channel models.NotificationChannel, delivery models.NotificationDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	headers := map[string]string{}
+	if channel.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(channel.Secret))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+		headers["X-Signature-Timestamp"] = timestamp
+		headers["X-Signature"] = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	return postJSON(ctx, n.client, channel.URL, body, headers)
+}
+
+// ChatNotifier posts the rendered message to Slack- or Discord-style incoming
+// webhooks, which differ only in the name of the text field.
+type ChatNotifier struct {
+	client *http.Client
+	field  string
+}
+
+func NewSlackNotifier(timeout time.Duration, egress *EgressPolicy) *ChatNotifier {
+	return &ChatNotifier{client: egress.Client(timeout), field: "text"}
+}
+
+func NewDiscordNotifier(timeout time.Duration, egress *EgressPolicy) *ChatNotifier {
+	return &ChatNotifier{client: egress.Client(timeout), field: "content"}
+}
+
+func (n *ChatNotifier) Send(ctx context.Context, channel models.NotificationChannel, delivery models.NotificationDelivery) (int, error) {
+	body, err := json.Marshal(map[string]string{n.field: delivery.Message})
+	if err != nil {
+		return 0, err
+	}
+	return postJSON(ctx, n.client, channel.URL, body, nil)
+}
+
+type EmailNotifier struct {
+	mailer Mailer
+}
+
+func NewEmailNotifier(mailer Mailer) *EmailNotifier {
+	return &EmailNotifier{mailer: mailer}
+}
+
+func (n *EmailNotifier) Send(ctx context.Context, channel models.NotificationChannel, delivery models.NotificationDelivery) (int, error) {
+	return 0, n.mailer.Send(ctx, channel.Email, delivery.Subject, delivery.Message)
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "UptimeNinja/1.0")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver answered %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
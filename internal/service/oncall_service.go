@@ -0,0 +1,463 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"learn/internal/models"
+	"learn/internal/repository"
+)
+
+var (
+	ErrScheduleNotFound          = errors.New("on-call schedule not found")
+	ErrOverrideNotFound          = errors.New("on-call override not found")
+	ErrEscalationPolicyNotFound  = errors.New("escalation policy not found")
+	ErrOnCallUserNotAllowed      = errors.New("user is not a member of the schedule's organization")
+	ErrInvalidEscalationTarget   = errors.New("invalid escalation target")
+	ErrEscalationPolicyForbidden = errors.New("insufficient role to modify on-call settings")
+)
+
+const (
+	escalationBatchSize    = 20
+	escalationPollInterval = 15 * time.Second
+)
+
+// OnCallService manages on-call schedules and escalation policies, and walks
+// open incidents through their policy's steps until someone acknowledges.
+type OnCallService struct {
+	ctx           context.Context
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+	oncall        repository.OnCallRepository
+	incidents     repository.IncidentRepository
+	users         repository.UserRepository
+	monitors      *MonitorService
+	notifications *NotificationService
+	maintenance   *MaintenanceService
+	leases        *Leases
+	audit         *AuditService
+}
+
+func NewOnCallService(oncall repository.OnCallRepository, incidents repository.IncidentRepository, users repository.UserRepository, monitors *MonitorService, notifications *NotificationService, maintenance *MaintenanceService, leases *Leases, audit *AuditService) *OnCallService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &OnCallService{
+		ctx:           ctx,
+		cancel:        cancel,
+		oncall:        oncall,
+		incidents:     incidents,
+		users:         users,
+		monitors:      monitors,
+		notifications: notifications,
+		maintenance:   maintenance,
+		leases:        leases,
+		audit:         audit,
+	}
+}
+
+func (s *OnCallService) Start() {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(escalationPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				s.escalateDue()
+			}
+		}
+	}()
+}
+
+func (s *OnCallService) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *OnCallService) CreateSchedule(ctx context.Context, userID string, schedule models.OnCallSchedule) (models.OnCallSchedule, error) {
+	if err := s.requireWriter(ctx, userID, userID, schedule.OrganizationID); err != nil {
+		return models.OnCallSchedule{}, err
+	}
+	for _, participant := range schedule.Participants {
+		if err := s.requireEligible(ctx, userID, schedule.OrganizationID, participant); err != nil {
+			return models.OnCallSchedule{}, err
+		}
+	}
+	if schedule.RotationDays == 0 {
+		schedule.RotationDays = models.DefaultRotationDays
+	}
+	if schedule.RotationStart.IsZero() {
+		schedule.RotationStart = time.Now().UTC().Truncate(time.Minute)
+	}
+
+	schedule.ID = uuid.NewString()
+	schedule.UserID = userID
+	created, err := s.oncall.CreateSchedule(ctx, schedule)
+	if err != nil {
+		return models.OnCallSchedule{}, err
+	}
+
+	s.audit.Record(ctx, userID, models.AuditActionScheduleCreated, "oncall_schedule", created.ID, nil, created)
+	return created, nil
+}
+
+func (s *OnCallService) ListSchedules(ctx context.Context, userID string) ([]models.OnCallSchedule, error) {
+	return s.oncall.ListSchedules(ctx, userID)
+}
+
+func (s *OnCallService) GetSchedule(ctx context.Context, userID, id string) (models.OnCallSchedule, error) {
+	schedule, err := s.oncall.GetSchedule(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OnCallSchedule{}, ErrScheduleNotFound
+		}
+		return models.OnCallSchedule{}, err
+	}
+	if !s.monitors.canView(ctx, userID, schedule.UserID, schedule.OrganizationID) {
+		return models.OnCallSchedule{}, ErrScheduleNotFound
+	}
+	return schedule, nil
+}
+
+func (s *OnCallService) DeleteSchedule(ctx context.Context, userID, id string) error {
+	schedule, err := s.GetSchedule(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.requireWriter(ctx, userID, schedule.UserID, schedule.OrganizationID); err != nil {
+		return err
+	}
+
+	deleted, err := s.oncall.DeleteSchedule(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrScheduleNotFound
+	}
+
+	s.audit.Record(ctx, userID, models.AuditActionScheduleDeleted, "oncall_schedule", id, schedule, nil)
+	return nil
+}
+
+// AddOverride puts someone on call for a fixed window regardless of the
+// rotation, e.g. to cover a holiday.
+func (s *OnCallService) AddOverride(ctx context.Context, userID, scheduleID string, override models.OnCallOverride) (models.OnCallSchedule, error) {
+	schedule, err := s.GetSchedule(ctx, userID, scheduleID)
+	if err != nil {
+		return models.OnCallSchedule{}, err
+	}
+	if err := s.requireWriter(ctx, userID, schedule.UserID, schedule.OrganizationID); err != nil {
+		return models.OnCallSchedule{}, err
+	}
+	if err := s.requireEligible(ctx, schedule.UserID, schedule.OrganizationID, override.UserID); err != nil {
+		return models.OnCallSchedule{}, err
+	}
+
+	override.ID = uuid.NewString()
+	override.ScheduleID = scheduleID
+	override.CreatedAt = time.Now().UTC()
+	if err := s.oncall.CreateOverride(ctx, override); err != nil {
+		return models.OnCallSchedule{}, err
+	}
+
+	s.audit.Record(ctx, userID, models.AuditActionOverrideCreated, "oncall_schedule", scheduleID, nil, override)
+	return s.oncall.GetSchedule(ctx, scheduleID)
+}
+
+func (s *OnCallService) DeleteOverride(ctx context.Context, userID, scheduleID, id string) error {
+	schedule, err := s.GetSchedule(ctx, userID, scheduleID)
+	if err != nil {
+		return err
+	}
+	if err := s.requireWriter(ctx, userID, schedule.UserID, schedule.OrganizationID); err != nil {
+		return err
+	}
+
+	deleted, err := s.oncall.DeleteOverride(ctx, scheduleID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrOverrideNotFound
+	}
+	return nil
+}
+
+func (s *OnCallService) CreatePolicy(ctx context.Context, userID string, policy models.EscalationPolicy) (models.EscalationPolicy, error) {
+	if err := s.requireWriter(ctx, userID, userID, policy.OrganizationID); err != nil {
+		return models.EscalationPolicy{}, err
+	}
+	for _, step := range policy.Steps {
+		for _, target := range step.Targets {
+			if err := s.validateTarget(ctx, userID, policy.OrganizationID, target); err != nil {
+				return models.EscalationPolicy{}, err
+			}
+		}
+	}
+
+	policy.ID = uuid.NewString()
+	policy.UserID = userID
+	created, err := s.oncall.CreatePolicy(ctx, policy)
+	if err != nil {
+		return models.EscalationPolicy{}, err
+	}
+
+	s.audit.Record(ctx, userID, models.AuditActionEscalationPolicyCreated, "escalation_policy", created.ID, nil, created)
+	return created, nil
+}
+
+func (s *OnCallService) ListPolicies(ctx context.Context, userID string) ([]models.EscalationPolicy, error) {
+	return s.oncall.ListPolicies(ctx, userID)
+}
+
+func (s *OnCallService) GetPolicy(ctx context.Context, userID, id string) (models.EscalationPolicy, error) {
+	policy, err := s.oncall.GetPolicy(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.EscalationPolicy{}, ErrEscalationPolicyNotFound
+		}
+		return models.EscalationPolicy{}, err
+	}
+	if !s.monitors.canView(ctx, userID, policy.UserID, policy.OrganizationID) {
+		return models.EscalationPolicy{}, ErrEscalationPolicyNotFound
+	}
+	return policy, nil
+}
+
+func (s *OnCallService) DeletePolicy(ctx context.Context, userID, id string) error {
+	policy, err := s.GetPolicy(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.requireWriter(ctx, userID, policy.UserID, policy.OrganizationID); err != nil {
+		return err
+	}
+
+	deleted, err := s.oncall.DeletePolicy(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrEscalationPolicyNotFound
+	}
+
+	s.audit.Record(ctx, userID, models.AuditActionEscalationPolicyDeleted, "escalation_policy", id, policy, nil)
+	return nil
+}
+
+// SetMonitorPolicy attaches an escalation policy to a monitor, or detaches it
+// when policyID is empty. Incidents already open keep their current policy.
+func (s *OnCallService) SetMonitorPolicy(ctx context.Context, userID, monitorID, policyID string) (models.Monitor, error) {
+	monitor, err := s.monitors.monitors.GetByID(ctx, userID, monitorID)
+	if err != nil {
+		return models.Monitor{}, err
+	}
+	if err := s.monitors.authorizeWrite(ctx, userID, monitor); err != nil {
+		return models.Monitor{}, err
+	}
+	if policyID != "" {
+		policy, err := s.GetPolicy(ctx, userID, policyID)
+		if err != nil {
+			return models.Monitor{}, err
+		}
+		if policy.OrganizationID != monitor.OrganizationID {
+			return models.Monitor{}, ErrEscalationPolicyNotFound
+		}
+	}
+
+	if err := s.monitors.monitors.SetEscalationPolicy(ctx, monitorID, policyID); err != nil {
+		return models.Monitor{}, err
+	}
+
+	s.audit.Record(ctx, userID, models.AuditActionMonitorPolicyChanged, "monitor", monitorID,
+		map[string]any{"escalation_policy_id": monitor.EscalationPolicyID},
+		map[string]any{"escalation_policy_id": policyID})
+	return s.monitors.monitors.GetByID(ctx, userID, monitorID)
+}
+
+func (s *OnCallService) escalateDue() {
+	if !s.leases.Hold(s.ctx, "escalations", jobLeaseTTL) {
+		return
+	}
+
+	incidents, err := s.incidents.ListDueEscalations(s.ctx, time.Now(), escalationBatchSize)
+	if err != nil {
+		log.Printf("Error fetching due escalations: %v", err)
+		return
+	}
+
+	for _, incident := range incidents {
+		if s.ctx.Err() != nil {
+			return
+		}
+		if err := s.escalate(incident); err != nil {
+			log.Printf("Error escalating incident %s: %v", incident.ID, err)
+		}
+	}
+}
+
+// escalate fires the incident's next step and schedules the one after it.
+// The step is claimed before alerts are queued so that an acknowledgement
+// arriving in between wins. Steps due during maintenance wait for it to end.
+func (s *OnCallService) escalate(incident models.Incident) error {
+	_, end, inMaintenance, err := s.maintenance.Active(s.ctx, incident.MonitorID, time.Now())
+	if err != nil {
+		return err
+	}
+	if inMaintenance {
+		_, err := s.incidents.AdvanceEscalation(s.ctx, incident.ID, incident.EscalationStep, incident.EscalationStep, &end)
+		return err
+	}
+
+	policy, err := s.oncall.GetPolicy(s.ctx, incident.EscalationPolicyID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if err != nil || incident.EscalationStep >= len(policy.Steps) {
+		_, err := s.incidents.AdvanceEscalation(s.ctx, incident.ID, incident.EscalationStep, incident.EscalationStep, nil)
+		return err
+	}
+
+	step := policy.Steps[incident.EscalationStep]
+	var next *time.Time
+	if incident.EscalationStep+1 < len(policy.Steps) {
+		at := time.Now().Add(time.Duration(policy.Steps[incident.EscalationStep+1].DelayMinutes) * time.Minute)
+		next = &at
+	}
+	claimed, err := s.incidents.AdvanceEscalation(s.ctx, incident.ID, incident.EscalationStep, incident.EscalationStep+1, next)
+	if err != nil || !claimed {
+		return err
+	}
+
+	monitor, err := s.monitors.monitors.GetByIDUnscoped(s.ctx, incident.MonitorID)
+	if err != nil {
+		return err
+	}
+	channels, recipients := s.resolveTargets(step.Targets)
+	if len(channels) == 0 && len(recipients) == 0 {
+		log.Printf("Escalation step %d of incident %s has nobody to notify", incident.EscalationStep+1, incident.ID)
+		return nil
+	}
+	return s.notifications.Escalate(s.ctx, incident, monitor, incident.EscalationStep+1, channels, recipients)
+}
+
+// resolveTargets turns a step's targets into channels and email addresses.
+// Targets that no longer exist are skipped so the rest of the step still fires.
+func (s *OnCallService) resolveTargets(targets []models.EscalationTarget) ([]models.NotificationChannel, []string) {
+	var channels []models.NotificationChannel
+	var recipients []string
+	seen := map[string]bool{}
+	addUser := func(id string) {
+		if id == "" || seen[id] {
+			return
+		}
+		seen[id] = true
+		user, err := s.users.GetByID(s.ctx, id)
+		if err != nil {
+			log.Printf("Error resolving escalation user %s: %v", id, err)
+			return
+		}
+		recipients = append(recipients, user.Email)
+	}
+
+	for _, target := range targets {
+		switch target.Type {
+		case models.EscalationTargetUser:
+			addUser(target.ID)
+		case models.EscalationTargetSchedule:
+			schedule, err := s.oncall.GetSchedule(s.ctx, target.ID)
+			if err != nil {
+				log.Printf("Error resolving escalation schedule %s: %v", target.ID, err)
+				continue
+			}
+			addUser(schedule.OnCallAt(time.Now()))
+		case models.EscalationTargetChannel:
+			if seen[target.ID] {
+				continue
+			}
+			seen[target.ID] = true
+			channel, err := s.notifications.notifications.GetChannel(s.ctx, target.ID)
+			if err != nil {
+				log.Printf("Error resolving escalation channel %s: %v", target.ID, err)
+				continue
+			}
+			channels = append(channels, channel)
+		}
+	}
+	return channels, recipients
+}
+
+// validateTarget checks that a policy only points at schedules in its own
+// scope, users who belong there and channels the author owns.
+func (s *OnCallService) validateTarget(ctx context.Context, userID, organizationID string, target models.EscalationTarget) error {
+	switch target.Type {
+	case models.EscalationTargetSchedule:
+		schedule, err := s.GetSchedule(ctx, userID, target.ID)
+		if err != nil {
+			if errors.Is(err, ErrScheduleNotFound) {
+				return ErrInvalidEscalationTarget
+			}
+			return err
+		}
+		if schedule.OrganizationID != organizationID {
+			return ErrInvalidEscalationTarget
+		}
+	case models.EscalationTargetUser:
+		if err := s.requireEligible(ctx, userID, organizationID, target.ID); err != nil {
+			return err
+		}
+	case models.EscalationTargetChannel:
+		if _, err := s.notifications.getOwnChannel(ctx, userID, target.ID); err != nil {
+			if errors.Is(err, ErrNotificationChannelNotFound) {
+				return ErrInvalidEscalationTarget
+			}
+			return err
+		}
+	default:
+		return ErrInvalidEscalationTarget
+	}
+	return nil
+}
+
+// requireEligible checks that someone can be put on call: a member of the
+// organization, or the owner themselves for personal schedules.
+func (s *OnCallService) requireEligible(ctx context.Context, ownerID, organizationID, userID string) error {
+	if organizationID == "" {
+		if userID != ownerID {
+			return ErrOnCallUserNotAllowed
+		}
+		return nil
+	}
+	if _, err := s.monitors.organizations.GetMemberRole(ctx, organizationID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrOnCallUserNotAllowed
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *OnCallService) requireWriter(ctx context.Context, userID, ownerID, organizationID string) error {
+	if organizationID == "" {
+		if userID != ownerID {
+			return ErrEscalationPolicyForbidden
+		}
+		return nil
+	}
+	if err := s.monitors.requireOrgWriter(ctx, userID, organizationID); err != nil {
+		if errors.Is(err, ErrMonitorForbidden) {
+			return ErrEscalationPolicyForbidden
+		}
+		return err
+	}
+	return nil
+}
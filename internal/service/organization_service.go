@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"learn/internal/models"
+	"learn/internal/repository"
+)
+
+var (
+	ErrOrganizationNotFound  = errors.New("organization not found")
+	ErrOrganizationForbidden = errors.New("insufficient organization role")
+	ErrMemberNotFound        = errors.New("member not found")
+	ErrLastOwner             = errors.New("organization must keep at least one owner")
+	ErrInviteNotFound        = errors.New("invite not found or expired")
+	ErrInviteEmailMismatch   = errors.New("invite was sent to a different email")
+	ErrAlreadyMember         = repository.ErrAlreadyMember
+)
+
+type OrganizationService struct {
+	organizations repository.OrganizationRepository
+	mailer        Mailer
+	audit         *AuditService
+	inviteTTL     time.Duration
+	publicURL     string
+}
+
+func NewOrganizationService(organizations repository.OrganizationRepository, mailer Mailer, audit *AuditService, inviteTTL time.Duration, publicURL string) *OrganizationService {
+	if inviteTTL <= 0 {
+		inviteTTL = 7 * 24 * time.Hour
+	}
+	return &OrganizationService{
+		organizations: organizations,
+		mailer:        mailer,
+		audit:         audit,
+		inviteTTL:     inviteTTL,
+		publicURL:     strings.TrimRight(publicURL, "/"),
+	}
+}
+
+func (s *OrganizationService) Create(ctx context.Context, userID, name string) (models.Organization, error) {
+	organization, err := s.organizations.Create(ctx, models.Organization{
+		ID:        uuid.NewString(),
+		Name:      name,
+		CreatedBy: userID,
+	})
+	if err != nil {
+		return models.Organization{}, err
+	}
+
+	s.audit.Record(ctx, userID, models.AuditActionOrganizationCreated, "organization", organization.ID, nil, organization)
+	return organization, nil
+}
+
+func (s *OrganizationService) List(ctx context.Context, userID string) ([]models.Organization, error) {
+	return s.organizations.ListByUser(ctx, userID)
+}
+
+func (s *OrganizationService) Get(ctx context.Context, userID, id string) (models.Organization, []models.OrganizationMember, error) {
+	organization, err := s.organizations.GetForUser(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Organization{}, nil, ErrOrganizationNotFound
+		}
+		return models.Organization{}, nil, err
+	}
+
+	members, err := s.organizations.ListMembers(ctx, id)
+	if err != nil {
+		return models.Organization{}, nil, err
+	}
+
+	return organization, members, nil
+}
+
+func (s *OrganizationService) UpdateMemberRole(ctx context.Context, actorID, organizationID, memberID, role string) error {
+	actorRole, err := s.requireRole(ctx, actorID, organizationID, models.OrgRoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	currentRole, err := s.memberRole(ctx, organizationID, memberID)
+	if err != nil {
+		return err
+	}
+
+	if (currentRole == models.OrgRoleOwner || role == models.OrgRoleOwner) && actorRole != models.OrgRoleOwner {
+		return ErrOrganizationForbidden
+	}
+	if currentRole == models.OrgRoleOwner && role != models.OrgRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, organizationID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.organizations.UpdateMemberRole(ctx, organizationID, memberID, role); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, actorID, models.AuditActionMemberRoleChanged, "organization", organizationID,
+		map[string]string{"user_id": memberID, "role": currentRole},
+		map[string]string{"user_id": memberID, "role": role})
+	return nil
+}
+
+func (s *OrganizationService) RemoveMember(ctx context.Context, actorID, organizationID, memberID string) error {
+	actorRole, err := s.requireRole(ctx, actorID, organizationID, models.OrgRoleViewer)
+	if err != nil {
+		return err
+	}
+
+	currentRole, err := s.memberRole(ctx, organizationID, memberID)
+	if err != nil {
+		return err
+	}
+
+	if actorID != memberID {
+		if !models.OrgRoleAtLeast(actorRole, models.OrgRoleAdmin) {
+			return ErrOrganizationForbidden
+		}
+		if currentRole == models.OrgRoleOwner && actorRole != models.OrgRoleOwner {
+			return ErrOrganizationForbidden
+		}
+	}
+	if currentRole == models.OrgRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, organizationID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.organizations.RemoveMember(ctx, organizationID, memberID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, actorID, models.AuditActionMemberRemoved, "organization", organizationID,
+		map[string]string{"user_id": memberID, "role": currentRole}, nil)
+	return nil
+}
+
+func (s *OrganizationService) Invite(ctx context.Context, actor models.User, organizationID, email, role string) (models.OrganizationInvite, string, error) {
+	actorRole, err := s.requireRole(ctx, actor.ID, organizationID, models.OrgRoleAdmin)
+	if err != nil {
+		return models.OrganizationInvite{}, "", err
+	}
+	if role == models.OrgRoleOwner && actorRole != models.OrgRoleOwner {
+		return models.OrganizationInvite{}, "", ErrOrganizationForbidden
+	}
+
+	organization, err := s.organizations.GetForUser(ctx, actor.ID, organizationID)
+	if err != nil {
+		return models.OrganizationInvite{}, "", err
+	}
+
+	token, err := generateToken(32)
+	if err != nil {
+		return models.OrganizationInvite{}, "", err
+	}
+
+	invite, err := s.organizations.CreateInvite(ctx, models.OrganizationInvite{
+		ID:             uuid.NewString(),
+		OrganizationID: organizationID,
+		Email:          strings.ToLower(strings.TrimSpace(email)),
+		Role:           role,
+		TokenHash:      hashToken(token),
+		InvitedBy:      actor.ID,
+		ExpiresAt:      time.Now().Add(s.inviteTTL),
+	})
+	if err != nil {
+		return models.OrganizationInvite{}, "", err
+	}
+
+	body := fmt.Sprintf("%s invited you to join %s as %s.\n\nSign in with this email address and accept the invitation:\n\nPOST %s/invites/%s/accept\n\nThis invitation expires on %s.",
+		actor.Username, organization.Name, role, s.publicURL, token, invite.ExpiresAt.UTC().Format(time.RFC1123))
+	if err := s.mailer.Send(ctx, invite.Email, "You're invited to join "+organization.Name, body); err != nil {
+		log.Printf("Error sending invite %s: %v", invite.ID, err)
+	}
+
+	s.audit.Record(ctx, actor.ID, models.AuditActionInviteCreated, "organization", organizationID, nil,
+		map[string]string{"invite_id": invite.ID, "email": invite.Email, "role": role})
+	return invite, token, nil
+}
+
+func (s *OrganizationService) ListInvites(ctx context.Context, actorID, organizationID string) ([]models.OrganizationInvite, error) {
+	if _, err := s.requireRole(ctx, actorID, organizationID, models.OrgRoleAdmin); err != nil {
+		return nil, err
+	}
+	return s.organizations.ListPendingInvites(ctx, organizationID, time.Now())
+}
+
+func (s *OrganizationService) RevokeInvite(ctx context.Context, actorID, organizationID, inviteID string) error {
+	if _, err := s.requireRole(ctx, actorID, organizationID, models.OrgRoleAdmin); err != nil {
+		return err
+	}
+
+	deleted, err := s.organizations.DeleteInvite(ctx, organizationID, inviteID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrInviteNotFound
+	}
+
+	s.audit.Record(ctx, actorID, models.AuditActionInviteRevoked, "organization", organizationID,
+		map[string]string{"invite_id": inviteID}, nil)
+	return nil
+}
+
+func (s *OrganizationService) AcceptInvite(ctx context.Context, user models.User, token string) (models.Organization, error) {
+	invite, err := s.organizations.GetInviteByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Organization{}, ErrInviteNotFound
+		}
+		return models.Organization{}, err
+	}
+	if invite.AcceptedAt != nil || time.Now().After(invite.ExpiresAt) {
+		return models.Organization{}, ErrInviteNotFound
+	}
+	if !strings.EqualFold(invite.Email, user.Email) {
+		return models.Organization{}, ErrInviteEmailMismatch
+	}
+
+	if err := s.organizations.AcceptInvite(ctx, invite, user.ID, time.Now()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Organization{}, ErrInviteNotFound
+		}
+		return models.Organization{}, err
+	}
+
+	s.audit.Record(ctx, user.ID, models.AuditActionInviteAccepted, "organization", invite.OrganizationID, nil,
+		map[string]string{"invite_id": invite.ID, "role": invite.Role})
+
+	return s.organizations.GetForUser(ctx, user.ID, invite.OrganizationID)
+}
+
+func (s *OrganizationService) requireRole(ctx context.Context, userID, organizationID, required string) (string, error) {
+	role, err := s.organizations.GetMemberRole(ctx, organizationID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrOrganizationNotFound
+		}
+		return "", err
+	}
+	if !models.OrgRoleAtLeast(role, required) {
+		return "", ErrOrganizationForbidden
+	}
+	return role, nil
+}
+
+func (s *OrganizationService) memberRole(ctx context.Context, organizationID, userID string) (string, error) {
+	role, err := s.organizations.GetMemberRole(ctx, organizationID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrMemberNotFound
+		}
+		return "", err
+	}
+	return role, nil
+}
+
+func (s *OrganizationService) ensureAnotherOwner(ctx context.Context, organizationID string) error {
+	owners, err := s.organizations.CountOwners(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
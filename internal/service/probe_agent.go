@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"learn/internal/models"
+)
+
+const (
+	probeSyncInterval   = 30 * time.Second
+	probeFlushInterval  = 2 * time.Second
+	probeRequestTimeout = 15 * time.Second
+	maxPendingResults   = 1000
+)
+
+// ProbeAgent checks monitors on behalf of a remote server. It pulls the
+// monitors assigned to its location, checks them on their own schedule and
+// pushes the results back in batches.
+type ProbeAgent struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	serverURL string
+	token     string
+	client    *http.Client
+	checkers  map[string]Checker
+	schedule  *MonitorSchedule
+	semaphore chan struct{}
+	mu        sync.Mutex
+	checks    map[string]models.Monitor
+	running   map[string]bool
+	pending   []models.ProbeResult
+}
+
+func NewProbeAgent(serverURL, token string, egress *EgressPolicy) *ProbeAgent {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ProbeAgent{
+		ctx:       ctx,
+		cancel:    cancel,
+		serverURL: strings.TrimRight(serverURL, "/"),
+		token:     token,
+		client:    &http.Client{Timeout: probeRequestTimeout},
+		checkers:  newCheckers(egress),
+		schedule:  NewMonitorSchedule(),
+		semaphore: make(chan struct{}, maxConcurrentChecks),
+		checks:    make(map[string]models.Monitor),
+		running:   make(map[string]bool),
+	}
+}
+
+func (a *ProbeAgent) Start() {
+	log.Printf("Probe agent started, reporting to %s", a.serverURL)
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		a.run()
+	}()
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(probeFlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-a.ctx.Done():
+				return
+			case <-ticker.C:
+				a.flush(a.ctx)
+			}
+		}
+	}()
+}
+
+// Stop waits for running checks and sends their results before returning.
+func (a *ProbeAgent) Stop() {
+	a.cancel()
+	a.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeRequestTimeout)
+	defer cancel()
+	a.flush(ctx)
+	log.Println("Probe agent stopped")
+}
+
+func (a *ProbeAgent) run() {
+	a.sync()
+
+	syncTicker := time.NewTicker(probeSyncInterval)
+	defer syncTicker.Stop()
+	timer := time.NewTimer(probeSyncInterval)
+	defer timer.Stop()
+
+	for {
+		a.dispatchDue(time.Now())
+
+		wait := probeSyncInterval
+		if due, ok := a.schedule.next(); ok {
+			wait = time.Until(due)
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-timer.C:
+		case <-syncTicker.C:
+			a.sync()
+		}
+	}
+}
+
+// sync fetches the monitors assigned to this probe. New monitors are checked
+// straight away, changed intervals shift the next check and monitors no
+// longer assigned are dropped.
+func (a *ProbeAgent) sync() {
+	checks, err := a.fetchChecks()
+	if err != nil {
+		log.Printf("Error fetching probe checks: %v", err)
+		return
+	}
+
+	now := time.Now()
+	assigned := make(map[string]models.Monitor, len(checks))
+	for _, check := range checks {
+		monitor := check.Monitor
+		monitor.Headers = make([]models.MonitorHeader, 0, len(check.Headers))
+		for _, header := range check.Headers {
+			monitor.Headers = append(monitor.Headers, models.MonitorHeader{Name: header.Name, Value: header.Value})
+		}
+		assigned[monitor.ID] = monitor
+
+		interval := time.Duration(monitor.IntervalSeconds) * time.Second
+		entry, ok := a.schedule.get(monitor.ID)
+		switch {
+		case !ok:
+			a.schedule.set(monitor.ID, interval, now.Add(scheduleJitter(interval)))
+		case entry.interval != interval:
+			a.schedule.set(monitor.ID, interval, later(entry.due.Add(interval-entry.interval), now))
+		}
+	}
+
+	for _, id := range a.schedule.ids() {
+		if _, ok := assigned[id]; !ok {
+			a.schedule.remove(id)
+		}
+	}
+
+	a.mu.Lock()
+	a.checks = assigned
+	a.mu.Unlock()
+}
+
+func (a *ProbeAgent) fetchChecks() ([]models.ProbeCheck, error) {
+	ctx, cancel := context.WithTimeout(a.ctx, probeRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.serverURL+"/probes/checks", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	req.Header.Set("User-Agent", "UptimeNinja/1.0")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		return nil, fmt.Errorf("server answered %d", resp.StatusCode)
+	}
+
+	var envelope struct {
+		Data []models.ProbeCheck `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Data, nil
+}
+
+// dispatchDue starts the checks that are due and schedules the next ones. A
+// monitor whose previous check is still running is skipped this time.
+func (a *ProbeAgent) dispatchDue(now time.Time) {
+	for _, entry := range a.schedule.popDue(now) {
+		a.mu.Lock()
+		monitor, assigned := a.checks[entry.monitorID]
+		running := a.running[entry.monitorID]
+		if assigned && !running {
+			a.running[entry.monitorID] = true
+		}
+		a.mu.Unlock()
+		if !assigned {
+			continue
+		}
+
+		a.schedule.set(entry.monitorID, entry.interval, later(entry.due.Add(entry.interval), now))
+		if running {
+			continue
+		}
+
+		a.wg.Add(1)
+		go func(monitor models.Monitor) {
+			defer a.wg.Done()
+			defer func() {
+				a.mu.Lock()
+				delete(a.running, monitor.ID)
+				a.mu.Unlock()
+			}()
+
+			select {
+			case a.semaphore <- struct{}{}:
+			case <-a.ctx.Done():
+				return
+			}
+			defer func() { <-a.semaphore }()
+
+			a.check(monitor)
+		}(monitor)
+	}
+}
+
+func (a *ProbeAgent) check(monitor models.Monitor) {
+	result := CheckResult{Status: models.MonitorStatusDown, Message: "unsupported monitor type " + monitor.Type}
+	if checker, ok := a.checkers[monitor.Type]; ok {
+		result = checkWithRetries(a.ctx, checker, monitor)
+	}
+	if a.ctx.Err() != nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// Keep the newest results if the server has been unreachable for a while.
+	if len(a.pending) >= maxPendingResults {
+		a.pending = a.pending[1:]
+	}
+	a.pending = append(a.pending, models.ProbeResult{
+		MonitorID:      monitor.ID,
+		Status:         result.Status,
+		StatusCode:     result.StatusCode,
+		ResponseTimeMs: result.ResponseTime.Milliseconds(),
+		ErrorMessage:   result.Message,
+		Details:        result.Details,
+		Timings:        result.Timings,
+	})
+}
+
+// flush sends pending results. They are kept for the next attempt if the
+// server can't be reached, but dropped if it rejects them.
+func (a *ProbeAgent) flush(ctx context.Context) {
+	a.mu.Lock()
+	results := a.pending
+	a.pending = nil
+	a.mu.Unlock()
+	if len(results) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(map[string][]models.ProbeResult{"results": results})
+	if err != nil {
+		log.Printf("Error encoding probe results: %v", err)
+		return
+	}
+
+	status, err := postJSON(ctx, a.client, a.serverURL+"/probes/results", body, map[string]string{"Authorization": "Bearer " + a.token})
+	if err == nil {
+		return
+	}
+	log.Printf("Error sending %d probe results: %v", len(results), err)
+	if status != 0 && status < http.StatusInternalServerError {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pending = append(results, a.pending...)
+	if len(a.pending) > maxPendingResults {
+		a.pending = a.pending[len(a.pending)-maxPendingResults:]
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"learn/internal/models"
+	"learn/internal/repository"
+)
+
+var (
+	ErrProbeNotFound         = errors.New("probe not found")
+	ErrProbeUnauthorized     = errors.New("invalid probe token")
+	ErrProbeLocationReserved = errors.New("location is reserved for checks run by the server")
+)
+
+var probeResultStatuses = []string{models.MonitorStatusUp, models.MonitorStatusDown, models.MonitorStatusDegraded}
+
+// ProbeService registers probes and exchanges checks and results with them.
+type ProbeService struct {
+	probes      repository.ProbeRepository
+	monitors    repository.MonitorRepository
+	recorder    *CheckRecorder
+	maintenance *MaintenanceService
+	audit       *AuditService
+}
+
+func NewProbeService(probes repository.ProbeRepository, monitors repository.MonitorRepository, recorder *CheckRecorder, maintenance *MaintenanceService, audit *AuditService) *ProbeService {
+	return &ProbeService{probes: probes, monitors: monitors, recorder: recorder, maintenance: maintenance, audit: audit}
+}
+
+// Create registers a probe for location. The returned token is what the probe
+// authenticates with; only its hash is stored.
+func (s *ProbeService) Create(ctx context.Context, actorID, location string) (models.Probe, string, error) {
+	if location == models.LocationLocal {
+		return models.Probe{}, "", ErrProbeLocationReserved
+	}
+
+	token, err := generateToken(32)
+	if err != nil {
+		return models.Probe{}, "", err
+	}
+
+	probe, err := s.probes.Create(ctx, models.Probe{
+		ID:        uuid.NewString(),
+		Location:  location,
+		TokenHash: hashToken(token),
+	})
+	if err != nil {
+		return models.Probe{}, "", err
+	}
+
+	s.audit.Record(ctx, actorID, models.AuditActionProbeCreated, "probe", probe.ID, nil, probe)
+	return probe, token, nil
+}
+
+func (s *ProbeService) List(ctx context.Context) ([]models.Probe, error) {
+	return s.probes.List(ctx)
+}
+
+func (s *ProbeService) Delete(ctx context.Context, actorID, id string) error {
+	probe, err := s.probes.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrProbeNotFound
+		}
+		return err
+	}
+
+	deleted, err := s.probes.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrProbeNotFound
+	}
+
+	s.audit.Record(ctx, actorID, models.AuditActionProbeDeleted, "probe", id, probe, nil)
+	return nil
+}
+
+// Locations lists where monitors can be checked from: the server itself and
+// every registered probe.
+func (s *ProbeService) Locations(ctx context.Context) ([]models.Location, error) {
+	probes, err := s.probes.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := []models.Location{{Name: models.LocationLocal}}
+	for _, probe := range probes {
+		locations = append(locations, models.Location{Name: probe.Location, LastSeenAt: probe.LastSeenAt})
+	}
+	return locations, nil
+}
+
+// Authenticate finds the probe a token belongs to and notes that it was seen.
+func (s *ProbeService) Authenticate(ctx context.Context, token string) (models.Probe, error) {
+	if token == "" {
+		return models.Probe{}, ErrProbeUnauthorized
+	}
+
+	probe, err := s.probes.GetByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Probe{}, ErrProbeUnauthorized
+		}
+		return models.Probe{}, err
+	}
+
+	now := time.Now()
+	if err := s.probes.Touch(ctx, probe.ID, now); err != nil {
+		log.Printf("Error updating last seen time of probe %s: %v", probe.ID, err)
+	}
+	probe.LastSeenAt = &now
+	return probe, nil
+}
+
+// Assignments returns the active monitors the probe should check.
+func (s *ProbeService) Assignments(ctx context.Context, probe models.Probe) ([]models.ProbeCheck, error) {
+	monitors, err := s.monitors.ListActiveByLocation(ctx, probe.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := []models.ProbeCheck{}
+	for _, monitor := range monitors {
+		if monitor.Type == models.MonitorTypeHeartbeat {
+			continue
+		}
+		headers := make([]models.ProbeHeader, 0, len(monitor.Headers))
+		for _, header := range monitor.Headers {
+			if header.Unreadable {
+				continue
+			}
+			headers = append(headers, models.ProbeHeader{Name: header.Name, Value: header.Value})
+		}
+		checks = append(checks, models.ProbeCheck{Monitor: monitor, Headers: headers})
+	}
+	return checks, nil
+}
+
+// Report records results sent by a probe. Results for monitors the probe is
+// not assigned, and ones paused for maintenance, are dropped. It returns how
+// many were recorded.
+func (s *ProbeService) Report(ctx context.Context, probe models.Probe, results []models.ProbeResult) (int, error) {
+	recorded := 0
+	for _, result := range results {
+		if !slices.Contains(probeResultStatuses, result.Status) {
+			continue
+		}
+
+		monitor, err := s.monitors.GetByIDUnscoped(ctx, result.MonitorID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return recorded, err
+		}
+		if !monitor.IsActive || !monitor.ChecksFrom(probe.Location) || monitor.Type == models.MonitorTypeHeartbeat {
+			continue
+		}
+
+		mode, _, inMaintenance, err := s.maintenance.Active(ctx, monitor.ID, time.Now())
+		if err != nil {
+			return recorded, err
+		}
+		if inMaintenance && mode == models.MaintenanceModePause {
+			continue
+		}
+
+		state, changed, err := s.recorder.Record(ctx, monitor, models.MonitorLog{
+			ID:             uuid.NewString(),
+			MonitorID:      monitor.ID,
+			Status:         result.Status,
+			StatusCode:     result.StatusCode,
+			ResponseTimeMs: result.ResponseTimeMs,
+			ErrorMessage:   result.ErrorMessage,
+			Details:        result.Details,
+			Timings:        result.Timings,
+			Location:       probe.Location,
+		})
+		if err != nil {
+			return recorded, err
+		}
+		if changed {
+			log.Printf("Monitor %s is now %s", monitor.ID, state.Status)
+		}
+		recorded++
+	}
+	return recorded, nil
+}
@@ -22,13 +22,14 @@ var (
 
 type SnippetService struct {
 	snippets repository.SnippetRepository
+	audit    *AuditService
 }
 
-func NewSnippetService(snippets repository.SnippetRepository) *SnippetService {
-	return &SnippetService{snippets: snippets}
+func NewSnippetService(snippets repository.SnippetRepository, audit *AuditService) *SnippetService {
+	return &SnippetService{snippets: snippets, audit: audit}
 }
 
-func (s *SnippetService) Create(ctx context.Context, content, password string, burnAfterRead bool, expiresInHours int) (models.Snippet, error) {
+func (s *SnippetService) Create(ctx context.Context, userID, content, password string, burnAfterRead bool, expiresInHours int) (models.Snippet, error) {
 	hash, err := generateHash(8)
 	if err != nil {
 		return models.Snippet{}, err
@@ -53,14 +54,28 @@ func (s *SnippetService) Create(ctx context.Context, content, password string, b
 		expiresAt = &exp
 	}
 
-	return s.snippets.Create(ctx, models.Snippet{
+	var owner *string
+	if userID != "" {
+		owner = &userID
+	}
+
+	snippet, err := s.snippets.Create(ctx, models.Snippet{
 		ID:            uuid.NewString(),
+		UserID:        owner,
 		Hash:          hash,
 		Content:       content,
 		PasswordHash:  hashedPassword,
 		BurnAfterRead: burnAfterRead,
 		ExpiresAt:     expiresAt,
 	})
+	if err != nil {
+		return models.Snippet{}, err
+	}
+
+	after := snippet.Response()
+	after.Content = ""
+	s.audit.Record(ctx, userID, models.AuditActionSnippetCreated, "snippet", snippet.ID, nil, after)
+	return snippet, nil
 }
 
 func (s *SnippetService) Get(ctx context.Context, hash, password string) (models.Snippet, error) {
@@ -93,6 +108,10 @@ func (s *SnippetService) Get(ctx context.Context, hash, password string) (models
 	return snippet, nil
 }
 
+func (s *SnippetService) ListByUser(ctx context.Context, userID string) ([]models.Snippet, error) {
+	return s.snippets.ListByUser(ctx, userID)
+}
+
 func (s *SnippetService) DeleteExpired(ctx context.Context) (int64, error) {
 	return s.snippets.DeleteExpired(ctx, time.Now())
 }
@@ -104,3 +123,11 @@ func generateHash(length int) (string, error) {
 	}
 	return hex.EncodeToString(bytes)[:length], nil
 }
+
+func generateToken(length int) (string, error) {
+	bytes := make([]byte, length)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"learn/internal/models"
+	"learn/internal/repository"
+)
+
+var (
+	ErrStatusPageNotFound = errors.New("status page not found")
+	ErrInvalidStatusPage  = errors.New("invalid status page")
+
+	ErrStatusPageMonitorMismatch = errors.New("monitors must belong to the status page's organization")
+)
+
+type StatusPageService struct {
+	pages       repository.StatusPageRepository
+	monitors    *MonitorService
+	incidents   repository.IncidentRepository
+	maintenance *MaintenanceService
+	audit       *AuditService
+}
+
+func NewStatusPageService(pages repository.StatusPageRepository, monitors *MonitorService, incidents repository.IncidentRepository, maintenance *MaintenanceService, audit *AuditService) *StatusPageService {
+	return &StatusPageService{pages: pages, monitors: monitors, incidents: incidents, maintenance: maintenance, audit: audit}
+}
+
+func (s *StatusPageService) Create(ctx context.Context, userID string, page models.StatusPage) (models.StatusPage, error) {
+	page.ID = uuid.NewString()
+	page.UserID = userID
+	if err := s.checkPage(ctx, userID, page); err != nil {
+		return models.StatusPage{}, err
+	}
+
+	created, err := s.pages.Create(ctx, page)
+	if err != nil {
+		return models.StatusPage{}, err
+	}
+
+	s.audit.Record(ctx, userID, models.AuditActionStatusPageCreated, "status_page", created.ID, nil, created)
+	return created, nil
+}
+
+func (s *StatusPageService) List(ctx context.Context, userID string) ([]models.StatusPage, error) {
+	return s.pages.ListByUser(ctx, userID)
+}
+
+func (s *StatusPageService) Get(ctx context.Context, userID, id string) (models.StatusPage, error) {
+	page, err := s.pages.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.StatusPage{}, ErrStatusPageNotFound
+		}
+		return models.StatusPage{}, err
+	}
+	if !s.monitors.canView(ctx, userID, page.UserID, page.OrganizationID) {
+		return models.StatusPage{}, ErrStatusPageNotFound
+	}
+	return page, nil
+}
+
+func (s *StatusPageService) Update(ctx context.Context, userID, id string, update models.StatusPage) (models.StatusPage, error) {
+	before, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return models.StatusPage{}, err
+	}
+
+	page := before
+	page.Slug = update.Slug
+	page.Title = update.Title
+	page.Description = update.Description
+	page.CustomCSS = update.CustomCSS
+	page.Sections = update.Sections
+	if err := s.checkPage(ctx, userID, page); err != nil {
+		return models.StatusPage{}, err
+	}
+
+	updated, err := s.pages.Update(ctx, userID, page)
+	if err != nil {
+		return models.StatusPage{}, err
+	}
+	if !updated {
+		return models.StatusPage{}, ErrMonitorForbidden
+	}
+
+	after, err := s.pages.GetByID(ctx, id)
+	if err != nil {
+		return models.StatusPage{}, err
+	}
+
+	s.audit.Record(ctx, userID, models.AuditActionStatusPageUpdated, "status_page", id, before, after)
+	return after, nil
+}
+
+func (s *StatusPageService) Delete(ctx context.Context, userID, id string) error {
+	page, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if page.OrganizationID != "" {
+		if err := s.monitors.requireOrgWriter(ctx, userID, page.OrganizationID); err != nil {
+			return err
+		}
+	}
+
+	deleted, err := s.pages.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrStatusPageNotFound
+	}
+
+	s.audit.Record(ctx, userID, models.AuditActionStatusPageDeleted, "status_page", id, page, nil)
+	return nil
+}
+
+// checkPage makes sure userID may publish every monitor on the page. The CSS
+// ends up inside a <style> element, so it may not contain markup.
+func (s *StatusPageService) checkPage(ctx context.Context, userID string, page models.StatusPage) error {
+	if strings.Contains(page.CustomCSS, "<") {
+		return fmt.Errorf("%w: custom_css may not contain <", ErrInvalidStatusPage)
+	}
+
+	if page.OrganizationID != "" {
+		if err := s.monitors.requireOrgWriter(ctx, userID, page.OrganizationID); err != nil {
+			return err
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, monitorID := range page.MonitorIDs() {
+		if seen[monitorID] {
+			return fmt.Errorf("%w: monitor %s appears more than once", ErrInvalidStatusPage, monitorID)
+		}
+		seen[monitorID] = true
+
+		monitor, err := s.monitors.monitors.GetByID(ctx, userID, monitorID)
+		if err != nil {
+			return err
+		}
+		if monitor.OrganizationID != page.OrganizationID {
+			return ErrStatusPageMonitorMismatch
+		}
+		if err := s.monitors.authorizeWrite(ctx, userID, monitor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Public builds the anonymous view of the page with the given slug. Monitors
+// that have since been deleted or moved to another owner are left out.
+func (s *StatusPageService) Public(ctx context.Context, slug string) (models.PublicStatusPage, error) {
+	page, err := s.pages.GetBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.PublicStatusPage{}, ErrStatusPageNotFound
+		}
+		return models.PublicStatusPage{}, err
+	}
+
+	now := time.Now().UTC()
+	firstDay := time.Date(now.Year(), now.Month(), now.Day()-(models.StatusPageDays-1), 0, 0, 0, 0, time.UTC)
+	public := models.PublicStatusPage{
+		Slug:        page.Slug,
+		Title:       page.Title,
+		Description: page.Description,
+		Sections:    []models.PublicStatusSection{},
+		Incidents:   []models.PublicStatusIncident{},
+		GeneratedAt: now,
+		CustomCSS:   page.CustomCSS,
+	}
+
+	var statuses []string
+	for _, section := range page.Sections {
+		publicSection := models.PublicStatusSection{Name: section.Name, Monitors: []models.PublicStatusMonitor{}}
+		for _, monitorID := range section.MonitorIDs {
+			monitor, err := s.monitors.monitors.GetByIDUnscoped(ctx, monitorID)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					continue
+				}
+				return models.PublicStatusPage{}, err
+			}
+			if monitor.OrganizationID != page.OrganizationID || (page.OrganizationID == "" && monitor.UserID != page.UserID) {
+				continue
+			}
+
+			publicMonitor, err := s.publicMonitor(ctx, monitor, firstDay, now)
+			if err != nil {
+				return models.PublicStatusPage{}, err
+			}
+			publicSection.Monitors = append(publicSection.Monitors, publicMonitor)
+			statuses = append(statuses, publicMonitor.Status)
+
+			incident, err := s.incidents.GetOpenByMonitor(ctx, monitor.ID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return models.PublicStatusPage{}, err
+			}
+			if err == nil {
+				public.Incidents = append(public.Incidents, models.PublicStatusIncident{
+					MonitorName:     monitor.Name,
+					StartedAt:       incident.StartedAt,
+					DurationSeconds: int64(now.Sub(incident.StartedAt).Seconds()),
+					Acknowledged:    incident.AcknowledgedAt != nil,
+				})
+			}
+		}
+		public.Sections = append(public.Sections, publicSection)
+	}
+
+	public.Status = overallStatus(statuses)
+	return public, nil
+}
+
+func (s *StatusPageService) publicMonitor(ctx context.Context, monitor models.Monitor, firstDay, now time.Time) (models.PublicStatusMonitor, error) {
+	status := monitor.Status
+	if !monitor.IsActive {
+		status = models.MonitorStatusPending
+	}
+	_, _, active, err := s.maintenance.Active(ctx, monitor.ID, now)
+	if err != nil {
+		return models.PublicStatusMonitor{}, err
+	}
+	if active {
+		status = models.MonitorStatusMaintenance
+	}
+
+	recorded, err := s.monitors.history(ctx, monitor.ID, models.RollupDay, firstDay, now)
+	if err != nil {
+		return models.PublicStatusMonitor{}, err
+	}
+	byDate := make(map[string]models.DailyUptime, len(recorded))
+	for _, bucket := range recorded {
+		date := bucket.BucketStart.Format("2006-01-02")
+		byDate[date] = models.DailyUptime{Date: date, Checks: bucket.Checks, Uptime: bucket.UptimePercentage}
+	}
+
+	days := make([]models.DailyUptime, 0, models.StatusPageDays)
+	for i := range models.StatusPageDays {
+		date := firstDay.AddDate(0, 0, i).Format("2006-01-02")
+		day, ok := byDate[date]
+		if !ok {
+			day = models.DailyUptime{Date: date}
+		}
+		days = append(days, day)
+	}
+
+	report, err := s.monitors.uptime(ctx, monitor, firstDay, now)
+	if err != nil {
+		return models.PublicStatusMonitor{}, err
+	}
+	return models.PublicStatusMonitor{Name: monitor.Name, Status: status, Uptime: report.UptimePercentage, Days: days}, nil
+}
+
+func overallStatus(statuses []string) string {
+	var down, degraded, maintenance int
+	for _, status := range statuses {
+		switch status {
+		case models.MonitorStatusDown:
+			down++
+		case models.MonitorStatusDegraded:
+			degraded++
+		case models.MonitorStatusMaintenance:
+			maintenance++
+		}
+	}
+
+	switch {
+	case down > 0 && down == len(statuses):
+		return models.StatusPageMajorOutage
+	case down > 0:
+		return models.StatusPagePartialOutage
+	case degraded > 0:
+		return models.StatusPageDegraded
+	case maintenance > 0:
+		return models.StatusPageMaintenance
+	default:
+		return models.StatusPageOperational
+	}
+}
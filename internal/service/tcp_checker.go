@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"learn/internal/models"
+)
+
+type TCPChecker struct {
+	dialer *net.Dialer
+}
+
+func NewTCPChecker(timeout time.Duration, egress *EgressPolicy) *TCPChecker {
+	return &TCPChecker{dialer: &net.Dialer{Timeout: timeout, Control: egress.Control}}
+}
+
+func (c *TCPChecker) Check(ctx context.Context, monitor models.Monitor) CheckResult {
+	start := time.Now()
+
+	address, err := hostPort(monitor)
+	if err != nil {
+		return downResult(start, "%v", err)
+	}
+
+	conn, err := c.dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return downResult(start, "%v", err)
+	}
+	responseTime := time.Since(start)
+	conn.Close()
+
+	return CheckResult{
+		Status:       models.MonitorStatusUp,
+		ResponseTime: responseTime,
+		Details:      map[string]string{"remote_addr": conn.RemoteAddr().String()},
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"learn/internal/models"
+)
+
+type TLSChecker struct {
+	dialer *tls.Dialer
+}
+
+func NewTLSChecker(timeout time.Duration, egress *EgressPolicy) *TLSChecker {
+	return &TLSChecker{dialer: &tls.Dialer{NetDialer: &net.Dialer{Timeout: timeout, Control: egress.Control}}}
+}
+
+func (c *TLSChecker) Check(ctx context.Context, monitor models.Monitor) CheckResult {
+	start := time.Now()
+
+	host, port, err := monitorTarget(monitor)
+	if err != nil {
+		return downResult(start, "%v", err)
+	}
+
+	thresholdDays := models.DefaultTLSExpiryThresholdDays
+	if monitor.TLS != nil && monitor.TLS.ExpiryThresholdDays > 0 {
+		thresholdDays = monitor.TLS.ExpiryThresholdDays
+	}
+
+	dialer := *c.dialer
+	dialer.Config = &tls.Config{ServerName: host}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return downResult(start, "TLS handshake failed: %v", err)
+	}
+	responseTime := time.Since(start)
+	defer conn.Close()
+
+	certificates := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return downResult(start, "server presented no certificate")
+	}
+	leaf := certificates[0]
+	remaining := time.Until(leaf.NotAfter)
+	daysRemaining := int(remaining.Hours() / 24)
+
+	result := CheckResult{
+		Status:       models.MonitorStatusUp,
+		ResponseTime: responseTime,
+		Details: map[string]string{
+			"issuer":         leaf.Issuer.String(),
+			"subject":        leaf.Subject.String(),
+			"sans":           strings.Join(leaf.DNSNames, ", "),
+			"not_after":      leaf.NotAfter.UTC().Format(time.RFC3339),
+			"days_remaining": strconv.Itoa(daysRemaining),
+		},
+	}
+
+	switch {
+	case remaining <= 0:
+		result.Status = models.MonitorStatusDown
+		result.Message = "certificate expired on " + leaf.NotAfter.UTC().Format(time.DateOnly)
+	case daysRemaining < thresholdDays:
+		result.Status = models.MonitorStatusDegraded
+		result.Message = fmt.Sprintf("certificate expires in %d days", daysRemaining)
+	}
+
+	return result
+}
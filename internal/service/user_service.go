@@ -2,23 +2,53 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"learn/internal/models"
 	"learn/internal/repository"
 )
 
+var ErrUserNotFound = errors.New("user not found")
+
 type UserService struct {
 	users repository.UserRepository
+	audit *AuditService
+}
+
+func NewUserService(users repository.UserRepository, audit *AuditService) *UserService {
+	return &UserService{users: users, audit: audit}
 }
 
-func NewUserService(users repository.UserRepository) *UserService {
-	return &UserService{users: users}
+func (s *UserService) GetPublic(ctx context.Context, id string) (models.PublicProfile, error) {
+	profile, err := s.users.GetPublicByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.PublicProfile{}, ErrUserNotFound
+		}
+		return models.PublicProfile{}, err
+	}
+	return profile, nil
 }
 
-func (s *UserService) GetByID(ctx context.Context, id string) (models.User, error) {
-	return s.users.GetByID(ctx, id)
+func (s *UserService) List(ctx context.Context, filter models.UserFilter) ([]models.PublicProfile, int, error) {
+	return s.users.ListPublic(ctx, filter)
 }
 
-func (s *UserService) List(ctx context.Context) ([]models.User, error) {
-	return s.users.List(ctx)
+func (s *UserService) UpdateProfile(ctx context.Context, user models.User, displayName, bio *string) (models.User, error) {
+	before := user.Response()
+
+	if displayName != nil {
+		user.DisplayName = *displayName
+	}
+	if bio != nil {
+		user.Bio = *bio
+	}
+
+	if err := s.users.UpdateProfile(ctx, user.ID, user.DisplayName, user.Bio, user.AvatarURL); err != nil {
+		return models.User{}, err
+	}
+
+	s.audit.Record(ctx, user.ID, models.AuditActionProfileUpdated, "user", user.ID, before, user.Response())
+	return user, nil
 }
@@ -0,0 +1,14 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,10 @@
+package types
+
+import "learn/internal/models"
+
+type AuditLogListResponseEnvelope struct {
+	Success bool              `json:"success"`
+	Status  int               `json:"status"`
+	Message string            `json:"message"`
+	Data    []models.AuditLog `json:"data"`
+}
@@ -13,6 +13,11 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required" example:"secret123"`
 }
 
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required" example:"secret123"`
+	NewPassword     string `json:"new_password" validate:"required,min=6,max=100" example:"n3w-secret"`
+}
+
 type AuthResponse struct {
 	Token string              `json:"token"`
 	User  models.UserResponse `json:"user"`
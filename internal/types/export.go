@@ -0,0 +1,15 @@
+package types
+
+import "learn/internal/models"
+
+type ExportResponse struct {
+	models.DataExport
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+type ExportResponseEnvelope struct {
+	Success bool           `json:"success"`
+	Status  int            `json:"status"`
+	Message string         `json:"message"`
+	Data    ExportResponse `json:"data"`
+}
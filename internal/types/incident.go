@@ -0,0 +1,21 @@
+package types
+
+import "learn/internal/models"
+
+type IncidentAckRequest struct {
+	Notes string `json:"notes" validate:"max=2000" example:"Upstream provider outage, following their status page"`
+}
+
+type IncidentResponseEnvelope struct {
+	Success bool            `json:"success"`
+	Status  int             `json:"status"`
+	Message string          `json:"message"`
+	Data    models.Incident `json:"data"`
+}
+
+type IncidentListResponseEnvelope struct {
+	Success bool              `json:"success"`
+	Status  int               `json:"status"`
+	Message string            `json:"message"`
+	Data    []models.Incident `json:"data"`
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"time"
+
+	"learn/internal/models"
+)
+
+type MaintenanceWindowCreateRequest struct {
+	Name           string    `json:"name" validate:"required,min=1,max=100" example:"Weekly deploy"`
+	OrganizationID string    `json:"organization_id" validate:"omitempty,uuid" example:""`
+	StartsAt       time.Time `json:"starts_at" validate:"required" example:"2026-01-04T02:00:00Z"`
+	EndsAt         time.Time `json:"ends_at" validate:"required,gtfield=StartsAt" example:"2026-01-04T02:30:00Z"`
+	Recurrence     string    `json:"recurrence" validate:"max=200" example:"FREQ=WEEKLY;BYDAY=SU"`
+	Timezone       string    `json:"timezone" validate:"max=64" example:"Europe/Berlin"`
+	Mode           string    `json:"mode" validate:"omitempty,oneof=pause record" example:"pause"`
+	MonitorIDs     []string  `json:"monitor_ids" validate:"required,min=1,max=100,dive,uuid"`
+}
+
+// MaintenanceWindowResponse adds whether the window is in effect now and
+// when it next starts.
+type MaintenanceWindowResponse struct {
+	models.MaintenanceWindow
+	Active       bool       `json:"active"`
+	NextStartsAt *time.Time `json:"next_starts_at,omitempty"`
+}
+
+type MaintenanceWindowResponseEnvelope struct {
+	Success bool                      `json:"success"`
+	Status  int                       `json:"status"`
+	Message string                    `json:"message"`
+	Data    MaintenanceWindowResponse `json:"data"`
+}
+
+type MaintenanceWindowListResponseEnvelope struct {
+	Success bool                        `json:"success"`
+	Status  int                         `json:"status"`
+	Message string                      `json:"message"`
+	Data    []MaintenanceWindowResponse `json:"data"`
+}
@@ -1,11 +1,98 @@
 package types
 
-import "learn/internal/models"
+import (
+	"time"
+
+	"learn/internal/models"
+)
+
+type MonitorHeaderRequest struct {
+	Name   string `json:"name" validate:"required,max=256,httpheader" example:"Authorization"`
+	Value  string `json:"value" validate:"max=8192" example:"Bearer abc123"`
+	Secret bool   `json:"secret" example:"true"`
+}
+
+type MonitorAssertionRequest struct {
+	Type   string `json:"type" validate:"required,oneof=body_contains body_not_contains body_regex json_path_equals json_path_exists header_matches body_max_bytes" example:"json_path_equals"`
+	Target string `json:"target" validate:"max=256" example:"$.status"`
+	Value  string `json:"value" validate:"max=1024" example:"ok"`
+}
+
+type MonitorDNSRequest struct {
+	RecordType string `json:"record_type" validate:"omitempty,oneof=A AAAA CNAME MX TXT NS" example:"A"`
+	Expected   string `json:"expected" validate:"max=255" example:"93.184.216.34"`
+	Resolver   string `json:"resolver" validate:"omitempty,hostname_port|ip" example:"1.1.1.1:53"`
+}
+
+type MonitorTLSRequest struct {
+	ExpiryThresholdDays int `json:"expiry_threshold_days" validate:"omitempty,min=1,max=365" example:"14"`
+}
 
 type MonitorCreateRequest struct {
-	Name            string `json:"name" validate:"required,min=1,max=100" example:"Google"`
-	URL             string `json:"url" validate:"required,url" example:"https://google.com"`
-	IntervalSeconds int    `json:"interval_seconds" validate:"omitempty,min=60,max=86400" example:"300"`
+	Name              string                    `json:"name" validate:"required,min=1,max=100" example:"Google"`
+	Type              string                    `json:"type" validate:"omitempty,oneof=http tcp dns tls heartbeat" example:"http"`
+	URL               string                    `json:"url" validate:"required_unless=Type heartbeat,omitempty,url" example:"https://google.com"`
+	IntervalSeconds   int                       `json:"interval_seconds" validate:"omitempty,min=10,max=86400" example:"300"`
+	OrganizationID    string                    `json:"organization_id" validate:"omitempty,uuid" example:""`
+	Method            string                    `json:"method" validate:"omitempty,oneof=GET HEAD POST PUT" example:"GET"`
+	Headers           []MonitorHeaderRequest    `json:"headers" validate:"max=50,dive"`
+	Body              string                    `json:"body" validate:"max=65536" example:""`
+	AcceptedStatuses  string                    `json:"accepted_statuses" validate:"omitempty,statusranges" example:"200-299,301"`
+	FollowRedirects   *bool                     `json:"follow_redirects" example:"true"`
+	Assertions        []MonitorAssertionRequest `json:"assertions" validate:"max=20,dive"`
+	MaxResponseBytes  int64                     `json:"max_response_bytes" validate:"omitempty,min=1,max=10485760" example:"1048576"`
+	DNS               *MonitorDNSRequest        `json:"dns"`
+	TLS               *MonitorTLSRequest        `json:"tls"`
+	GraceSeconds      *int                      `json:"grace_seconds" validate:"omitnil,min=0,max=604800" example:"300"`
+	Retries           int                       `json:"retries" validate:"min=0,max=5" example:"2"`
+	RetryDelayMs      int                       `json:"retry_delay_ms" validate:"omitempty,min=100,max=10000" example:"1000"`
+	FailureThreshold  int                       `json:"failure_threshold" validate:"omitempty,min=1,max=10" example:"3"`
+	RecoveryThreshold int                       `json:"recovery_threshold" validate:"omitempty,min=1,max=10" example:"2"`
+	SLATarget         float64                   `json:"sla_target" validate:"omitempty,gt=0,lt=100" example:"99.9"`
+	Locations         []string                  `json:"locations" validate:"max=20,dive,required" example:"local,eu-west"`
+	Quorum            int                       `json:"quorum" validate:"omitempty,min=1,max=20" example:"2"`
+}
+
+// Omitted fields are left unchanged; an explicit empty headers or assertions
+// list clears them. Changing locations without giving a quorum resets it to a
+// majority of the new locations.
+type MonitorUpdateRequest struct {
+	Name              *string                   `json:"name" validate:"omitnil,required,min=1,max=100" example:"Google"`
+	Type              *string                   `json:"type" validate:"omitnil,oneof=http tcp dns tls heartbeat" example:"http"`
+	URL               *string                   `json:"url" validate:"omitnil,required,url" example:"https://google.com"`
+	IntervalSeconds   *int                      `json:"interval_seconds" validate:"omitnil,min=10,max=86400" example:"300"`
+	Method            *string                   `json:"method" validate:"omitnil,oneof=GET HEAD POST PUT" example:"GET"`
+	Headers           []MonitorHeaderRequest    `json:"headers" validate:"max=50,dive"`
+	Body              *string                   `json:"body" validate:"omitnil,max=65536" example:""`
+	AcceptedStatuses  *string                   `json:"accepted_statuses" validate:"omitnil,statusranges" example:"200-299,301"`
+	FollowRedirects   *bool                     `json:"follow_redirects" example:"true"`
+	Assertions        []MonitorAssertionRequest `json:"assertions" validate:"max=20,dive"`
+	MaxResponseBytes  *int64                    `json:"max_response_bytes" validate:"omitnil,min=1,max=10485760" example:"1048576"`
+	DNS               *MonitorDNSRequest        `json:"dns"`
+	TLS               *MonitorTLSRequest        `json:"tls"`
+	GraceSeconds      *int                      `json:"grace_seconds" validate:"omitnil,min=0,max=604800" example:"300"`
+	Retries           *int                      `json:"retries" validate:"omitnil,min=0,max=5" example:"2"`
+	RetryDelayMs      *int                      `json:"retry_delay_ms" validate:"omitnil,min=100,max=10000" example:"1000"`
+	FailureThreshold  *int                      `json:"failure_threshold" validate:"omitnil,min=1,max=10" example:"3"`
+	RecoveryThreshold *int                      `json:"recovery_threshold" validate:"omitnil,min=1,max=10" example:"2"`
+	SLATarget         *float64                  `json:"sla_target" validate:"omitnil,gt=0,lt=100" example:"99.9"`
+	Locations         []string                  `json:"locations" validate:"max=20,dive,required" example:"local,eu-west"`
+	Quorum            *int                      `json:"quorum" validate:"omitnil,min=1,max=20" example:"2"`
+}
+
+// MonitorTestRequest holds the settings that affect a single check.
+type MonitorTestRequest struct {
+	Type             string                    `json:"type" validate:"omitempty,oneof=http tcp dns tls" example:"http"`
+	URL              string                    `json:"url" validate:"required,url" example:"https://google.com"`
+	Method           string                    `json:"method" validate:"omitempty,oneof=GET HEAD POST PUT" example:"GET"`
+	Headers          []MonitorHeaderRequest    `json:"headers" validate:"max=50,dive"`
+	Body             string                    `json:"body" validate:"max=65536" example:""`
+	AcceptedStatuses string                    `json:"accepted_statuses" validate:"omitempty,statusranges" example:"200-299,301"`
+	FollowRedirects  *bool                     `json:"follow_redirects" example:"true"`
+	Assertions       []MonitorAssertionRequest `json:"assertions" validate:"max=20,dive"`
+	MaxResponseBytes int64                     `json:"max_response_bytes" validate:"omitempty,min=1,max=10485760" example:"1048576"`
+	DNS              *MonitorDNSRequest        `json:"dns"`
+	TLS              *MonitorTLSRequest        `json:"tls"`
 }
 
 type MonitorResponseEnvelope struct {
@@ -22,6 +109,33 @@ type MonitorListResponseEnvelope struct {
 	Data    []models.Monitor `json:"data"`
 }
 
+type MonitorUptimeResponse struct {
+	MonitorID string                `json:"monitor_id"`
+	Ranges    []models.UptimeReport `json:"ranges"`
+}
+
+type MonitorUptimeResponseEnvelope struct {
+	Success bool                  `json:"success"`
+	Status  int                   `json:"status"`
+	Message string                `json:"message"`
+	Data    MonitorUptimeResponse `json:"data"`
+}
+
+type MonitorHistoryResponse struct {
+	MonitorID   string                 `json:"monitor_id"`
+	Granularity string                 `json:"granularity"`
+	From        time.Time              `json:"from"`
+	To          time.Time              `json:"to"`
+	Buckets     []models.MonitorRollup `json:"buckets"`
+}
+
+type MonitorHistoryResponseEnvelope struct {
+	Success bool                   `json:"success"`
+	Status  int                    `json:"status"`
+	Message string                 `json:"message"`
+	Data    MonitorHistoryResponse `json:"data"`
+}
+
 type MonitorWithLogsResponseEnvelope struct {
 	Success bool                   `json:"success"`
 	Status  int                    `json:"status"`
@@ -30,11 +144,13 @@ type MonitorWithLogsResponseEnvelope struct {
 }
 
 type MonitorDashboardResponse struct {
-	TotalMonitors  int                       `json:"total_monitors"`
-	ActiveMonitors int                       `json:"active_monitors"`
-	UpMonitors     int                       `json:"up_monitors"`
-	DownMonitors   int                       `json:"down_monitors"`
-	RecentLogs     []models.RecentMonitorLog `json:"recent_logs"`
+	TotalMonitors    int                       `json:"total_monitors"`
+	ActiveMonitors   int                       `json:"active_monitors"`
+	UpMonitors       int                       `json:"up_monitors"`
+	DownMonitors     int                       `json:"down_monitors"`
+	DegradedMonitors int                       `json:"degraded_monitors"`
+	OpenIncidents    []models.Incident         `json:"open_incidents"`
+	RecentLogs       []models.RecentMonitorLog `json:"recent_logs"`
 }
 
 type MonitorDashboardResponseEnvelope struct {
@@ -43,3 +159,22 @@ type MonitorDashboardResponseEnvelope struct {
 	Message string                   `json:"message"`
 	Data    MonitorDashboardResponse `json:"data"`
 }
+
+type MonitorCheckResponse struct {
+	Result models.MonitorLog   `json:"result"`
+	State  models.MonitorState `json:"state"`
+}
+
+type MonitorCheckResponseEnvelope struct {
+	Success bool                 `json:"success"`
+	Status  int                  `json:"status"`
+	Message string               `json:"message"`
+	Data    MonitorCheckResponse `json:"data"`
+}
+
+type MonitorTestResponseEnvelope struct {
+	Success bool              `json:"success"`
+	Status  int               `json:"status"`
+	Message string            `json:"message"`
+	Data    models.MonitorLog `json:"data"`
+}
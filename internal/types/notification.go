@@ -0,0 +1,44 @@
+package types
+
+import "learn/internal/models"
+
+type NotificationChannelCreateRequest struct {
+	Name     string `json:"name" validate:"required,min=1,max=100" example:"On-call Slack"`
+	Type     string `json:"type" validate:"required,oneof=webhook email slack discord" example:"slack"`
+	URL      string `json:"url" validate:"required_unless=Type email,omitempty,url,max=2048" example:"https://hooks.slack.com/services/T000/B000/XXXX"`
+	Email    string `json:"email" validate:"required_if=Type email,omitempty,email" example:""`
+	Secret   string `json:"secret" validate:"max=256" example:""`
+	Template string `json:"template" validate:"max=2000" example:"{{.MonitorName}} is {{.Status}}"`
+}
+
+type MonitorChannelsRequest struct {
+	ChannelIDs []string `json:"channel_ids" validate:"required,max=20,dive,uuid"`
+}
+
+type NotificationChannelResponseEnvelope struct {
+	Success bool                       `json:"success"`
+	Status  int                        `json:"status"`
+	Message string                     `json:"message"`
+	Data    models.NotificationChannel `json:"data"`
+}
+
+type NotificationChannelListResponseEnvelope struct {
+	Success bool                         `json:"success"`
+	Status  int                          `json:"status"`
+	Message string                       `json:"message"`
+	Data    []models.NotificationChannel `json:"data"`
+}
+
+type NotificationDeliveryResponseEnvelope struct {
+	Success bool                        `json:"success"`
+	Status  int                         `json:"status"`
+	Message string                      `json:"message"`
+	Data    models.NotificationDelivery `json:"data"`
+}
+
+type NotificationDeliveryListResponseEnvelope struct {
+	Success bool                          `json:"success"`
+	Status  int                           `json:"status"`
+	Message string                        `json:"message"`
+	Data    []models.NotificationDelivery `json:"data"`
+}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"time"
+
+	"learn/internal/models"
+)
+
+type OnCallScheduleCreateRequest struct {
+	Name           string    `json:"name" validate:"required,min=1,max=100" example:"Primary rotation"`
+	OrganizationID string    `json:"organization_id" validate:"omitempty,uuid" example:""`
+	RotationStart  time.Time `json:"rotation_start" example:"2026-01-05T09:00:00Z"`
+	RotationDays   int       `json:"rotation_days" validate:"omitempty,min=1,max=90" example:"7"`
+	Participants   []string  `json:"participants" validate:"required,min=1,max=50,dive,uuid"`
+}
+
+type OnCallOverrideCreateRequest struct {
+	UserID   string    `json:"user_id" validate:"required,uuid" example:"6f1c7d0e-1b7a-4c59-9f6a-0d6b3c4a2e11"`
+	StartsAt time.Time `json:"starts_at" validate:"required" example:"2026-01-10T00:00:00Z"`
+	EndsAt   time.Time `json:"ends_at" validate:"required,gtfield=StartsAt" example:"2026-01-12T00:00:00Z"`
+}
+
+type EscalationPolicyCreateRequest struct {
+	Name           string                  `json:"name" validate:"required,min=1,max=100" example:"Production outages"`
+	OrganizationID string                  `json:"organization_id" validate:"omitempty,uuid" example:""`
+	Steps          []EscalationStepRequest `json:"steps" validate:"required,min=1,max=10,dive"`
+}
+
+type EscalationStepRequest struct {
+	DelayMinutes int                       `json:"delay_minutes" validate:"min=0,max=1440" example:"15"`
+	Targets      []EscalationTargetRequest `json:"targets" validate:"required,min=1,max=10,dive"`
+}
+
+type EscalationTargetRequest struct {
+	Type string `json:"type" validate:"required,oneof=schedule user channel" example:"schedule"`
+	ID   string `json:"id" validate:"required,uuid" example:"0b8f2f7a-3a9e-4d0c-8f43-5d9a6b1c2e77"`
+}
+
+type MonitorEscalationPolicyRequest struct {
+	PolicyID string `json:"policy_id" validate:"omitempty,uuid" example:"0b8f2f7a-3a9e-4d0c-8f43-5d9a6b1c2e77"`
+}
+
+// OnCallScheduleResponse adds who is on call right now to a schedule.
+type OnCallScheduleResponse struct {
+	models.OnCallSchedule
+	OnCallUserID string `json:"on_call_user_id"`
+}
+
+type OnCallScheduleResponseEnvelope struct {
+	Success bool                   `json:"success"`
+	Status  int                    `json:"status"`
+	Message string                 `json:"message"`
+	Data    OnCallScheduleResponse `json:"data"`
+}
+
+type OnCallScheduleListResponseEnvelope struct {
+	Success bool                     `json:"success"`
+	Status  int                      `json:"status"`
+	Message string                   `json:"message"`
+	Data    []OnCallScheduleResponse `json:"data"`
+}
+
+type EscalationPolicyResponseEnvelope struct {
+	Success bool                    `json:"success"`
+	Status  int                     `json:"status"`
+	Message string                  `json:"message"`
+	Data    models.EscalationPolicy `json:"data"`
+}
+
+type EscalationPolicyListResponseEnvelope struct {
+	Success bool                      `json:"success"`
+	Status  int                       `json:"status"`
+	Message string                    `json:"message"`
+	Data    []models.EscalationPolicy `json:"data"`
+}
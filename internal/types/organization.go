@@ -0,0 +1,61 @@
+package types
+
+import "learn/internal/models"
+
+type OrganizationCreateRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=100" example:"Platform Team"`
+}
+
+type OrganizationMemberUpdateRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner admin member viewer" example:"member"`
+}
+
+type OrganizationInviteRequest struct {
+	Email string `json:"email" validate:"required,email" example:"jane@example.com"`
+	Role  string `json:"role" validate:"required,oneof=owner admin member viewer" example:"member"`
+}
+
+type OrganizationDetailResponse struct {
+	models.Organization
+	Members []models.OrganizationMember `json:"members"`
+}
+
+type OrganizationInviteResponse struct {
+	models.OrganizationInvite
+	Token string `json:"token"`
+}
+
+type OrganizationResponseEnvelope struct {
+	Success bool                `json:"success"`
+	Status  int                 `json:"status"`
+	Message string              `json:"message"`
+	Data    models.Organization `json:"data"`
+}
+
+type OrganizationListResponseEnvelope struct {
+	Success bool                  `json:"success"`
+	Status  int                   `json:"status"`
+	Message string                `json:"message"`
+	Data    []models.Organization `json:"data"`
+}
+
+type OrganizationDetailResponseEnvelope struct {
+	Success bool                       `json:"success"`
+	Status  int                        `json:"status"`
+	Message string                     `json:"message"`
+	Data    OrganizationDetailResponse `json:"data"`
+}
+
+type OrganizationInviteResponseEnvelope struct {
+	Success bool                       `json:"success"`
+	Status  int                        `json:"status"`
+	Message string                     `json:"message"`
+	Data    OrganizationInviteResponse `json:"data"`
+}
+
+type OrganizationInviteListResponseEnvelope struct {
+	Success bool                        `json:"success"`
+	Status  int                         `json:"status"`
+	Message string                      `json:"message"`
+	Data    []models.OrganizationInvite `json:"data"`
+}
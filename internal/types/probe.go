@@ -0,0 +1,55 @@
+package types
+
+import "learn/internal/models"
+
+type ProbeCreateRequest struct {
+	Location string `json:"location" validate:"required,max=50,slug" example:"eu-west"`
+}
+
+type ProbeCreateResponse struct {
+	models.Probe
+	Token string `json:"token"`
+}
+
+type ProbeResultsRequest struct {
+	Results []models.ProbeResult `json:"results" validate:"required,max=1000"`
+}
+
+type ProbeResultsResponse struct {
+	Recorded int `json:"recorded"`
+}
+
+type ProbeCreateResponseEnvelope struct {
+	Success bool                `json:"success"`
+	Status  int                 `json:"status"`
+	Message string              `json:"message"`
+	Data    ProbeCreateResponse `json:"data"`
+}
+
+type ProbeListResponseEnvelope struct {
+	Success bool           `json:"success"`
+	Status  int            `json:"status"`
+	Message string         `json:"message"`
+	Data    []models.Probe `json:"data"`
+}
+
+type ProbeCheckListResponseEnvelope struct {
+	Success bool                `json:"success"`
+	Status  int                 `json:"status"`
+	Message string              `json:"message"`
+	Data    []models.ProbeCheck `json:"data"`
+}
+
+type ProbeResultsResponseEnvelope struct {
+	Success bool                 `json:"success"`
+	Status  int                  `json:"status"`
+	Message string               `json:"message"`
+	Data    ProbeResultsResponse `json:"data"`
+}
+
+type LocationListResponseEnvelope struct {
+	Success bool              `json:"success"`
+	Status  int               `json:"status"`
+	Message string            `json:"message"`
+	Data    []models.Location `json:"data"`
+}
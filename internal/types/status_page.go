@@ -0,0 +1,38 @@
+package types
+
+import "learn/internal/models"
+
+type StatusPageSectionRequest struct {
+	Name       string   `json:"name" validate:"required,min=1,max=100" example:"API"`
+	MonitorIDs []string `json:"monitor_ids" validate:"max=100,dive,uuid"`
+}
+
+type StatusPageRequest struct {
+	Slug           string                     `json:"slug" validate:"required,min=3,max=50,slug" example:"acme"`
+	Title          string                     `json:"title" validate:"required,min=1,max=100" example:"Acme Status"`
+	Description    string                     `json:"description" validate:"max=1000" example:"Live status of Acme services"`
+	OrganizationID string                     `json:"organization_id" validate:"omitempty,uuid" example:""`
+	CustomCSS      string                     `json:"custom_css" validate:"max=20000" example:"header { background: #0b3d91; }"`
+	Sections       []StatusPageSectionRequest `json:"sections" validate:"required,min=1,max=20,dive"`
+}
+
+type StatusPageResponseEnvelope struct {
+	Success bool              `json:"success"`
+	Status  int               `json:"status"`
+	Message string            `json:"message"`
+	Data    models.StatusPage `json:"data"`
+}
+
+type StatusPageListResponseEnvelope struct {
+	Success bool                `json:"success"`
+	Status  int                 `json:"status"`
+	Message string              `json:"message"`
+	Data    []models.StatusPage `json:"data"`
+}
+
+type PublicStatusPageResponseEnvelope struct {
+	Success bool                    `json:"success"`
+	Status  int                     `json:"status"`
+	Message string                  `json:"message"`
+	Data    models.PublicStatusPage `json:"data"`
+}
@@ -9,9 +9,28 @@ type UserResponseEnvelope struct {
 	Data    models.UserResponse `json:"data"`
 }
 
+type ProfileUpdateRequest struct {
+	DisplayName *string `json:"display_name" validate:"omitempty,max=100" example:"John Doe"`
+	Bio         *string `json:"bio" validate:"omitempty,max=500" example:"Keeping the lights on."`
+}
+
+type PublicProfileResponseEnvelope struct {
+	Success bool                 `json:"success"`
+	Status  int                  `json:"status"`
+	Message string               `json:"message"`
+	Data    models.PublicProfile `json:"data"`
+}
+
+type UserListResponse struct {
+	Users  []models.PublicProfile `json:"users"`
+	Total  int                    `json:"total"`
+	Limit  int                    `json:"limit"`
+	Offset int                    `json:"offset"`
+}
+
 type UsersResponseEnvelope struct {
-	Success bool                  `json:"success"`
-	Status  int                   `json:"status"`
-	Message string                `json:"message"`
-	Data    []models.UserResponse `json:"data"`
+	Success bool             `json:"success"`
+	Status  int              `json:"status"`
+	Message string           `json:"message"`
+	Data    UserListResponse `json:"data"`
 }
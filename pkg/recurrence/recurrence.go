@@ -0,0 +1,291 @@
+// Package recurrence computes when a repeating event happens next. Rules are
+// either five-field cron expressions or a subset of iCalendar RRULEs (FREQ,
+// INTERVAL, BYDAY, COUNT and UNTIL).
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// maxIterations bounds the search for the next occurrence so that rules which
+// can never match again do not spin forever.
+const maxIterations = 100000
+
+type Schedule interface {
+	// Next returns the first occurrence strictly after t, or the zero time
+	// when there are no more.
+	Next(t time.Time) time.Time
+}
+
+// Parse reads a cron expression or an RRULE. RRULE occurrences repeat the
+// clock time of start, and start is the first one; cron expressions ignore it.
+// Both are evaluated in loc.
+func Parse(rule string, start time.Time, loc *time.Location) (Schedule, error) {
+	rule = strings.TrimSpace(rule)
+	upper := strings.ToUpper(rule)
+	if strings.HasPrefix(upper, "RRULE:") || strings.HasPrefix(upper, "FREQ=") {
+		return parseRRule(strings.TrimPrefix(upper, "RRULE:"), start.In(loc))
+	}
+	return parseCron(rule, loc)
+}
+
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+	loc                           *time.Location
+}
+
+func parseCron(rule string, loc *time.Location) (*cronSchedule, error) {
+	fields := strings.Fields(rule)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: cron needs 5 fields, got %d", ErrInvalidRule, len(fields))
+	}
+
+	s := &cronSchedule{loc: loc, domAny: fields[2] == "*", dowAny: fields[4] == "*"}
+	bounds := []struct {
+		field    *uint64
+		min, max int
+	}{{&s.minute, 0, 59}, {&s.hour, 0, 23}, {&s.dom, 1, 31}, {&s.month, 1, 12}, {&s.dow, 0, 7}}
+	for i, b := range bounds {
+		bits, err := parseCronField(fields[i], b.min, b.max)
+		if err != nil {
+			return nil, fmt.Errorf("%w: field %q: %v", ErrInvalidRule, fields[i], err)
+		}
+		*b.field = bits
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n < 1 {
+				return 0, errors.New("bad step")
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, errors.New("bad number")
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, errors.New("bad number")
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, errors.New("out of range")
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.In(s.loc).Truncate(time.Minute).Add(time.Minute)
+	for range maxIterations {
+		year, month, day := t.Date()
+		switch {
+		case s.month&(1<<int(month)) == 0:
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, s.loc)
+		case !s.dayMatches(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, s.loc)
+		case s.hour&(1<<t.Hour()) == 0:
+			t = time.Date(year, month, day, t.Hour()+1, 0, 0, 0, s.loc)
+		case s.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches follows cron's rule that when both day fields are restricted a
+// day matching either one counts.
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<t.Day()) != 0
+	dow := s.dow&(1<<int(t.Weekday())) != 0
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+type rruleSchedule struct {
+	freq     string
+	interval int
+	byDay    []time.Weekday
+	count    int
+	until    time.Time
+	start    time.Time
+}
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+func parseRRule(rule string, start time.Time) (*rruleSchedule, error) {
+	s := &rruleSchedule{interval: 1, start: start}
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRule, part)
+		}
+		switch key {
+		case "FREQ":
+			if value != "DAILY" && value != "WEEKLY" && value != "MONTHLY" {
+				return nil, fmt.Errorf("%w: FREQ must be DAILY, WEEKLY or MONTHLY", ErrInvalidRule)
+			}
+			s.freq = value
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: bad INTERVAL", ErrInvalidRule)
+			}
+			s.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: bad COUNT", ErrInvalidRule)
+			}
+			s.count = n
+		case "UNTIL":
+			until, err := time.Parse("20060102T150405Z", value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: UNTIL must look like 20260131T000000Z", ErrInvalidRule)
+			}
+			s.until = until
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := rruleWeekdays[code]
+				if !ok {
+					return nil, fmt.Errorf("%w: bad BYDAY %q", ErrInvalidRule, code)
+				}
+				s.byDay = append(s.byDay, day)
+			}
+		default:
+			return nil, fmt.Errorf("%w: %s is not supported", ErrInvalidRule, key)
+		}
+	}
+	if s.freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if s.freq == "MONTHLY" && len(s.byDay) > 0 {
+		return nil, fmt.Errorf("%w: BYDAY is not supported with MONTHLY", ErrInvalidRule)
+	}
+	return s, nil
+}
+
+func (s *rruleSchedule) Next(t time.Time) time.Time {
+	period := 0
+	if s.count == 0 && t.After(s.start) {
+		// Without COUNT earlier periods do not matter, so skip close to t.
+		days := int(t.Sub(s.start).Hours() / 24)
+		switch s.freq {
+		case "DAILY":
+			period = days/s.interval - 1
+		case "WEEKLY":
+			period = days/(7*s.interval) - 1
+		case "MONTHLY":
+			period = days/(31*s.interval) - 1
+		}
+		period = max(period, 0)
+	}
+
+	seen := 0
+	for ; period < maxIterations; period++ {
+		for _, occurrence := range s.period(period) {
+			if occurrence.Before(s.start) {
+				continue
+			}
+			if !s.until.IsZero() && occurrence.After(s.until) {
+				return time.Time{}
+			}
+			seen++
+			if s.count > 0 && seen > s.count {
+				return time.Time{}
+			}
+			if occurrence.After(t) {
+				return occurrence
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// period returns the occurrences in the n-th day, week or month after start,
+// in order.
+func (s *rruleSchedule) period(n int) []time.Time {
+	year, month, day := s.start.Date()
+	hour, minute, second := s.start.Clock()
+	loc := s.start.Location()
+
+	switch s.freq {
+	case "DAILY":
+		occurrence := time.Date(year, month, day+n*s.interval, hour, minute, second, 0, loc)
+		if len(s.byDay) > 0 && !containsWeekday(s.byDay, occurrence.Weekday()) {
+			return nil
+		}
+		return []time.Time{occurrence}
+	case "WEEKLY":
+		days := s.byDay
+		if len(days) == 0 {
+			days = []time.Weekday{s.start.Weekday()}
+		}
+		monday := day - (int(s.start.Weekday())+6)%7 + 7*n*s.interval
+		var occurrences []time.Time
+		for offset := range 7 {
+			occurrence := time.Date(year, month, monday+offset, hour, minute, second, 0, loc)
+			if containsWeekday(days, occurrence.Weekday()) {
+				occurrences = append(occurrences, occurrence)
+			}
+		}
+		return occurrences
+	default:
+		occurrence := time.Date(year, month+time.Month(n*s.interval), day, hour, minute, second, 0, loc)
+		if occurrence.Day() != day {
+			return nil
+		}
+		return []time.Time{occurrence}
+	}
+}
+
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}